@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/github/github-mcp-server/pkg/repository"
+	"github.com/spf13/cobra"
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert [root|archive]",
+	Short: "Convert a repository into a bundle of tool definitions",
+	Long: `Convert a repository into MCP-compatible tools in one step: list its source files, extract their public functions, methods and constructors, and emit a tool definition for each.
+
+The bundle holds the tool definitions, the source location of every tool and a report of the symbols that were skipped. It is written to stdout unless --output is given. The repository is a directory, by default the current one, or a .zip, .tar.gz or .tgz source archive.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+		repo, err := repository.OpenRepo(root)
+		if err != nil {
+			return fmt.Errorf("failed to open repository: %w", err)
+		}
+		defer repo.Close()
+
+		flags := cmd.Flags()
+		include, _ := flags.GetStringArray("include")
+		exclude, _ := flags.GetStringArray("exclude")
+		maxFiles, _ := flags.GetInt("max-files")
+		includeDeprecated, _ := flags.GetBool("include-deprecated")
+		output, _ := flags.GetString("output")
+
+		bundle, err := repository.Convert(cmd.Context(), repo, repository.ConvertOptions{
+			Include:           include,
+			Exclude:           exclude,
+			MaxFiles:          maxFiles,
+			IncludeDeprecated: includeDeprecated,
+		})
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal tool bundle: %w", err)
+		}
+		data = append(data, '\n')
+
+		if output == "" || output == "-" {
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		}
+		if err := os.WriteFile(output, data, 0o600); err != nil {
+			return fmt.Errorf("failed to write tool bundle: %w", err)
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d tools from %d files to %s (%d symbols skipped)\n",
+			len(bundle.Tools), bundle.Files, output, len(bundle.Skipped))
+		return nil
+	},
+}
+
+func init() {
+	convertCmd.Flags().StringArray("include", nil, "Only read files matching this glob (repeatable)")
+	convertCmd.Flags().StringArray("exclude", nil, "Skip files matching this glob (repeatable)")
+	convertCmd.Flags().Int("max-files", 500, "Maximum number of source files to read")
+	convertCmd.Flags().Bool("include-deprecated", false, "Convert symbols marked as deprecated instead of skipping them")
+	convertCmd.Flags().StringP("output", "o", "", "Write the bundle to this file instead of stdout")
+
+	rootCmd.AddCommand(convertCmd)
+}
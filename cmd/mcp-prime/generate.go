@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/repository"
+	"github.com/spf13/cobra"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate [root]",
+	Short: "Scaffold an MCP server project serving a repository's functions",
+	Long: `Scaffold an MCP server project, in Go using mcp-go or in Python using FastMCP, with a tool for each public function of a repository. Each tool decodes its arguments into the parameter types of the original function, calls it and returns its result as JSON.
+
+The project is written to --output, by default the mcp-server directory of the repository, and points back at the repository: the Go module with a replace directive, the Python server by adding it to sys.path. The repository defaults to the current directory.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+		// The generated project imports the repository, so it must be on disk
+		repo, err := repository.OpenDir(root)
+		if err != nil {
+			return fmt.Errorf("failed to open repository: %w", err)
+		}
+
+		flags := cmd.Flags()
+		language, _ := flags.GetString("lang")
+		name, _ := flags.GetString("name")
+		include, _ := flags.GetStringArray("include")
+		exclude, _ := flags.GetStringArray("exclude")
+		maxFiles, _ := flags.GetInt("max-files")
+		includeDeprecated, _ := flags.GetBool("include-deprecated")
+		output, _ := flags.GetString("output")
+
+		outputDir := filepath.Join(repo.Dir(), repository.DefaultServerDir)
+		if output != "" {
+			if outputDir, err = filepath.Abs(output); err != nil {
+				return fmt.Errorf("failed to resolve output directory: %w", err)
+			}
+		}
+
+		project, err := repository.Generate(cmd.Context(), repo, repository.GenerateOptions{
+			Language:          language,
+			Name:              name,
+			OutputDir:         outputDir,
+			Include:           include,
+			Exclude:           exclude,
+			MaxFiles:          maxFiles,
+			IncludeDeprecated: includeDeprecated,
+		})
+		if err != nil {
+			return err
+		}
+		if err := project.Write(outputDir); err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Wrote a %s server with %d tools to %s (%d symbols skipped)\n",
+			project.Language, len(project.Tools), outputDir, len(project.Skipped))
+		return nil
+	},
+}
+
+func init() {
+	generateCmd.Flags().String("lang", "go", fmt.Sprintf("Language of the generated server (%s)", strings.Join(repository.ServerLanguages(), ", ")))
+	generateCmd.Flags().String("name", "", "Server name (default: the name of the repository directory)")
+	generateCmd.Flags().StringArray("include", nil, "Only read files matching this glob (repeatable)")
+	generateCmd.Flags().StringArray("exclude", nil, "Skip files matching this glob (repeatable)")
+	generateCmd.Flags().Int("max-files", 500, "Maximum number of source files to read")
+	generateCmd.Flags().Bool("include-deprecated", false, "Serve symbols marked as deprecated instead of skipping them")
+	generateCmd.Flags().StringP("output", "o", "", "Directory to write the project to (default: <root>/mcp-server)")
+
+	rootCmd.AddCommand(generateCmd)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/github/github-mcp-server/internal/ghmcp"
 	"github.com/spf13/cobra"
@@ -28,7 +29,7 @@ var (
 		Use:   "stdio",
 		Short: "Start stdio MCP server",
 		Long:  `Start an MCP server that communicates via standard input/output streams using JSON-RPC messages.`,
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) error {
 			stdioServerConfig := ghmcp.StdioServerConfig{
 				Version:              version,
 				EnabledToolsets:      []string{"repository"},
@@ -38,6 +39,13 @@ var (
 				EnableCommandLogging: viper.GetBool("enable-command-logging"),
 				LogFilePath:          viper.GetString("log-file"),
 				ContentWindowSize:    viper.GetInt("content-window-size"),
+				RepoRoots:            repoRoots(cmd.Flags()),
+				ServeFunctions:       viper.GetBool("serve-functions"),
+				PythonInterpreter:    viper.GetString("python"),
+				NodeInterpreter:      viper.GetString("node"),
+				FunctionTimeout:      viper.GetDuration("function-timeout"),
+				FunctionMaxOutput:    viper.GetInt("function-max-output"),
+				Watch:                viper.GetBool("watch"),
 			}
 			return ghmcp.RunRepositoryStdioServer(stdioServerConfig)
 		},
@@ -62,6 +70,25 @@ func init() {
 	_ = viper.BindPFlag("export-translations", rootCmd.PersistentFlags().Lookup("export-translations"))
 	_ = viper.BindPFlag("content-window-size", rootCmd.PersistentFlags().Lookup("content-window-size"))
 
+	stdioCmd.Flags().StringArray("repo-root", nil, "Repository directory the tools operate on, as a path or name=path (repeatable; the first is the default, the working directory when omitted)")
+	_ = viper.BindPFlag("repo-root", stdioCmd.Flags().Lookup("repo-root"))
+
+	stdioCmd.Flags().Bool("watch", false, "Keep the file lists and symbol indexes of the repository roots up to date as files change, re-registering served functions")
+	_ = viper.BindPFlag("watch", stdioCmd.Flags().Lookup("watch"))
+
+	// Serving repository functions runs repository code, so it is opt-in
+	stdioCmd.Flags().Bool("serve-functions", false, "Register the repository's Python and JavaScript functions as tools that run them (executes repository code)")
+	stdioCmd.Flags().String("python", "python3", "Command running served Python functions")
+	stdioCmd.Flags().String("node", "node", "Command running served JavaScript functions")
+	stdioCmd.Flags().Duration("function-timeout", 30*time.Second, "Time limit of each call of a served function")
+	stdioCmd.Flags().Int("function-max-output", 1<<20, "Largest result a served function may return, in bytes")
+
+	_ = viper.BindPFlag("serve-functions", stdioCmd.Flags().Lookup("serve-functions"))
+	_ = viper.BindPFlag("python", stdioCmd.Flags().Lookup("python"))
+	_ = viper.BindPFlag("node", stdioCmd.Flags().Lookup("node"))
+	_ = viper.BindPFlag("function-timeout", stdioCmd.Flags().Lookup("function-timeout"))
+	_ = viper.BindPFlag("function-max-output", stdioCmd.Flags().Lookup("function-max-output"))
+
 	// Add subcommands
 	rootCmd.AddCommand(stdioCmd)
 }
@@ -69,9 +96,25 @@ func init() {
 func initConfig() {
 	// Initialize Viper configuration
 	viper.SetEnvPrefix("MCP_PRIME")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
 }
 
+// repoRoots returns the roots given with --repo-root, or else the value of
+// MCP_PRIME_REPO_ROOT as a single spec. Viper would split the variable on
+// whitespace, breaking paths with spaces, so the splitting on the path list
+// separator is left to ParseRoots.
+func repoRoots(flags *pflag.FlagSet) []string {
+	if flags.Changed("repo-root") {
+		roots, _ := flags.GetStringArray("repo-root")
+		return roots
+	}
+	if env := os.Getenv("MCP_PRIME_REPO_ROOT"); env != "" {
+		return []string{env}
+	}
+	return nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
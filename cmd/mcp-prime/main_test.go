@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/repository"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoRoots(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "My Repos")
+	api := filepath.Join(base, "api server")
+	web := filepath.Join(base, "web")
+	for _, dir := range []string{api, web} {
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+	}
+
+	newFlags := func() *pflag.FlagSet {
+		flags := pflag.NewFlagSet("stdio", pflag.ContinueOnError)
+		flags.StringArray("repo-root", nil, "")
+		return flags
+	}
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("MCP_PRIME_REPO_ROOT", "api="+api+string(filepath.ListSeparator)+web)
+		specs := repoRoots(newFlags())
+		assert.Equal(t, []string{"api=" + api + string(filepath.ListSeparator) + web}, specs)
+
+		roots, err := repository.ParseRoots(specs)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api", "web"}, roots.Names())
+		assert.Equal(t, api, roots.List()[0].Path)
+	})
+
+	t.Run("flags take precedence", func(t *testing.T) {
+		t.Setenv("MCP_PRIME_REPO_ROOT", web)
+		flags := newFlags()
+		require.NoError(t, flags.Parse([]string{"--repo-root", api}))
+		assert.Equal(t, []string{api}, repoRoots(flags))
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		t.Setenv("MCP_PRIME_REPO_ROOT", "")
+		assert.Nil(t, repoRoots(newFlags()))
+	})
+}
@@ -10,8 +10,11 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"reflect"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/github"
@@ -187,6 +190,29 @@ type StdioServerConfig struct {
 
 	// Content window size
 	ContentWindowSize int
+
+	// RepoRoots are the repository directories the repository tools operate
+	// on, each a directory or name=directory; the first is the default. The
+	// working directory is used when there are none.
+	RepoRoots []string
+
+	// ServeFunctions registers the repository's Python and JavaScript
+	// functions as tools that run them. This executes repository code.
+	ServeFunctions bool
+
+	// Commands running served Python and JavaScript functions
+	PythonInterpreter string
+	NodeInterpreter   string
+
+	// Time limit of each call of a served function
+	FunctionTimeout time.Duration
+
+	// Largest result a served function may return, in bytes
+	FunctionMaxOutput int
+
+	// Watch keeps the file lists and symbol indexes of the repository roots
+	// up to date as files change, and re-registers served functions
+	Watch bool
 }
 
 // RunStdioServer is not concurrent safe.
@@ -281,8 +307,14 @@ func RunRepositoryStdioServer(cfg StdioServerConfig) error {
 		server.WithLogging(),
 	)
 
+	roots, err := repository.ParseRoots(cfg.RepoRoots)
+	if err != nil {
+		return err
+	}
+	defer roots.Close()
+
 	// Register repository tools
-	err := registerRepositoryTools(repoServer)
+	err = registerRepositoryTools(repoServer, roots, cfg.ContentWindowSize)
 	if err != nil {
 		return fmt.Errorf("failed to register repository tools: %w", err)
 	}
@@ -307,6 +339,33 @@ func RunRepositoryStdioServer(cfg StdioServerConfig) error {
 	stdLogger := log.New(logOutput, "[MCP-PRIME] ", 0)
 	stdioServer.SetErrorLogger(stdLogger)
 
+	var functions *functionTools
+	if cfg.ServeFunctions {
+		functions = &functionTools{mcpServer: repoServer, roots: roots, cfg: cfg, logger: logger}
+		if err := functions.register(ctx); err != nil {
+			return fmt.Errorf("failed to register function tools: %w", err)
+		}
+	}
+
+	if cfg.Watch {
+		err := roots.Watch(ctx, func(repo *repository.RepoFS, changed []string) {
+			logger.Debug("repository changed", "root", repo.Name(), "files", len(changed))
+			if functions == nil {
+				return
+			}
+			if defaultRepo, err := roots.Resolve(""); err != nil || defaultRepo != repo {
+				return
+			}
+			if err := functions.register(ctx); err != nil {
+				logger.Error("failed to register function tools", "error", err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to watch repository roots: %w", err)
+		}
+		logger.Info("watching repository roots for changes")
+	}
+
 	// Start listening for messages
 	errC := make(chan error, 1)
 	go func() {
@@ -336,23 +395,106 @@ func RunRepositoryStdioServer(cfg StdioServerConfig) error {
 	return nil
 }
 
-func registerRepositoryTools(mcpServer *server.MCPServer) error {
+func registerRepositoryTools(mcpServer *server.MCPServer, roots *repository.Roots, contentWindowSize int) error {
 	// Register repository analysis tools directly
-	fileListTool, fileListHandler := repository.GetFileListTool()
+	fileListTool, fileListHandler := repository.GetFileListTool(roots)
 	mcpServer.AddTool(fileListTool, fileListHandler)
 
-	fileContentTool, fileContentHandler := repository.GetFileContentTool()
+	fileContentTool, fileContentHandler := repository.GetFileContentTool(roots, contentWindowSize)
 	mcpServer.AddTool(fileContentTool, fileContentHandler)
 
 	extractSigTool, extractSigHandler := repository.ExtractSignaturesTool()
 	mcpServer.AddTool(extractSigTool, extractSigHandler)
 
+	extractRepoSigTool, extractRepoSigHandler := repository.ExtractRepositorySignaturesTool(roots)
+	mcpServer.AddTool(extractRepoSigTool, extractRepoSigHandler)
+
+	searchTool, searchHandler := repository.SearchCodeTool(roots)
+	mcpServer.AddTool(searchTool, searchHandler)
+
+	findSymbolTool, findSymbolHandler := repository.FindSymbolTool(roots)
+	mcpServer.AddTool(findSymbolTool, findSymbolHandler)
+
+	definitionTool, definitionHandler := repository.GetDefinitionTool(roots)
+	mcpServer.AddTool(definitionTool, definitionHandler)
+
+	referencesTool, referencesHandler := repository.FindReferencesTool(roots)
+	mcpServer.AddTool(referencesTool, referencesHandler)
+
+	convertTool, convertHandler := repository.ConvertRepositoryTool(roots)
+	mcpServer.AddTool(convertTool, convertHandler)
+
+	generateTool, generateHandler := repository.GenerateServerTool(roots)
+	mcpServer.AddTool(generateTool, generateHandler)
+
 	emitToolTool, emitToolHandler := repository.EmitToolJSONTool()
 	mcpServer.AddTool(emitToolTool, emitToolHandler)
 
 	return nil
 }
 
+// functionTools serves the Python and JavaScript functions of the default
+// repository root as tools run by the configured interpreters
+type functionTools struct {
+	mcpServer *server.MCPServer
+	roots     *repository.Roots
+	cfg       StdioServerConfig
+	logger    *slog.Logger
+
+	mu    sync.Mutex
+	tools map[string]mcp.Tool // the tools registered
+}
+
+// register registers a tool for each function of the default root, replacing
+// the tools registered before. The server notifies clients whenever the tool
+// list changes, so the tools are left alone when they are the same.
+func (f *functionTools) register(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	repo, err := f.roots.Resolve("")
+	if err != nil {
+		return err
+	}
+
+	tools, skipped, err := repository.LiveTools(ctx, repo, repository.LiveOptions{
+		Python:    f.cfg.PythonInterpreter,
+		Node:      f.cfg.NodeInterpreter,
+		Timeout:   f.cfg.FunctionTimeout,
+		MaxOutput: f.cfg.FunctionMaxOutput,
+		Reserved:  repository.ToolNames(),
+	})
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]mcp.Tool, len(tools))
+	for _, tool := range tools {
+		current[tool.Tool.Name] = tool.Tool
+	}
+	if f.tools != nil && reflect.DeepEqual(current, f.tools) {
+		return nil
+	}
+
+	for _, symbol := range skipped {
+		f.logger.Debug("function not served", "symbol", symbol.Symbol, "path", symbol.Path, "reason", symbol.Reason)
+	}
+	f.logger.Warn("serving repository functions, tool calls run repository code", "root", repo.Dir(), "tools", len(tools), "skipped", len(skipped))
+
+	var stale []string
+	for name := range f.tools {
+		if _, ok := current[name]; !ok {
+			stale = append(stale, name)
+		}
+	}
+	if len(stale) > 0 {
+		f.mcpServer.DeleteTools(stale...)
+	}
+	f.mcpServer.AddTools(tools...)
+	f.tools = current
+	return nil
+}
+
 type apiHost struct {
 	baseRESTURL *url.URL
 	graphqlURL  *url.URL
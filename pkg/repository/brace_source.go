@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+)
+
+// braceSource holds the code of a brace-delimited language (Rust, Java,
+// Kotlin, C) in three aligned forms of the same length, so offsets found in
+// one can be used to slice the others
+type braceSource struct {
+	text   string // original source
+	code   string // comments replaced by spaces
+	masked string // comments and string or char literals replaced by spaces
+}
+
+// braceDecl is the extent of a declaration: a header that runs up to the
+// declaration's block or terminating semicolon, and the block itself
+type braceDecl struct {
+	Start     int // first non-blank offset of the header
+	HeaderEnd int
+	End       int    // offset after the declaration
+	Body      [2]int // offsets of the block contents; both zero when there is no block
+	Doc       string // doc comments preceding the declaration
+}
+
+var braceDocRE = regexp.MustCompile(`(?s)/\*\*([^*/].*?)\*/`)
+
+// newBraceSource blanks out comments and the literals recognised by
+// literalEnd so brackets can be matched. literalEnd returns the end of the
+// literal starting at i, if there is one. Block comments nest when nested is set.
+func newBraceSource(text string, literalEnd func(text string, i int) (int, bool), nested bool) *braceSource {
+	code := []byte(text)
+	masked := []byte(text)
+	blank := func(buf []byte, from, to int) {
+		for k := from; k < to; k++ {
+			if buf[k] != '\n' {
+				buf[k] = ' '
+			}
+		}
+	}
+
+	for i := 0; i < len(text); {
+		switch {
+		case strings.HasPrefix(text[i:], "//"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += i
+			}
+			blank(code, i, end)
+			blank(masked, i, end)
+			i = end
+		case strings.HasPrefix(text[i:], "/*"):
+			depth, j := 0, i
+			for j < len(text) {
+				if strings.HasPrefix(text[j:], "/*") && (nested || depth == 0) {
+					depth++
+					j += 2
+					continue
+				}
+				if strings.HasPrefix(text[j:], "*/") {
+					depth--
+					j += 2
+					if depth == 0 {
+						break
+					}
+					continue
+				}
+				j++
+			}
+			blank(code, i, j)
+			blank(masked, i, j)
+			i = j
+		default:
+			// Literal prefixes such as r"..." cannot follow an identifier
+			if i > 0 && braceIsIdentByte(text[i-1]) {
+				i++
+				continue
+			}
+			if end, ok := literalEnd(text, i); ok {
+				blank(masked, i, end)
+				i = end
+				continue
+			}
+			i++
+		}
+	}
+
+	return &braceSource{text: text, code: string(code), masked: string(masked)}
+}
+
+// quotedLiteralEnd returns the end of a "string" or 'c'har literal with
+// backslash escapes, as found in Java, Kotlin and C
+func quotedLiteralEnd(text string, i int) (int, bool) {
+	quote := text[i]
+	if quote != '"' && quote != '\'' {
+		return 0, false
+	}
+
+	// Java text blocks and Kotlin raw strings
+	if strings.HasPrefix(text[i:], `"""`) {
+		end := strings.Index(text[i+3:], `"""`)
+		if end < 0 {
+			return len(text), true
+		}
+		end += i + 6
+		for end < len(text) && text[end] == '"' {
+			end++
+		}
+		return end, true
+	}
+
+	for k := i + 1; k < len(text); k++ {
+		switch text[k] {
+		case '\\':
+			k++
+		case quote:
+			return k + 1, true
+		case '\n':
+			return k, true
+		}
+	}
+	return len(text), true
+}
+
+func braceIsIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// lineAt returns the 1-based line of an offset
+func (s *braceSource) lineAt(offset int) int {
+	return strings.Count(s.text[:offset], "\n") + 1
+}
+
+// matchingBrace returns the offset of the brace closing the one at open
+func (s *braceSource) matchingBrace(open, end int) int {
+	depth := 0
+	for i := open; i < end; i++ {
+		switch s.masked[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return end
+}
+
+// declarations splits the code between start and end into declarations.
+// When lineBreaks is set, as in Kotlin, a line break also ends a header
+// unless the next line continues it.
+func (s *braceSource) declarations(start, end int, lineBreaks bool) []braceDecl {
+	var decls []braceDecl
+	prevEnd := start
+
+	for pos := start; pos < end; {
+		for pos < end && strings.IndexByte(" \t\r\n;", s.masked[pos]) >= 0 {
+			pos++
+		}
+		if pos >= end {
+			break
+		}
+
+		decl := braceDecl{Start: pos, HeaderEnd: end, End: end}
+		depth := 0
+	scan:
+		for i := pos; i < end; i++ {
+			switch s.masked[i] {
+			case '(', '[':
+				depth++
+			case ')', ']', '}':
+				depth--
+			case '{':
+				if depth == 0 {
+					decl.HeaderEnd = i
+					decl.End = s.matchingBrace(i, end) + 1
+					decl.Body = [2]int{i + 1, decl.End - 1}
+					break scan
+				}
+				depth++
+			case ';':
+				if depth == 0 {
+					decl.HeaderEnd, decl.End = i, i+1
+					break scan
+				}
+			case '\n':
+				if lineBreaks && depth == 0 && !s.continuesLine(pos, i, end) {
+					decl.HeaderEnd, decl.End = i, i+1
+					break scan
+				}
+			}
+		}
+		if decl.End > end {
+			decl.End = end
+			decl.Body[1] = min(decl.Body[1], end)
+		}
+
+		decl.Doc = braceDocComment(s.text[prevEnd:decl.HeaderEnd])
+		decls = append(decls, decl)
+		pos, prevEnd = decl.End, decl.End
+	}
+	return decls
+}
+
+// continuesLine reports whether the header that started at start continues
+// past the line break at i: after annotations, binary operators and before
+// member access, supertypes, bodies and where clauses
+func (s *braceSource) continuesLine(start, i, end int) bool {
+	header := strings.TrimSpace(s.masked[start:i])
+	if header == "" {
+		return true
+	}
+	fields := strings.Fields(header)
+	if last := fields[len(fields)-1]; strings.HasPrefix(last, "@") {
+		return true
+	}
+	if strings.ContainsAny(header[len(header)-1:], "=,.:(<+-*/|&?") || strings.HasSuffix(header, "->") {
+		return true
+	}
+
+	next := strings.TrimLeft(s.masked[i:end], " \t\r\n")
+	for _, prefix := range []string{".", "?.", ":", "=", "{", ")", ",", "&&", "||", "?:", "->", "where ", "by "} {
+		if strings.HasPrefix(next, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// braceMatchingBracket returns the offset of the bracket closing the one at
+// open, treating (, [, { and < as brackets while skipping -> and =>
+func braceMatchingBracket(text string, open, end int) int {
+	depth := 0
+	for i := open; i < end; i++ {
+		switch c := text[i]; c {
+		case '(', '[', '{', '<':
+			if c == '<' && i+1 < end && text[i+1] == '<' {
+				i++
+				continue
+			}
+			depth++
+		case ')', ']', '}', '>':
+			if c == '>' && i > 0 && (text[i-1] == '-' || text[i-1] == '=') {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return end - 1
+}
+
+// braceScanTo returns the index of the first occurrence of sep outside of
+// brackets and generics
+func braceScanTo(text, sep string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		if depth == 0 && strings.HasPrefix(text[i:], sep) {
+			return i
+		}
+		switch c := text[i]; c {
+		case '(', '[', '{', '<':
+			if c == '<' && strings.HasPrefix(text[i:], "<<") {
+				i++
+				continue
+			}
+			depth++
+		case ')', ']', '}', '>':
+			if c == '>' && i > 0 && (text[i-1] == '-' || text[i-1] == '=') {
+				continue
+			}
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	return -1
+}
+
+// splitBraceTopLevel splits text on a separator outside of brackets and generics
+func splitBraceTopLevel(text string, sep byte) []string {
+	var parts []string
+	for {
+		idx := braceScanTo(text, string(sep))
+		if idx < 0 {
+			break
+		}
+		parts = append(parts, text[:idx])
+		text = text[idx+1:]
+	}
+	if strings.TrimSpace(text) != "" || len(parts) > 0 {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+// splitBraceMasked splits text on a separator outside of brackets,
+// generics, comments and literals, finding the separators in masked, the
+// text with its comments and literals blanked out
+func splitBraceMasked(text, masked string, sep byte) []string {
+	var parts []string
+	offset := 0
+	for _, part := range splitBraceTopLevel(masked, sep) {
+		parts = append(parts, text[offset:offset+len(part)])
+		offset += len(part) + 1
+	}
+	return parts
+}
+
+// braceDocComment collects the doc comments (/** */ and ///) in the text
+// preceding a declaration, without their comment markers
+func braceDocComment(text string) string {
+	var lines []string
+	for _, m := range braceDocRE.FindAllStringSubmatch(text, -1) {
+		for _, line := range strings.Split(m[1], "\n") {
+			line = strings.TrimSpace(line)
+			line = strings.TrimPrefix(strings.TrimPrefix(line, "*"), " ")
+			lines = append(lines, line)
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "///") && !strings.HasPrefix(line, "////") {
+			lines = append(lines, strings.TrimPrefix(line[3:], " "))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
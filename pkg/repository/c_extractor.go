@@ -0,0 +1,834 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// cSource is a C or C++ source file prepared for scanning. Preprocessor
+// directives are blanked out of both the code and masked forms.
+type cSource struct {
+	*braceSource
+	exportMacros map[string]bool // macros defined to dllexport or default visibility
+}
+
+// cDecl is a C or C++ declaration
+type cDecl struct {
+	Kind        string // "function", "struct", "class", "union", "enum", "typedef", "namespace", "linkage", "variable" or "" for anything else
+	Name        string // the typedef name of typedef'd structs and enums, otherwise the declared name
+	Tag         string // struct, union or enum tag
+	Header      string // declaration without attributes, export macros or body, whitespace collapsed
+	Type        string // return type of a function, aliased type of a typedef or type of a variable
+	Params      string // text between the parameter parentheses
+	Declarators string // declarators following a struct, union or enum body
+	Access      string // "public", "protected" or "private"
+	Static      bool
+	Inline      bool
+	Exported    bool // marked with an export macro or attribute
+	Deprecated  bool
+	Deleted     bool // "= delete"
+	Doc         string
+	Trailing    string // member documentation following the declaration (///< or /**< */)
+	Line        int
+	Body        [2]int // offsets of the block contents; both zero when there is no block
+}
+
+// cParam is a function parameter or a struct field
+type cParam struct {
+	Name       string
+	Type       string // including any array suffix, e.g. "int[4]"
+	Default    string // C++ default argument
+	HasDefault bool
+}
+
+var (
+	cIdentRE         = regexp.MustCompile(`^[A-Za-z_]\w*`)
+	cTrailingIdentRE = regexp.MustCompile(`([A-Za-z_]\w*)\s*((?:\[[^\]]*\]\s*)*)$`)
+	cDefineRE        = regexp.MustCompile(`^#\s*define\s+([A-Za-z_]\w*)(\([^)]*\))?\s*(.*)$`)
+	cAccessRE        = regexp.MustCompile(`^(public|private|protected)\s*:([^:]|$)`)
+	cExportNameRE    = regexp.MustCompile(`^[A-Z][A-Z0-9_]*_(?:API|EXPORT|EXPORTS|PUBLIC|EXTERN|DECLSPEC)$|^(?:EXPORT|DLLEXPORT)$`)
+	cFunctionNameRE  = regexp.MustCompile(`~?[A-Za-z_][\w:]*$`)
+	cBitFieldRE      = regexp.MustCompile(`\s*:\s*\d+\s*$`)
+	cOperatorRE      = regexp.MustCompile(`\boperator\b`)
+	cLinkageMacroRE  = regexp.MustCompile(`^\w*(?:BEGIN|END)_(?:C_)?DECLS$|^\w*EXTERN_C_(?:BEGIN|END)$`)
+	cExportValueRE   = regexp.MustCompile(`dll(?:ex|im)port|visibility\s*\(\s*"default"\s*\)`)
+	cDocFileRE       = regexp.MustCompile(`(?s)/\*[*!](?:[^*]|\*[^/])*[@\\](?:file|mainpage|defgroup|addtogroup|page)\b.*?\*/`)
+	cMemberDocRE     = regexp.MustCompile(`(?s)/\*[*!]<.*?\*/|//[/!]<[^\n]*`)
+	cDoxygenCmdRE    = regexp.MustCompile(`(^|\s)\\(brief|short|details|param|tparam|returns?|result|retval|throws?|exception|deprecated|note|warning|see|sa|since|code|endcode|example|pre|post)\b`)
+	cDoxygenInlineRE = regexp.MustCompile(`[@\\](?:a|b|c|e|em|p)\s+`)
+	cDoxygenParamRE  = regexp.MustCompile(`@param\s*\[[^\]]*\]`)
+	cDoxygenCodeRE   = regexp.MustCompile(`@code(?:\{[^}]*\})?`)
+
+	// cSpecifiers are storage classes, function specifiers and calling
+	// conventions, which are not part of a function's return type
+	cSpecifiers = map[string]bool{
+		"extern": true, "static": true, "inline": true, "__inline": true, "__inline__": true,
+		"__forceinline": true, "virtual": true, "explicit": true, "constexpr": true, "consteval": true,
+		"friend": true, "_Noreturn": true, "__cdecl": true, "__stdcall": true, "__fastcall": true,
+		"__vectorcall": true, "WINAPI": true, "APIENTRY": true, "CALLBACK": true, "register": true,
+		"thread_local": true, "_Thread_local": true, "mutable": true,
+	}
+	// cNotFunctions are keywords that can precede a parenthesis in a declaration
+	cNotFunctions = map[string]bool{
+		"sizeof": true, "alignof": true, "_Alignof": true, "decltype": true, "noexcept": true,
+		"throw": true, "requires": true, "alignas": true, "_Alignas": true, "typeof": true, "__typeof__": true,
+	}
+)
+
+// newCSource prepares C or C++ code, recording the export macros it defines
+func newCSource(text string) *cSource {
+	var directives [][2]int
+	literalEnd := func(text string, i int) (int, bool) {
+		if end, ok := cDirectiveEnd(text, i); ok {
+			directives = append(directives, [2]int{i, end})
+			return end, true
+		}
+		return quotedLiteralEnd(text, i)
+	}
+	src := &cSource{
+		braceSource:  newBraceSource(text, literalEnd, false),
+		exportMacros: make(map[string]bool),
+	}
+
+	code := []byte(src.code)
+	for _, d := range directives {
+		directive := strings.ReplaceAll(src.code[d[0]:d[1]], "\\\n", " ")
+		if m := cDefineRE.FindStringSubmatch(directive); m != nil && m[2] == "" {
+			value := strings.TrimSpace(m[3])
+			if cExportValueRE.MatchString(value) || src.exportMacros[value] {
+				src.exportMacros[m[1]] = true
+			}
+		}
+		for k := d[0]; k < d[1]; k++ {
+			if code[k] != '\n' {
+				code[k] = ' '
+			}
+		}
+	}
+	src.code = string(code)
+	return src
+}
+
+// cDirectiveEnd returns the end of the preprocessor directive starting at i,
+// including continuation lines, if i starts one
+func cDirectiveEnd(text string, i int) (int, bool) {
+	if text[i] != '#' {
+		return 0, false
+	}
+	for k := i - 1; k >= 0 && text[k] != '\n'; k-- {
+		if text[k] != ' ' && text[k] != '\t' {
+			return 0, false
+		}
+	}
+	for k := i; k < len(text); k++ {
+		if text[k] == '\n' && (k == 0 || text[k-1] != '\\') {
+			return k, true
+		}
+	}
+	return len(text), true
+}
+
+// isExportMacro reports whether an identifier is a macro marking exported
+// symbols, either defined in the file or named like one, e.g. MYLIB_API
+func (s *cSource) isExportMacro(word string) bool {
+	return s.exportMacros[word] || cExportNameRE.MatchString(word)
+}
+
+// decls parses the declarations between start and end. access is the
+// default access of the enclosing scope, "private" in C++ classes.
+func (s *cSource) decls(start, end int, access string) []cDecl {
+	var decls []cDecl
+	raw := s.declarations(start, end, false)
+	prevEnd := start
+
+	for i := 0; i < len(raw); i++ {
+		d := raw[i]
+		declEnd := d.End
+
+		// A struct body is followed by its declarators up to the semicolon:
+		// "typedef struct { ... } point_t;"
+		declarators := ""
+		if d.Body != [2]int{} && i+1 < len(raw) && raw[i+1].Body == [2]int{} &&
+			!strings.Contains(s.masked[d.End:raw[i+1].Start], ";") && cTagKeyword(s.code[d.Start:d.HeaderEnd]) {
+			next := raw[i+1]
+			declarators = strings.Join(strings.Fields(s.code[next.Start:next.HeaderEnd]), " ")
+			declEnd = next.End
+			i++
+		}
+
+		decl := s.parseDecl(d.Start, d.HeaderEnd, &access)
+		decl.Body = d.Body
+		decl.Declarators = declarators
+		decl.Doc = cDocComment(s.text[prevEnd:d.HeaderEnd])
+		decl.Trailing = s.trailingDoc(declEnd)
+		if declarators != "" {
+			s.parseDeclarators(&decl)
+		}
+		prevEnd = declEnd
+		if decl.Kind == "" && decl.Header == "" {
+			continue
+		}
+		decls = append(decls, decl)
+	}
+	return decls
+}
+
+// cTagKeyword reports whether a header declares a struct, union, class or enum
+func cTagKeyword(header string) bool {
+	if strings.Contains(header, "(") && !strings.Contains(header, "__attribute__") && !strings.Contains(header, "__declspec") {
+		return false
+	}
+	for _, word := range strings.Fields(header) {
+		switch word {
+		case "struct", "union", "class", "enum":
+			return true
+		}
+	}
+	return false
+}
+
+// trailingDoc returns the member documentation after a declaration, on the
+// same line: "int x; ///< the x coordinate"
+func (s *cSource) trailingDoc(end int) string {
+	lineEnd := strings.IndexByte(s.text[end:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(s.text) - end
+	}
+	rest := strings.TrimSpace(s.text[end : end+lineEnd])
+	switch {
+	case strings.HasPrefix(rest, "///<"), strings.HasPrefix(rest, "//!<"):
+		return strings.TrimSpace(rest[4:])
+	case strings.HasPrefix(rest, "/**<"), strings.HasPrefix(rest, "/*!<"):
+		rest = strings.TrimLeft(s.text[end:], " \t")
+		if close := strings.Index(rest, "*/"); close >= 0 {
+			return braceDocComment("/** " + rest[4:close] + " */")
+		}
+	}
+	return ""
+}
+
+// cDocComment collects the Doxygen comments (/** */, /*! */, /// and //!)
+// preceding a declaration. File and group comments and member documentation
+// of the previous declaration are left out.
+func cDocComment(text string) string {
+	text = cDocFileRE.ReplaceAllString(text, "")
+	text = cMemberDocRE.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, "/*!", "/**")
+	text = strings.ReplaceAll(text, "//!", "///")
+	return braceDocComment(text)
+}
+
+// parseDecl classifies the header between start and end, updating access
+// with any access labels that precede it
+func (s *cSource) parseDecl(start, end int, access *string) cDecl {
+	header := strings.Join(strings.Fields(s.code[start:end]), " ")
+	for {
+		m := cAccessRE.FindStringSubmatch(header)
+		if m == nil {
+			break
+		}
+		*access = m[1]
+		header = strings.TrimSpace(header[len(m[0])-len(m[2]):])
+	}
+
+	decl := cDecl{Access: *access}
+	if header == "" {
+		return decl
+	}
+	header = s.stripAttributes(&decl, header)
+	if header == "" {
+		return decl
+	}
+	decl.Line = s.lineAt(start + cWordIndex(s.code[start:end], strings.Fields(header)[0]))
+
+	// Linkage specifications and namespaces wrap other declarations
+	switch {
+	case header == `extern "C"` || header == `extern "C++"`:
+		decl.Kind = "linkage"
+		return decl
+	case strings.HasPrefix(header, `extern "C" `), strings.HasPrefix(header, `extern "C++" `):
+		header = strings.TrimSpace(header[strings.LastIndexByte(header, '"')+1:])
+	}
+	words := strings.Fields(header)
+	if len(words) > 1 && words[0] == "inline" && words[1] == "namespace" {
+		decl.Inline = true
+		words = words[1:]
+	}
+	if len(words) > 0 && words[0] == "namespace" {
+		decl.Kind = "namespace"
+		if len(words) > 1 {
+			decl.Name = words[1]
+		}
+		return decl
+	}
+
+	// Templates are described by their parameters' placeholder names
+	for strings.HasPrefix(header, "template") {
+		open := strings.IndexByte(header, '<')
+		if open < 0 {
+			break
+		}
+		header = strings.TrimSpace(header[braceMatchingBracket(header, open, len(header))+1:])
+	}
+	decl.Header = header
+
+	if strings.HasPrefix(header, "typedef ") {
+		decl.Kind = "typedef"
+		header = strings.TrimPrefix(header, "typedef ")
+		if s.parseTagHeader(&decl, header) {
+			return decl
+		}
+		decl.Type, decl.Name = cSplitDeclarator(header)
+		return decl
+	}
+	if strings.HasPrefix(header, "using ") && strings.Contains(header, "=") {
+		name, typ, _ := strings.Cut(strings.TrimPrefix(header, "using "), "=")
+		decl.Kind = "typedef"
+		decl.Name, decl.Type = strings.TrimSpace(name), strings.TrimSpace(typ)
+		return decl
+	}
+	if s.parseTagHeader(&decl, header) {
+		return decl
+	}
+	// Operators are not callable by name
+	if cOperatorRE.MatchString(header) {
+		return decl
+	}
+	if s.parseFunctionHeader(&decl, header) {
+		return decl
+	}
+
+	decl.Kind = "variable"
+	for _, word := range strings.Fields(header) {
+		if word == "static" {
+			decl.Static = true
+		}
+	}
+	return decl
+}
+
+// cWordIndex returns the offset of the first occurrence of word that is not
+// part of a longer identifier, or 0
+func cWordIndex(text, word string) int {
+	for offset := 0; ; {
+		idx := strings.Index(text[offset:], word)
+		if idx < 0 {
+			return 0
+		}
+		idx += offset
+		end := idx + len(word)
+		if (idx == 0 || !braceIsIdentByte(text[idx-1])) && (end >= len(text) || !braceIsIdentByte(text[end])) {
+			return idx
+		}
+		offset = idx + 1
+	}
+}
+
+// stripAttributes removes attributes, export macros, deprecation macros and
+// linkage macros from a header, recording what they say about the declaration
+func (s *cSource) stripAttributes(decl *cDecl, header string) string {
+	var out strings.Builder
+	for i := 0; i < len(header); {
+		switch {
+		case strings.HasPrefix(header[i:], "[["):
+			end := strings.Index(header[i:], "]]")
+			if end < 0 {
+				end = len(header) - i - 2
+			}
+			attr := header[i : i+end+2]
+			if strings.Contains(attr, "deprecated") {
+				decl.Deprecated = true
+			}
+			i += end + 2
+			continue
+		case i == 0 || !braceIsIdentByte(header[i-1]):
+			word := cIdentRE.FindString(header[i:])
+			if word == "" {
+				break
+			}
+			wordEnd := i + len(word)
+			argsEnd := wordEnd
+			if argsEnd < len(header) && header[argsEnd] == ' ' && argsEnd+1 < len(header) && header[argsEnd+1] == '(' {
+				argsEnd++
+			}
+			hasArgs := argsEnd < len(header) && header[argsEnd] == '('
+			if hasArgs {
+				argsEnd = braceMatchingBracket(header, argsEnd, len(header)) + 1
+			} else {
+				argsEnd = wordEnd
+			}
+
+			switch {
+			case word == "__attribute__" || word == "__declspec":
+				attr := header[wordEnd:argsEnd]
+				if strings.Contains(attr, "deprecated") {
+					decl.Deprecated = true
+				}
+				if cExportValueRE.MatchString(attr) {
+					decl.Exported = true
+				}
+				i = argsEnd
+				continue
+			case word == "alignas" || word == "_Alignas" || word == "__extension__":
+				i = argsEnd
+				continue
+			case s.isExportMacro(word):
+				decl.Exported = true
+				i = argsEnd
+				continue
+			case strings.Contains(word, "DEPRECATED") && strings.ToUpper(word) == word:
+				decl.Deprecated = true
+				i = argsEnd
+				continue
+			case cLinkageMacroRE.MatchString(word):
+				i = argsEnd
+				continue
+			}
+			out.WriteString(word)
+			i = wordEnd
+			continue
+		}
+		out.WriteByte(header[i])
+		i++
+	}
+	return strings.Join(strings.Fields(out.String()), " ")
+}
+
+// parseTagHeader parses struct, union, class and enum declarations, including
+// typedef'd ones. It reports false for headers that merely use such a type.
+func (s *cSource) parseTagHeader(decl *cDecl, header string) bool {
+	words := strings.Fields(header)
+	for len(words) > 0 && (words[0] == "const" || words[0] == "volatile") {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "struct", "union", "class", "enum":
+	default:
+		return false
+	}
+	if strings.Contains(header, "(") {
+		return false
+	}
+
+	kind := words[0]
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.Join(words, " "), kind))
+	if kind == "enum" {
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "class "), "struct ")
+	}
+	tag := cIdentRE.FindString(rest)
+	if tag == "final" {
+		tag = ""
+	}
+	after := strings.TrimSpace(rest[len(tag):])
+	for strings.HasPrefix(after, "::") {
+		next := cIdentRE.FindString(after[2:])
+		tag += "::" + next
+		after = strings.TrimSpace(after[2+len(next):])
+	}
+	after = strings.TrimSpace(strings.TrimPrefix(after, "final"))
+
+	// Headers such as "struct point *origin" declare variables or fields
+	if after != "" && !strings.HasPrefix(after, ":") {
+		if decl.Kind == "typedef" {
+			decl.Type = kind + " " + tag
+			_, decl.Name = cSplitDeclarator(header)
+			return true
+		}
+		return false
+	}
+
+	decl.Kind = kind
+	decl.Tag = tag
+	decl.Name = tag
+	return true
+}
+
+// parseDeclarators reads the names declared after a struct body, using the
+// typedef name for typedef'd structs
+func (s *cSource) parseDeclarators(decl *cDecl) {
+	first := strings.TrimSpace(splitBraceTopLevel(decl.Declarators, ',')[0])
+	name := cIdentRE.FindString(strings.TrimLeft(first, "* "))
+	if name == "" {
+		return
+	}
+	if strings.HasPrefix(decl.Header, "typedef ") {
+		decl.Name = name
+		decl.Header += " { ... } " + decl.Declarators
+	}
+}
+
+// parseFunctionHeader finds the function declared by a header: the first
+// parenthesis preceded by a name that is itself preceded by a return type
+func (s *cSource) parseFunctionHeader(decl *cDecl, header string) bool {
+	typeStart := 0
+	for i := 0; i < len(header); i++ {
+		switch header[i] {
+		case '<', '[':
+			i = braceMatchingBracket(header, i, len(header))
+			continue
+		case '=':
+			// Initializers, e.g. "int x = f(1)", are not functions
+			return false
+		case '(':
+		default:
+			continue
+		}
+
+		nameEnd := strings.TrimRight(header[:i], " ")
+		m := cFunctionNameRE.FindString(nameEnd)
+		closing := braceMatchingBracket(header, i, len(header))
+		if m == "" || cNotFunctions[m] || strings.HasPrefix(m, "~") || strings.Contains(m, "::~") {
+			i = closing
+			typeStart = closing + 1
+			continue
+		}
+
+		returns := strings.TrimSpace(header[typeStart : len(nameEnd)-len(m)])
+		var typeWords []string
+		for _, word := range strings.Fields(returns) {
+			switch {
+			case word == "static":
+				decl.Static = true
+			case word == "inline" || word == "__inline" || word == "__inline__" || word == "__forceinline" || word == "constexpr":
+				decl.Inline = true
+			case word == "friend":
+				return false
+			}
+			if !cSpecifiers[word] {
+				typeWords = append(typeWords, word)
+			}
+		}
+		returns = strings.Join(typeWords, " ")
+
+		// Function-like macros and constructors have no return type. What
+		// follows a constructor's parameters is not another declaration, so
+		// the declaration is kept with an empty type for the caller to tell
+		// constructors from macros.
+		if returns == "" {
+			rest := strings.TrimSpace(header[closing+1:])
+			if rest != "" && rest[0] != ':' && rest[0] != '=' && !strings.HasPrefix(rest, "noexcept") {
+				i = closing
+				typeStart = closing + 1
+				continue
+			}
+		}
+
+		decl.Kind = "function"
+		decl.Name = m
+		decl.Type = returns
+		decl.Params = header[i+1 : closing]
+
+		qualifiers := header[closing+1:]
+		if strings.Contains(qualifiers, "= delete") || strings.Contains(qualifiers, "=delete") {
+			decl.Deleted = true
+		}
+		if arrow := strings.Index(qualifiers, "->"); arrow >= 0 && decl.Type == "auto" {
+			decl.Type = strings.TrimSpace(strings.Split(qualifiers[arrow+2:], "=")[0])
+		}
+		decl.Header = strings.TrimSpace(header)
+		return true
+	}
+	return false
+}
+
+// cSplitDeclarator splits "const char *name[4]" into its type, "const char *[4]",
+// and its name. Function pointer declarators such as "void (*cb)(int)" yield
+// the pointer's name.
+func cSplitDeclarator(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	if open := strings.Index(text, "(*"); open >= 0 {
+		if m := cIdentRE.FindString(strings.TrimSpace(text[open+2:])); m != "" {
+			return strings.TrimSpace(text[:open]) + " (*)" + text[braceMatchingBracket(text, open, len(text))+1:], m
+		}
+	}
+	m := cTrailingIdentRE.FindStringSubmatchIndex(text)
+	if m == nil {
+		return text, ""
+	}
+	name := text[m[2]:m[3]]
+	typ := strings.TrimSpace(text[:m[2]])
+	qualifiersOnly := true
+	for _, word := range strings.Fields(typ) {
+		if word != "const" && word != "volatile" {
+			qualifiersOnly = false
+		}
+	}
+	if qualifiersOnly || cTypeWord(name) || strings.HasSuffix(typ, "struct") || strings.HasSuffix(typ, "enum") ||
+		strings.HasSuffix(typ, "union") || strings.HasSuffix(typ, "class") || strings.HasSuffix(typ, "::") {
+		// An unnamed parameter such as "unsigned int" or "struct point *"
+		return text, ""
+	}
+	return typ + strings.ReplaceAll(text[m[4]:m[5]], " ", ""), name
+}
+
+// cTypeWord reports whether a word can only be part of a type
+func cTypeWord(word string) bool {
+	switch word {
+	case "void", "char", "short", "int", "long", "float", "double", "signed", "unsigned",
+		"bool", "_Bool", "const", "volatile", "restrict", "__restrict", "auto", "wchar_t",
+		"char8_t", "char16_t", "char32_t", "size_t":
+		return true
+	}
+	return false
+}
+
+// parseCParams parses a parameter list. "(void)" declares no parameters and
+// variadic "..." parameters are left out.
+func parseCParams(text string) []cParam {
+	text = strings.TrimSpace(text)
+	if text == "" || text == "void" {
+		return nil
+	}
+
+	var params []cParam
+	masked := newBraceSource(text, quotedLiteralEnd, false).masked
+	for i, part := range splitBraceMasked(text, masked, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "..." || strings.HasSuffix(part, "...") {
+			continue
+		}
+
+		var p cParam
+		if eq := braceScanTo(part, "="); eq >= 0 {
+			p.Default = strings.TrimSpace(part[eq+1:])
+			p.HasDefault = true
+			part = strings.TrimSpace(part[:eq])
+		}
+		p.Type, p.Name = cSplitDeclarator(part)
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("arg%d", i)
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// cLiteral evaluates a default argument, dropping C numeric suffixes such as 10u or 1.5f
+func cLiteral(text string) (interface{}, bool) {
+	text = strings.TrimSpace(text)
+	if trimmed := strings.TrimRight(text, "uUlLfF"); trimmed != text && tsNumberRE.MatchString(trimmed) {
+		text = trimmed
+	}
+	return parseJSLiteral(text)
+}
+
+// parseCDoc parses a Doxygen comment. Backslash commands are read like their
+// @ forms, "@brief" introduces the summary and "@code" blocks are examples.
+func parseCDoc(text string) docComment {
+	text = cDoxygenCmdRE.ReplaceAllString(text, "$1@$2")
+	text = cDoxygenInlineRE.ReplaceAllString(text, "")
+	text = cDoxygenParamRE.ReplaceAllString(text, "@param")
+	text = cDoxygenCodeRE.ReplaceAllString(text, "@example")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "@endcode":
+			continue
+		case strings.HasPrefix(trimmed, "@brief "), strings.HasPrefix(trimmed, "@short "):
+			line = strings.TrimSpace(trimmed[len("@brief "):])
+		case strings.HasPrefix(trimmed, "@details"):
+			line = "@description" + strings.TrimPrefix(trimmed, "@details")
+		case strings.HasPrefix(trimmed, "@result"):
+			line = "@returns" + strings.TrimPrefix(trimmed, "@result")
+		}
+		lines = append(lines, line)
+	}
+
+	doc := parseJSDoc(strings.Join(lines, "\n"))
+	for i, raised := range doc.Raises {
+		if raised.Name == "" {
+			name, description, _ := strings.Cut(raised.Description, " ")
+			doc.Raises[i] = docParam{Name: name, Description: strings.TrimSpace(description)}
+		}
+	}
+	return doc
+}
+
+// extractCSignatures extracts the public functions, structs, unions, enums
+// and C++ classes declared in C or C++ source, typically a header. When the
+// file marks its exports with a macro such as MYLIB_API or with
+// __declspec(dllexport), only the marked functions are returned.
+func extractCSignatures(code string) ([]FunctionSignature, error) {
+	src := newCSource(code)
+	decls := src.decls(0, len(code), "public")
+	types := collectCTypes(src, decls)
+
+	var signatures []FunctionSignature
+	src.appendSignatures(&signatures, decls, "", !src.hasExports(decls), "", types)
+	return signatures, nil
+}
+
+// hasExports reports whether any declaration is marked with an export macro
+// or attribute, in which case unmarked functions are not part of the ABI
+func (s *cSource) hasExports(decls []cDecl) bool {
+	for _, decl := range decls {
+		if decl.Exported {
+			return true
+		}
+		if (decl.Kind == "linkage" || decl.Kind == "namespace") && decl.Body != [2]int{} &&
+			s.hasExports(s.decls(decl.Body[0], decl.Body[1], "public")) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendSignatures adds the public declarations, naming namespace members and
+// class members with their qualified C++ names, e.g. ns::Class::method.
+// exported is set when every function in scope belongs to the ABI, and class
+// is the qualified name of the enclosing struct or class, if any.
+func (s *cSource) appendSignatures(signatures *[]FunctionSignature, decls []cDecl, prefix string, exported bool, class string, types map[string]cTypeDecl) {
+	for _, decl := range decls {
+		if decl.Access != "public" {
+			continue
+		}
+
+		switch decl.Kind {
+		case "linkage":
+			if decl.Body != [2]int{} {
+				s.appendSignatures(signatures, s.decls(decl.Body[0], decl.Body[1], "public"), prefix, exported, "", types)
+			}
+		case "namespace":
+			// Anonymous and implementation namespaces are not part of the API
+			if decl.Name == "" || decl.Name == "detail" || decl.Name == "internal" || decl.Body == [2]int{} {
+				continue
+			}
+			nested := prefix
+			if !decl.Inline {
+				nested = prefix + decl.Name + "::"
+			}
+			s.appendSignatures(signatures, s.decls(decl.Body[0], decl.Body[1], "public"), nested, exported, "", types)
+		case "struct", "union", "class", "enum":
+			if decl.Name == "" || decl.Body == [2]int{} || cReserved(decl.Name) {
+				continue
+			}
+			name := prefix + decl.Name
+			kind := decl.Kind
+			if kind == "union" {
+				kind = "struct"
+			}
+			sig := s.declSignature(decl, name, kind)
+			if decl.Kind != "enum" {
+				schema := cTypeSchema(decl.Name, types, map[string]bool{})
+				if props, _ := schema["properties"].(map[string]interface{}); len(props) > 0 {
+					sig.Parameters = map[string]interface{}{
+						"type":       "object",
+						"properties": props,
+					}
+					sig.Required = goStringSlice(schema["required"])
+				}
+			}
+			*signatures = append(*signatures, sig)
+
+			if decl.Kind != "enum" {
+				access := "public"
+				if decl.Kind == "class" {
+					access = "private"
+				}
+				s.appendSignatures(signatures, s.decls(decl.Body[0], decl.Body[1], access), name+"::", exported || decl.Exported, name, types)
+			}
+		case "function":
+			// Static free functions have internal linkage
+			if decl.Static && class == "" || decl.Deleted || cReserved(decl.Name) {
+				continue
+			}
+			if !exported && !decl.Exported && !decl.Inline {
+				continue
+			}
+			kind := "function"
+			switch {
+			case decl.Type == "" && class != "" && strings.HasSuffix("::"+class, "::"+decl.Name):
+				// Copies and moves are not called by name
+				if cCopyConstructor(decl) {
+					continue
+				}
+				kind = "constructor"
+			case decl.Type == "":
+				// Function-like macro invocations
+				continue
+			case class != "":
+				kind = "method"
+			}
+			sig := s.functionSignature(decl, prefix+decl.Name, kind, types)
+			if class != "" {
+				sig.Class = class
+				sig.Static = decl.Static
+			}
+			*signatures = append(*signatures, sig)
+		}
+	}
+}
+
+// cCopyConstructor reports whether a constructor takes a single reference to
+// its own class, as copy and move constructors do
+func cCopyConstructor(decl cDecl) bool {
+	params := parseCParams(decl.Params)
+	if len(params) != 1 || !strings.Contains(params[0].Type, "&") {
+		return false
+	}
+	return cIdentRE.FindString(strings.TrimPrefix(strings.TrimSpace(params[0].Type), "const ")) == decl.Name
+}
+
+// cReserved reports whether a name is reserved for the implementation, as
+// names starting with two underscores or an underscore and a capital are
+func cReserved(name string) bool {
+	return strings.HasPrefix(name, "__") || len(name) > 1 && name[0] == '_' && name[1] >= 'A' && name[1] <= 'Z'
+}
+
+// declSignature fills the fields shared by every kind of declaration
+func (s *cSource) declSignature(decl cDecl, name, kind string) FunctionSignature {
+	doc := parseCDoc(decl.Doc)
+	return FunctionSignature{
+		Name:        name,
+		Type:        kind,
+		Signature:   decl.Header,
+		Description: doc.String(),
+		Returns:     docReturns(doc),
+		Raises:      docRaises(doc),
+		Deprecated:  doc.Deprecated || decl.Deprecated,
+		Examples:    doc.Examples,
+		Line:        decl.Line,
+	}
+}
+
+// functionSignature builds the signature of a function, mapping its parameters
+func (s *cSource) functionSignature(decl cDecl, name, kind string, types map[string]cTypeDecl) FunctionSignature {
+	sig := s.declSignature(decl, name, kind)
+	doc := parseCDoc(decl.Doc)
+
+	properties := make(map[string]interface{})
+	required := []string{}
+	for _, p := range parseCParams(decl.Params) {
+		schema := cTypeSchema(p.Type, types, map[string]bool{})
+		if value, ok := cLiteral(p.Default); ok && p.HasDefault {
+			schema["default"] = value
+		}
+
+		description := fmt.Sprintf("Parameter %s", p.Name)
+		if dp, ok := doc.Param(p.Name); ok && dp.Description != "" {
+			description = dp.Description
+		}
+		schema["description"] = description
+		properties[p.Name] = schema
+
+		if !p.HasDefault {
+			required = append(required, p.Name)
+		}
+	}
+
+	sig.Parameters = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	sig.Required = required
+	if sig.Returns == "" && decl.Type != "void" {
+		sig.Returns = decl.Type
+	}
+	return sig
+}
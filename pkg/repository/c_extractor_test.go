@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCDoc(t *testing.T) {
+	doc := parseCDoc("\\brief Opens a file.\n\nUses @c fopen internally.\n\n@param[in] path the file path\n\\param mode the open mode\n@return a handle, or @c NULL on error\n@throws std::runtime_error if the file is locked\n@deprecated\n@code{.c}\nFILE *f = lib_open(\"a\", \"r\");\n@endcode")
+
+	assert.Equal(t, "Opens a file.", doc.Summary)
+	assert.Equal(t, "Uses fopen internally.", doc.Description)
+	assert.Equal(t, []docParam{
+		{Name: "path", Description: "the file path"},
+		{Name: "mode", Description: "the open mode"},
+	}, doc.Params)
+	assert.Equal(t, "a handle, or NULL on error", doc.Returns)
+	assert.Equal(t, []docParam{{Name: "std::runtime_error", Description: "if the file is locked"}}, doc.Raises)
+	assert.True(t, doc.Deprecated)
+	assert.Equal(t, []string{`FILE *f = lib_open("a", "r");`}, doc.Examples)
+}
+
+func TestExtractCSignatures(t *testing.T) {
+	code := `/**
+ * @file mylib.h
+ * @brief The library header.
+ */
+#ifndef MYLIB_H
+#define MYLIB_H
+
+#ifdef _WIN32
+#  define MYLIB_API __declspec(dllexport)
+#else
+#  define MYLIB_API __attribute__((visibility("default")))
+#endif
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/** Log levels. */
+typedef enum {
+    LOG_DEBUG, ///< verbose output
+    LOG_INFO = 1,
+    LOG_ERROR
+} log_level_t;
+
+/// A point in 2D space.
+typedef struct point {
+    double x; ///< the x coordinate
+    double y; /**< the y coordinate */
+    const char *label;
+    unsigned flags : 3;
+} point_t;
+
+struct opaque;
+
+/**
+ * \brief Adds two integers.
+ *
+ * @param[in] a first operand
+ * @param[in] b second operand
+ * @return the sum
+ */
+MYLIB_API int mylib_add(int a, int b);
+
+MYLIB_API void mylib_move(point_t *p,
+                          double dx, double dy) __attribute__((deprecated));
+
+MYLIB_API const char *mylib_name(void);
+
+MYLIB_API void mylib_log(log_level_t level, const char *fmt, ...);
+
+int internal_helper(int x);
+
+static inline int twice(int x) { return x * 2; }
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`
+
+	signatures, err := extractCSignatures(code)
+	require.NoError(t, err)
+
+	var names []string
+	for _, sig := range signatures {
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{"log_level_t", "point_t", "mylib_add", "mylib_move", "mylib_name", "mylib_log"}, names)
+
+	level := signatures[0]
+	assert.Equal(t, "enum", level.Type)
+	assert.Equal(t, "Log levels.", level.Description)
+	assert.Equal(t, "typedef enum { ... } log_level_t", level.Signature)
+
+	point := signatures[1]
+	assert.Equal(t, "struct", point.Type)
+	assert.Equal(t, "A point in 2D space.", point.Description)
+	assert.Equal(t, 26, point.Line)
+	assert.Equal(t, []string{"x", "y", "label", "flags"}, point.Required)
+	pointProps := point.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "number", "description": "the x coordinate"}, pointProps["x"])
+	assert.Equal(t, map[string]interface{}{"type": "number", "description": "the y coordinate"}, pointProps["y"])
+	assert.Equal(t, map[string]interface{}{"type": "string"}, pointProps["label"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "minimum": 0}, pointProps["flags"])
+
+	add := signatures[2]
+	assert.Equal(t, "function", add.Type)
+	assert.Equal(t, "int mylib_add(int a, int b)", add.Signature)
+	assert.Equal(t, "Adds two integers.", add.Description)
+	assert.Equal(t, "the sum", add.Returns)
+	assert.Equal(t, []string{"a", "b"}, add.Required)
+	assert.Equal(t, map[string]interface{}{"type": "integer", "description": "first operand"},
+		add.Parameters["properties"].(map[string]interface{})["a"])
+
+	move := signatures[3]
+	assert.True(t, move.Deprecated)
+	assert.Equal(t, "void mylib_move(point_t *p, double dx, double dy)", move.Signature)
+	assert.Equal(t, "object", move.Parameters["properties"].(map[string]interface{})["p"].(map[string]interface{})["type"])
+	assert.Empty(t, move.Returns)
+
+	name := signatures[4]
+	assert.Equal(t, "const char *", name.Returns)
+	assert.Empty(t, name.Parameters["properties"])
+
+	logFn := signatures[5]
+	assert.Equal(t, []string{"level", "fmt"}, logFn.Required)
+	assert.Equal(t, []interface{}{"LOG_DEBUG", "LOG_INFO", "LOG_ERROR"},
+		logFn.Parameters["properties"].(map[string]interface{})["level"].(map[string]interface{})["enum"])
+}
+
+func TestExtractCSignaturesWithoutExportMacros(t *testing.T) {
+	code := `G_BEGIN_DECLS
+
+/* Not a doc comment. */
+int add(int, int);
+
+/// Frees a buffer.
+void buffer_free(struct buffer *buf);
+
+static int helper(void);
+int __internal(void);
+
+G_END_DECLS
+`
+
+	signatures, err := extractCSignatures(code)
+	require.NoError(t, err)
+	require.Len(t, signatures, 2)
+
+	assert.Equal(t, "add", signatures[0].Name)
+	assert.Equal(t, "", signatures[0].Description)
+	assert.Equal(t, []string{"arg0", "arg1"}, signatures[0].Required)
+	assert.Equal(t, 4, signatures[0].Line)
+
+	assert.Equal(t, "buffer_free", signatures[1].Name)
+	assert.Equal(t, "Frees a buffer.", signatures[1].Description)
+	assert.Equal(t, "void buffer_free(struct buffer *buf)", signatures[1].Signature)
+}
+
+func TestExtractCPlusPlusSignatures(t *testing.T) {
+	code := `#pragma once
+#include <string>
+
+namespace geo {
+
+/// Shape kinds.
+enum class Kind : uint8_t { Circle, Square };
+
+namespace detail { int hidden(int); }
+
+/**
+ * A drawable canvas.
+ */
+class GEO_API Canvas : public Base {
+public:
+    Canvas(int w, int h) : w_(w), h_(h) {}
+    Canvas(const Canvas& other);
+    ~Canvas();
+
+    /**
+     * Draws shapes.
+     * @param shapes the shapes to draw
+     * @param scale scale factor
+     * @throws std::invalid_argument if a shape is empty
+     */
+    std::size_t draw(const std::vector<Kind>& shapes, double scale = 1.5) const;
+
+    [[deprecated("use title()")]] std::string name() const { return title_; }
+
+    static Canvas* create(std::optional<std::string> title);
+
+    bool operator==(const Canvas& other) const;
+    Canvas& operator=(const Canvas&) = delete;
+
+    int width; ///< width in pixels
+
+protected:
+    void redraw();
+
+private:
+    int w_, h_;
+    std::string title_;
+};
+
+GEO_API std::vector<std::pair<int, std::string>> list_items(const std::map<std::string, int>& index, int limit = 10);
+
+int unexported(int x);
+
+} // namespace geo
+`
+
+	signatures, err := extractCSignatures(code)
+	require.NoError(t, err)
+
+	var names []string
+	for _, sig := range signatures {
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{
+		"geo::Kind", "geo::Canvas", "geo::Canvas::Canvas", "geo::Canvas::draw", "geo::Canvas::name", "geo::Canvas::create",
+		"geo::list_items",
+	}, names)
+
+	canvas := signatures[1]
+	assert.Equal(t, "class", canvas.Type)
+	assert.Equal(t, "class Canvas : public Base", canvas.Signature)
+	assert.Equal(t, []string{"width"}, canvas.Required)
+
+	ctor := signatures[2]
+	assert.Equal(t, "constructor", ctor.Type)
+	assert.Equal(t, "geo::Canvas", ctor.Class)
+	assert.Equal(t, []string{"w", "h"}, ctor.Required)
+
+	draw := signatures[3]
+	assert.Equal(t, "method", draw.Type)
+	assert.Equal(t, "geo::Canvas", draw.Class)
+	assert.False(t, draw.Static)
+	assert.Equal(t, []string{"shapes"}, draw.Required)
+	assert.Equal(t, "std::size_t", draw.Returns)
+	assert.Equal(t, []string{"std::invalid_argument: if a shape is empty"}, draw.Raises)
+	drawProps := draw.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string", "enum": []interface{}{"Circle", "Square"}},
+		"description": "the shapes to draw",
+	}, drawProps["shapes"])
+	assert.Equal(t, 1.5, drawProps["scale"].(map[string]interface{})["default"])
+
+	assert.True(t, signatures[4].Deprecated)
+	assert.True(t, signatures[5].Static)
+	assert.Equal(t, map[string]interface{}{"type": "string", "nullable": true, "description": "Parameter title"},
+		signatures[5].Parameters["properties"].(map[string]interface{})["title"])
+
+	items := signatures[6]
+	assert.Equal(t, "function", items.Type)
+	assert.Equal(t, []string{"index"}, items.Required)
+	assert.Equal(t, int64(10), items.Parameters["properties"].(map[string]interface{})["limit"].(map[string]interface{})["default"])
+}
+
+func TestExtractCPlusPlusStringDefaultWithComma(t *testing.T) {
+	code := `class Widget {
+public:
+    Widget(int w, const std::string& label = "a,b", char sep = ',');
+};
+`
+
+	signatures, err := extractCSignatures(code)
+	require.NoError(t, err)
+	require.Len(t, signatures, 2)
+
+	ctor := signatures[1]
+	assert.Equal(t, "Widget::Widget", ctor.Name)
+	assert.Equal(t, []string{"w"}, ctor.Required)
+	props := ctor.Parameters["properties"].(map[string]interface{})
+	assert.Len(t, props, 3)
+	assert.Equal(t, "a,b", props["label"].(map[string]interface{})["default"])
+	assert.Equal(t, ",", props["sep"].(map[string]interface{})["default"])
+}
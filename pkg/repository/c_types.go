@@ -0,0 +1,424 @@
+package repository
+
+import (
+	"strings"
+)
+
+// cTypeDecl is a struct, union, enum or typedef declared in the source
+type cTypeDecl struct {
+	Fields    []cField
+	Union     bool
+	Constants []interface{} // enum constants
+	Alias     string        // typedef or using declaration
+}
+
+// cField is a public data member of a struct, union or class
+type cField struct {
+	Name        string
+	Type        string
+	Description string
+	Nested      *cTypeDecl // anonymous struct, union or enum declared in place
+}
+
+// collectCTypes indexes the structs, unions, classes, enums and typedefs
+// declared in the source by tag and typedef name
+func collectCTypes(src *cSource, decls []cDecl) map[string]cTypeDecl {
+	types := make(map[string]cTypeDecl)
+
+	var collect func(decls []cDecl)
+	collect = func(decls []cDecl) {
+		for _, decl := range decls {
+			switch decl.Kind {
+			case "linkage", "namespace":
+				if decl.Body != [2]int{} {
+					collect(src.decls(decl.Body[0], decl.Body[1], "public"))
+				}
+			case "struct", "union", "class", "enum":
+				if decl.Body == [2]int{} {
+					continue
+				}
+				typ := src.typeDecl(decl)
+				for _, name := range []string{decl.Tag, decl.Name} {
+					if name != "" {
+						types[name] = typ
+					}
+				}
+				if decl.Kind != "enum" {
+					collect(src.decls(decl.Body[0], decl.Body[1], cDefaultAccess(decl.Kind)))
+				}
+			case "typedef":
+				// "typedef struct point point;" names the struct itself
+				if _, ok := types[decl.Name]; ok || decl.Name == "" || cBaseName(decl.Type) == decl.Name {
+					continue
+				}
+				types[decl.Name] = cTypeDecl{Alias: decl.Type}
+			}
+		}
+	}
+	collect(decls)
+
+	return types
+}
+
+// cDefaultAccess is the access of members declared before any access label
+func cDefaultAccess(kind string) string {
+	if kind == "class" {
+		return "private"
+	}
+	return "public"
+}
+
+// cBaseName returns the type name without qualifiers, pointers, namespaces
+// or template arguments: "const struct ns::point *" yields "point"
+func cBaseName(typ string) string {
+	if open := strings.IndexByte(typ, '<'); open >= 0 {
+		typ = typ[:open]
+	}
+	words := strings.Fields(strings.NewReplacer("*", " ", "&", " ").Replace(typ))
+	if len(words) == 0 {
+		return ""
+	}
+	name := words[len(words)-1]
+	if idx := strings.LastIndex(name, "::"); idx >= 0 {
+		name = name[idx+2:]
+	}
+	return name
+}
+
+// typeDecl describes the fields or constants of a struct, union, class or enum
+func (s *cSource) typeDecl(decl cDecl) cTypeDecl {
+	if decl.Kind == "enum" {
+		var constants []interface{}
+		for _, part := range splitBraceMasked(s.code[decl.Body[0]:decl.Body[1]], s.masked[decl.Body[0]:decl.Body[1]], ',') {
+			if name := cIdentRE.FindString(strings.TrimSpace(part)); name != "" {
+				constants = append(constants, name)
+			}
+		}
+		return cTypeDecl{Constants: constants}
+	}
+	return cTypeDecl{Fields: s.fields(decl), Union: decl.Kind == "union"}
+}
+
+// fields returns the public, non-static data members of a struct, union or class
+func (s *cSource) fields(decl cDecl) []cField {
+	var fields []cField
+	for _, member := range s.decls(decl.Body[0], decl.Body[1], cDefaultAccess(decl.Kind)) {
+		if member.Access != "public" || member.Static {
+			continue
+		}
+		description := parseCDoc(member.Doc).String()
+		if description == "" {
+			description = member.Trailing
+		}
+
+		switch member.Kind {
+		case "variable":
+			declarators := splitBraceTopLevel(cBitFieldRE.ReplaceAllString(member.Header, ""), ',')
+			if len(declarators) == 0 {
+				continue
+			}
+			typ, name := cSplitDeclarator(declarators[0])
+			if name == "" {
+				continue
+			}
+			fields = append(fields, cField{Name: name, Type: typ, Description: description})
+
+			// "int x, *y;" declares further fields of the same base type
+			base := typ
+			if idx := strings.IndexAny(typ, "*&["); idx >= 0 {
+				base = strings.TrimSpace(typ[:idx])
+			}
+			for _, d := range declarators[1:] {
+				d = cBitFieldRE.ReplaceAllString(d, "")
+				if typ, name := cSplitDeclarator(base + " " + strings.TrimSpace(d)); name != "" {
+					fields = append(fields, cField{Name: name, Type: typ, Description: description})
+				}
+			}
+		case "struct", "union", "enum":
+			if member.Body == [2]int{} {
+				continue
+			}
+			nested := s.typeDecl(member)
+			if member.Declarators == "" {
+				// Anonymous structs and unions add their members to the enclosing type
+				if member.Name == "" && member.Kind != "enum" {
+					fields = append(fields, nested.Fields...)
+				}
+				continue
+			}
+			for _, d := range splitBraceTopLevel(member.Declarators, ',') {
+				typ, name := cSplitDeclarator("T " + strings.TrimSpace(d))
+				if name == "" {
+					continue
+				}
+				field := cField{Name: name, Type: strings.TrimSpace(strings.TrimPrefix(typ, "T")), Description: description}
+				field.Nested = &nested
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// cGenericArgs splits "std::vector<T>" into its simple name and template arguments
+func cGenericArgs(typ string) (string, []string) {
+	name := typ
+	var args []string
+	if open := strings.IndexByte(typ, '<'); open >= 0 && strings.HasSuffix(typ, ">") {
+		name = typ[:open]
+		for _, arg := range splitBraceTopLevel(typ[open+1:len(typ)-1], ',') {
+			if arg = strings.TrimSpace(arg); arg != "" {
+				args = append(args, arg)
+			}
+		}
+	}
+	if idx := strings.LastIndex(name, "::"); idx >= 0 {
+		name = name[idx+2:]
+	}
+	return strings.TrimSpace(name), args
+}
+
+// cTypeTokens splits a type into words, keeping template arguments with
+// their template and giving each * its own token
+func cTypeTokens(typ string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for i := 0; i < len(typ); i++ {
+		switch c := typ[i]; c {
+		case '<':
+			end := braceMatchingBracket(typ, i, len(typ))
+			current.WriteString(typ[i : end+1])
+			i = end
+		case '*':
+			flush()
+			tokens = append(tokens, "*")
+		case ' ', '\t', '&':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// cIntegerWords are the words of the multi-word C integer types, e.g. "unsigned long long"
+var cIntegerWords = map[string]bool{"signed": true, "unsigned": true, "short": true, "long": true, "int": true, "char": true}
+
+// cTypeSchema maps a C or C++ type to a JSON Schema. Character pointers and
+// arrays are strings, other pointers are described by what they point to and
+// pointers to pointers are arrays. seen guards against infinite recursion
+// through self-referencing types.
+func cTypeSchema(typ string, types map[string]cTypeDecl, seen map[string]bool) map[string]interface{} {
+	typ = strings.TrimSpace(typ)
+
+	// Function pointers and blocks cannot be passed as JSON
+	if typ == "" || strings.Contains(typ, "(*") || strings.Contains(typ, "(^") {
+		return map[string]interface{}{}
+	}
+
+	// Arrays, outermost dimension first
+	if strings.HasSuffix(typ, "]") {
+		open := strings.IndexByte(typ, '[')
+		closing := open + strings.IndexByte(typ[open:], ']')
+		elem := strings.TrimSpace(typ[:open] + typ[closing+1:])
+		if cCharType(elem) {
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": cTypeSchema(elem, types, seen),
+		}
+	}
+
+	pointers := 0
+	var words []string
+	for _, token := range cTypeTokens(typ) {
+		switch token {
+		case "*":
+			pointers++
+		case "const", "volatile", "restrict", "__restrict", "struct", "union", "enum", "class", "typename":
+		default:
+			words = append(words, token)
+		}
+	}
+	base := strings.Join(words, " ")
+
+	if pointers > 0 {
+		if base == "void" {
+			return map[string]interface{}{}
+		}
+		schema := map[string]interface{}{"type": "string"}
+		if !cCharType(base) {
+			schema = cTypeSchema(base, types, seen)
+		}
+		for ; pointers > 1; pointers-- {
+			schema = map[string]interface{}{"type": "array", "items": schema}
+		}
+		return schema
+	}
+
+	integer := len(words) > 0 && base != "char"
+	for _, word := range words {
+		integer = integer && cIntegerWords[word]
+	}
+	if integer {
+		if strings.Contains(base, "unsigned") {
+			return map[string]interface{}{"type": "integer", "minimum": 0}
+		}
+		return map[string]interface{}{"type": "integer"}
+	}
+
+	name, args := cGenericArgs(base)
+	switch name {
+	case "int8_t", "int16_t", "int32_t", "int64_t", "intptr_t", "intmax_t", "ptrdiff_t", "ssize_t",
+		"off_t", "time_t", "int_fast8_t", "int_fast16_t", "int_fast32_t", "int_fast64_t",
+		"int_least8_t", "int_least16_t", "int_least32_t", "int_least64_t":
+		return map[string]interface{}{"type": "integer"}
+	case "uint8_t", "uint16_t", "uint32_t", "uint64_t", "uintptr_t", "uintmax_t", "size_t",
+		"uint_fast8_t", "uint_fast16_t", "uint_fast32_t", "uint_fast64_t",
+		"uint_least8_t", "uint_least16_t", "uint_least32_t", "uint_least64_t", "byte":
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case "float", "double", "long double", "float_t", "double_t":
+		return map[string]interface{}{"type": "number"}
+	case "bool", "_Bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "char", "wchar_t", "char8_t", "char16_t", "char32_t", "string", "wstring", "u8string",
+		"u16string", "u32string", "string_view", "wstring_view", "path":
+		return map[string]interface{}{"type": "string"}
+	case "void", "nullptr_t":
+		return map[string]interface{}{"type": "null"}
+	case "any", "function":
+		return map[string]interface{}{}
+	case "optional":
+		if len(args) == 1 {
+			schema := cTypeSchema(args[0], types, seen)
+			schema["nullable"] = true
+			return schema
+		}
+	case "unique_ptr", "shared_ptr", "weak_ptr", "reference_wrapper", "atomic":
+		if len(args) > 0 {
+			return cTypeSchema(args[0], types, seen)
+		}
+	case "vector", "list", "deque", "forward_list", "span", "initializer_list", "valarray", "array", "multiset":
+		schema := map[string]interface{}{"type": "array"}
+		if len(args) > 0 {
+			schema["items"] = cTypeSchema(args[0], types, seen)
+		}
+		return schema
+	case "set", "unordered_set":
+		schema := map[string]interface{}{"type": "array", "uniqueItems": true}
+		if len(args) > 0 {
+			schema["items"] = cTypeSchema(args[0], types, seen)
+		}
+		return schema
+	case "map", "unordered_map", "multimap":
+		schema := map[string]interface{}{"type": "object"}
+		if len(args) == 2 {
+			schema["additionalProperties"] = cTypeSchema(args[1], types, seen)
+		}
+		return schema
+	case "pair", "tuple":
+		items := make([]interface{}, 0, len(args))
+		for _, arg := range args {
+			items = append(items, cTypeSchema(arg, types, seen))
+		}
+		return map[string]interface{}{
+			"type":        "array",
+			"prefixItems": items,
+			"minItems":    len(args),
+			"maxItems":    len(args),
+		}
+	}
+
+	decl, ok := types[name]
+	if !ok || seen[name] {
+		// Template parameters, opaque types and recursive references
+		if len(name) == 1 && name[0] >= 'A' && name[0] <= 'Z' {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"type": "object"}
+	}
+
+	nested := make(map[string]bool, len(seen)+1)
+	for k, v := range seen {
+		nested[k] = v
+	}
+	nested[name] = true
+	return cTypeDeclSchema(decl, types, nested)
+}
+
+// cTypeDeclSchema maps a declared type. Union members are alternatives, so
+// none of them is required.
+func cTypeDeclSchema(decl cTypeDecl, types map[string]cTypeDecl, seen map[string]bool) map[string]interface{} {
+	switch {
+	case decl.Alias != "":
+		return cTypeSchema(decl.Alias, types, seen)
+	case decl.Constants != nil:
+		return enumSchema(decl.Constants)
+	}
+
+	properties := make(map[string]interface{})
+	required := []string{}
+	for _, field := range decl.Fields {
+		var schema map[string]interface{}
+		if field.Nested != nil {
+			schema = cTypeDeclSchema(*field.Nested, types, seen)
+			if field.Type != "" {
+				// A nested declarator such as "points[4]" or "*next"
+				schema = cWrapSchema(field.Type, schema)
+			}
+		} else {
+			schema = cTypeSchema(field.Type, types, seen)
+		}
+		if field.Description != "" {
+			schema["description"] = field.Description
+		}
+		properties[field.Name] = schema
+		if !decl.Union {
+			required = append(required, field.Name)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// cWrapSchema applies the pointer and array suffix of a declarator, such as
+// "*[4]", to the schema of its base type
+func cWrapSchema(suffix string, schema map[string]interface{}) map[string]interface{} {
+	pointers := strings.Count(suffix, "*")
+	dimensions := strings.Count(suffix, "[")
+	for i := 1; i < pointers; i++ {
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+	for i := 0; i < dimensions; i++ {
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+	return schema
+}
+
+// cCharType reports whether a type is a character type, whose pointers and
+// arrays are strings
+func cCharType(typ string) bool {
+	words := strings.Fields(typ)
+	var kept []string
+	for _, word := range words {
+		if word != "const" && word != "volatile" {
+			kept = append(kept, word)
+		}
+	}
+	switch strings.Join(kept, " ") {
+	case "char", "wchar_t", "char8_t", "char16_t", "char32_t", "TCHAR", "WCHAR", "gchar":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCTypeSchema(t *testing.T) {
+	types := map[string]cTypeDecl{
+		"color":   {Constants: []interface{}{"RED", "GREEN"}},
+		"color_t": {Alias: "enum color"},
+		"node": {Fields: []cField{
+			{Name: "value", Type: "int", Description: "the value"},
+			{Name: "next", Type: "struct node *"},
+		}},
+		"number": {Union: true, Fields: []cField{
+			{Name: "i", Type: "long"},
+			{Name: "d", Type: "double"},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		typeText string
+		expected map[string]interface{}
+	}{
+		{"int", "int", map[string]interface{}{"type": "integer"}},
+		{"unsigned long long", "unsigned long long", map[string]interface{}{"type": "integer", "minimum": 0}},
+		{"signed char", "signed char", map[string]interface{}{"type": "integer"}},
+		{"uint32_t", "const uint32_t", map[string]interface{}{"type": "integer", "minimum": 0}},
+		{"size_t", "std::size_t", map[string]interface{}{"type": "integer", "minimum": 0}},
+		{"double", "double", map[string]interface{}{"type": "number"}},
+		{"long double", "long double", map[string]interface{}{"type": "number"}},
+		{"bool", "_Bool", map[string]interface{}{"type": "boolean"}},
+		{"char", "char", map[string]interface{}{"type": "string"}},
+		{"c string", "const char *", map[string]interface{}{"type": "string"}},
+		{"char array", "char[64]", map[string]interface{}{"type": "string"}},
+		{"string array", "const char **", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}},
+		{"int array", "int[4]", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "integer"},
+		}},
+		{"matrix", "float[3][3]", map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "number"},
+			},
+		}},
+		{"void pointer", "void *", map[string]interface{}{}},
+		{"void", "void", map[string]interface{}{"type": "null"}},
+		{"function pointer", "void (*)(int)", map[string]interface{}{}},
+		{"int pointer", "int *", map[string]interface{}{"type": "integer"}},
+		{"reference", "const std::string &", map[string]interface{}{"type": "string"}},
+		{"vector", "std::vector<int>", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "integer"},
+		}},
+		{"set", "std::set<std::string>", map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"uniqueItems": true,
+		}},
+		{"map", "std::unordered_map<std::string, double>", map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "number"},
+		}},
+		{"optional", "std::optional<bool>", map[string]interface{}{"type": "boolean", "nullable": true}},
+		{"pair", "std::pair<int, std::string>", map[string]interface{}{
+			"type": "array",
+			"prefixItems": []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "string"},
+			},
+			"minItems": 2,
+			"maxItems": 2,
+		}},
+		{"enum", "enum color", map[string]interface{}{"type": "string", "enum": []interface{}{"RED", "GREEN"}}},
+		{"typedef", "color_t", map[string]interface{}{"type": "string", "enum": []interface{}{"RED", "GREEN"}}},
+		{"recursive struct", "const struct node *", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"value": map[string]interface{}{"type": "integer", "description": "the value"},
+				"next":  map[string]interface{}{"type": "object"},
+			},
+			"required": []string{"value", "next"},
+		}},
+		{"union", "union number", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"i": map[string]interface{}{"type": "integer"},
+				"d": map[string]interface{}{"type": "number"},
+			},
+			"required": []string{},
+		}},
+		{"template parameter", "T", map[string]interface{}{}},
+		{"opaque", "FILE *", map[string]interface{}{"type": "object"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, cTypeSchema(tc.typeText, types, map[string]bool{}))
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultContentWindowSize is the byte limit of get_file_content when the
+	// server is not given a content window size
+	defaultContentWindowSize = 5000
+
+	// maxContentBytes is the largest max_bytes get_file_content accepts
+	maxContentBytes = 1 << 20
+
+	// sniffSize is how much of a file is read to tell text from binary
+	// content, as much as http.DetectContentType considers
+	sniffSize = 512
+)
+
+// lineRange selects the part of a text file get_file_content returns
+type lineRange struct {
+	StartLine int // 1-based, inclusive
+	EndLine   int // 1-based, inclusive; 0 reads to the end of the file
+	MaxBytes  int
+}
+
+// fileContentResult reads a repository file for get_file_content: the
+// selected lines of a text file, cut at MaxBytes with a truncation marker, an
+// image no larger than MaxBytes as a base64 blob, and the metadata of other
+// binary files
+func fileContentResult(repo *RepoFS, relPath string, r lineRange) (*mcp.CallToolResult, error) {
+	f, err := repo.Open(relPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", relPath)
+	}
+
+	reader := bufio.NewReaderSize(f, 64*1024)
+	head, err := reader.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if looksBinary(head) {
+		return binaryFileResult(reader, repoFileURI(repo, relPath), relPath, info.Size(), http.DetectContentType(head), r.MaxBytes)
+	}
+
+	text, err := readLines(reader, info.Size(), r)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// looksBinary reports whether the first bytes of a file are binary: they
+// hold a NUL byte or are not UTF-8, ignoring a rune cut at their end
+func looksBinary(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	for i := 0; i < utf8.UTFMax-1 && !utf8.Valid(head); i++ {
+		head = head[:len(head)-1]
+	}
+	return !utf8.Valid(head)
+}
+
+func binaryFileResult(r io.Reader, uri, relPath string, size int64, mimeType string, maxBytes int) (*mcp.CallToolResult, error) {
+	if strings.HasPrefix(mimeType, "image/") && size <= int64(maxBytes) {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		return mcp.NewToolResultResource(fmt.Sprintf("%s is an image of %d bytes (%s)", relPath, size, mimeType), mcp.BlobResourceContents{
+			URI:      uri,
+			MIMEType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString(content),
+		}), nil
+	}
+
+	note := "binary content is not returned"
+	if strings.HasPrefix(mimeType, "image/") {
+		note = fmt.Sprintf("the image is larger than max_bytes, pass max_bytes of at least %d to receive it", size)
+	}
+	result, err := json.MarshalIndent(BinaryFile{
+		Path:     relPath,
+		Size:     size,
+		MIMEType: mimeType,
+		Binary:   true,
+		Note:     note,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal file metadata: %w", err)
+	}
+	return mcp.NewToolResultText(string(result)), nil
+}
+
+// repoFileURI returns the URI of a repository file: a file URI when the
+// repository is on disk, and a repo URI naming the repository, and the
+// revision it was read at, otherwise
+func repoFileURI(repo *RepoFS, relPath string) string {
+	if dir := repo.Dir(); dir != "" {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(relPath)))}).String()
+	}
+	uri := &url.URL{Scheme: "repo", Host: repo.Name(), Path: "/" + relPath}
+	if rev := repo.Revision(); rev != "" {
+		uri.RawQuery = url.Values{"ref": {rev}}.Encode()
+	}
+	return uri.String()
+}
+
+// readLines reads the lines of a text file selected by r. When the lines
+// are longer than MaxBytes, it returns the whole lines that fit, followed by
+// a truncation marker telling where to continue.
+func readLines(reader *bufio.Reader, size int64, r lineRange) (string, error) {
+	var out strings.Builder
+	line := 0
+	for r.EndLine == 0 || line < r.EndLine {
+		// Lines before the range are skipped without being kept
+		limit := 0
+		if line+1 >= r.StartLine {
+			limit = r.MaxBytes - out.Len() + 1
+		}
+		text, ok, err := readLine(reader, limit)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+		if !ok {
+			break
+		}
+		line++
+
+		if line >= r.StartLine {
+			if out.Len()+len(text) > r.MaxBytes {
+				return out.String() + truncationMarker(out.Len(), size, r.StartLine, line, text, r.MaxBytes), nil
+			}
+			out.WriteString(text)
+		}
+	}
+
+	if r.StartLine > 1 && line < r.StartLine {
+		return "", fmt.Errorf("start_line %d is past the end of the file (%d lines)", r.StartLine, line)
+	}
+	return out.String(), nil
+}
+
+// readLine consumes a line, including its newline, and returns up to limit
+// bytes of it. It returns false at the end of the file.
+func readLine(reader *bufio.Reader, limit int) (string, bool, error) {
+	var line []byte
+	read := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		read = read || len(chunk) > 0
+		if room := limit - len(line); room > 0 {
+			line = append(line, chunk[:min(room, len(chunk))]...)
+		}
+		switch err {
+		case nil:
+			return string(line), true, nil
+		case bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			return string(line), read, nil
+		default:
+			return "", false, err
+		}
+	}
+}
+
+// truncationMarker ends content cut before line next, telling how much was
+// returned and how to read on. The content returned so far ends with a newline.
+func truncationMarker(returned int, size int64, start, next int, nextLine string, maxBytes int) string {
+	if next == start {
+		// Not even the first line fits, so return what does, without cutting a rune
+		cut := maxBytes
+		for cut > 0 && !utf8.RuneStart(nextLine[cut]) {
+			cut--
+		}
+		return fmt.Sprintf("%s\n[truncated: line %d is longer than max_bytes (%d bytes), pass a larger max_bytes to read it whole]\n",
+			nextLine[:cut], next, maxBytes)
+	}
+	return fmt.Sprintf("[truncated: returned lines %d-%d (%d of %d bytes), continue with start_line=%d]\n",
+		start, next-1, returned, size, next)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetFileContent(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	writeTree(t, map[string]string{
+		"lines.txt":   "one\ntwo\nthree\nfour\nfive",
+		"empty.txt":   "",
+		"wide.txt":    "héllo wörld\nsecond\n",
+		"logo.png":    png,
+		"archive.zip": "PK\x03\x04\x00\x00binary",
+	})
+
+	call := func(args map[string]interface{}) *mcp.CallToolResult {
+		t.Helper()
+		result, err := handleGetFileContent(context.Background(), createMCPRequest(args), nil, 12)
+		require.NoError(t, err)
+		return result
+	}
+	text := func(args map[string]interface{}) string {
+		t.Helper()
+		result := call(args)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+		return getTextResult(t, result).Text
+	}
+
+	t.Run("line ranges", func(t *testing.T) {
+		assert.Equal(t, "one\ntwo\nthree\nfour\nfive", text(map[string]interface{}{"path": "lines.txt", "max_bytes": float64(100)}))
+		assert.Equal(t, "two\nthree\n", text(map[string]interface{}{"path": "lines.txt", "start_line": float64(2), "end_line": float64(3)}))
+		assert.Equal(t, "five", text(map[string]interface{}{"path": "lines.txt", "start_line": float64(5)}))
+		assert.Equal(t, "", text(map[string]interface{}{"path": "empty.txt"}))
+
+		result := call(map[string]interface{}{"path": "lines.txt", "start_line": float64(7)})
+		require.True(t, result.IsError)
+		assert.Equal(t, "start_line 7 is past the end of the file (5 lines)", getTextResult(t, result).Text)
+
+		result = call(map[string]interface{}{"path": "lines.txt", "start_line": float64(3), "end_line": float64(2)})
+		require.True(t, result.IsError)
+		assert.Equal(t, "end_line must not be before start_line", getTextResult(t, result).Text)
+	})
+
+	t.Run("truncation", func(t *testing.T) {
+		// The content window size of 12 bytes is the default limit
+		assert.Equal(t, "one\ntwo\n[truncated: returned lines 1-2 (8 of 23 bytes), continue with start_line=3]\n",
+			text(map[string]interface{}{"path": "lines.txt"}))
+		assert.Equal(t, "three\nfour\n[truncated: returned lines 3-4 (11 of 23 bytes), continue with start_line=5]\n",
+			text(map[string]interface{}{"path": "lines.txt", "start_line": float64(3)}))
+		assert.Equal(t, "hé\n[truncated: line 1 is longer than max_bytes (3 bytes), pass a larger max_bytes to read it whole]\n",
+			text(map[string]interface{}{"path": "wide.txt", "max_bytes": float64(3)}))
+		assert.Equal(t, "h\n[truncated: line 1 is longer than max_bytes (2 bytes), pass a larger max_bytes to read it whole]\n",
+			text(map[string]interface{}{"path": "wide.txt", "max_bytes": float64(2)}))
+	})
+
+	t.Run("images", func(t *testing.T) {
+		result := call(map[string]interface{}{"path": "logo.png", "max_bytes": float64(100)})
+		require.False(t, result.IsError)
+		require.Len(t, result.Content, 2)
+		assert.Equal(t, "logo.png is an image of 16 bytes (image/png)", result.Content[0].(mcp.TextContent).Text)
+
+		resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+		assert.Equal(t, "image/png", resource.MIMEType)
+		assert.True(t, strings.HasPrefix(resource.URI, "file:///"))
+		assert.True(t, strings.HasSuffix(resource.URI, "/logo.png"))
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(png)), resource.Blob)
+
+		var file BinaryFile
+		require.NoError(t, json.Unmarshal([]byte(text(map[string]interface{}{"path": "logo.png"})), &file))
+		assert.Equal(t, BinaryFile{
+			Path:     "logo.png",
+			Size:     16,
+			MIMEType: "image/png",
+			Binary:   true,
+			Note:     "the image is larger than max_bytes, pass max_bytes of at least 16 to receive it",
+		}, file)
+	})
+
+	t.Run("binary files", func(t *testing.T) {
+		var file BinaryFile
+		require.NoError(t, json.Unmarshal([]byte(text(map[string]interface{}{"path": "archive.zip", "max_bytes": float64(1000)})), &file))
+		assert.Equal(t, BinaryFile{
+			Path:     "archive.zip",
+			Size:     12,
+			MIMEType: "application/zip",
+			Binary:   true,
+			Note:     "binary content is not returned",
+		}, file)
+	})
+}
+
+func TestLooksBinary(t *testing.T) {
+	assert.False(t, looksBinary([]byte("plain text\n")))
+	assert.False(t, looksBinary([]byte("caf\xc3\xa9")))
+	assert.False(t, looksBinary([]byte("caf\xc3")), "a rune cut at the end is still text")
+	assert.True(t, looksBinary([]byte("a\x00b")))
+	assert.True(t, looksBinary([]byte("\xff\xfe\xfa latin")))
+}
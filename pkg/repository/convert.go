@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// ConvertOptions selects the files and symbols Convert turns into tools
+type ConvertOptions struct {
+	Include           []string // globs of files to read; all files when empty
+	Exclude           []string // globs of files to skip
+	MaxFiles          int      // maximum number of source files to read; defaultRepositoryFiles when zero
+	IncludeDeprecated bool     // also convert symbols marked as deprecated
+}
+
+// ToolBundle is the result of converting a repository: the tool definitions,
+// where each tool's symbol is declared, and the symbols that were left out
+type ToolBundle struct {
+	Tools     []ToolDefinition `json:"tools"`
+	Sources   []ToolSource     `json:"sources"`
+	Skipped   []SkippedSymbol  `json:"skipped"`
+	Files     int              `json:"files"`               // number of source files read
+	Truncated bool             `json:"truncated,omitempty"` // more files exist beyond MaxFiles
+}
+
+// ToolSource locates the symbol a tool was generated from
+type ToolSource struct {
+	Tool     string   `json:"tool"`
+	Symbol   string   `json:"symbol"`
+	Path     string   `json:"path"`
+	Line     int      `json:"line,omitempty"`
+	Language string   `json:"language"`
+	Warnings []string `json:"warnings,omitempty"` // fixes applied to the tool, e.g. a renamed duplicate
+}
+
+// SkippedSymbol is a symbol, or a whole file when Symbol is empty, that was
+// not converted into a tool
+type SkippedSymbol struct {
+	Symbol string `json:"symbol,omitempty"`
+	Path   string `json:"path"`
+	Line   int    `json:"line,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Convert runs the whole conversion pipeline on a repository: it lists the
+// source files, extracts their signatures and emits a tool definition for
+// every public function, method and constructor. Classes and other types are
+// reported as skipped, as are deprecated symbols unless requested and symbols
+// whose parameters are not a valid schema. The tools are validated as
+// emit_tool_json does, so a tool whose name is already taken is renamed with
+// a numeric suffix.
+func Convert(ctx context.Context, repo *RepoFS, opts ConvertOptions) (*ToolBundle, error) {
+	maxFiles := opts.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = defaultRepositoryFiles
+	}
+
+	files, truncated, err := collectSourceFiles(ctx, repo, opts.Include, opts.Exclude, maxFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	bundle := &ToolBundle{
+		Tools:     []ToolDefinition{},
+		Sources:   []ToolSource{},
+		Skipped:   []SkippedSymbol{},
+		Files:     len(files),
+		Truncated: truncated,
+	}
+	// Callable symbols become tools, in file order, so renamed duplicates are stable
+	var descriptors []FunctionDescriptor
+	var sources []ToolSource
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		signatures, err := extractFileSignatures(repo, file.Path, file.Language)
+		if err != nil {
+			bundle.Skipped = append(bundle.Skipped, SkippedSymbol{Path: file.Path, Reason: err.Error()})
+			continue
+		}
+
+		for _, sig := range signatures {
+			skip := func(reason string) {
+				bundle.Skipped = append(bundle.Skipped, SkippedSymbol{Symbol: sig.Name, Path: file.Path, Line: sig.Line, Reason: reason})
+			}
+			switch {
+			case !callable(sig):
+				skip(fmt.Sprintf("%s is not callable", sig.Type))
+				continue
+			case sig.Deprecated && !opts.IncludeDeprecated:
+				skip("deprecated")
+				continue
+			}
+
+			descriptors = append(descriptors, signatureDescriptor(sig))
+			sources = append(sources, ToolSource{Symbol: sig.Name, Path: file.Path, Line: sig.Line, Language: file.Language})
+		}
+	}
+
+	tools, warnings, errors := toolFormats[DefaultToolFormat].emit(descriptors, true)
+	for _, issue := range errors {
+		source := sources[issue.Index]
+		bundle.Skipped = append(bundle.Skipped, SkippedSymbol{
+			Symbol: source.Symbol,
+			Path:   source.Path,
+			Line:   source.Line,
+			Reason: fmt.Sprintf("%s: %s", issue.Field, issue.Message),
+		})
+	}
+	for _, issue := range warnings {
+		sources[issue.Index].Warnings = append(sources[issue.Index].Warnings, issue.Message)
+	}
+	for _, tool := range tools {
+		source := sources[tool.Index]
+		source.Tool = tool.Name
+		bundle.Tools = append(bundle.Tools, tool.Tool.(ToolDefinition))
+		bundle.Sources = append(bundle.Sources, source)
+	}
+
+	return bundle, nil
+}
+
+// callable reports whether a signature describes something a tool can call
+func callable(sig FunctionSignature) bool {
+	switch sig.Type {
+	case "function", "method", "constructor":
+		return true
+	}
+	return false
+}
+
+// signatureDescriptor converts an extracted signature into the descriptor
+// accepted by emit_tool_json
+func signatureDescriptor(sig FunctionSignature) FunctionDescriptor {
+	parameters := sig.Parameters
+	if parameters == nil {
+		parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	required := sig.Required
+	if required == nil {
+		required = []string{}
+	}
+	return FunctionDescriptor{
+		Name:        sig.Name,
+		Type:        sig.Type,
+		Class:       sig.Class,
+		Description: sig.Description,
+		Parameters:  parameters,
+		Required:    required,
+		Deprecated:  sig.Deprecated,
+		Examples:    sig.Examples,
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"shop/cart.py": `class Cart:
+    """A cart."""
+
+    def add(self, sku: str, qty: int = 1):
+        """Adds an item."""
+
+def checkout(cart_id: str):
+    """Checks out."""
+
+def legacy():
+    """Old.
+
+    .. deprecated:: 1.0
+    """
+`,
+		"shop/cart_util.py":  "def checkout(cart_id: str):\n    pass\n",
+		"web/api.ts":         "/** Lists carts. */\nexport function listCarts(limit?: number) {\n}\n",
+		"web/broken.go":      "package broken\nfunc (",
+		"tests/test_cart.py": "def test_add():\n    pass\n",
+	})
+
+	bundle, err := Convert(context.Background(), openRepo(t, root), ConvertOptions{Exclude: []string{"tests"}})
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range bundle.Tools {
+		names = append(names, tool.Function.Name)
+	}
+	assert.Equal(t, []string{"Cart_add", "checkout", "checkout_2", "listCarts"}, names)
+	assert.Equal(t, 4, bundle.Files)
+	assert.False(t, bundle.Truncated)
+
+	assert.Equal(t, []ToolSource{
+		{Tool: "Cart_add", Symbol: "Cart.add", Path: "shop/cart.py", Line: 4, Language: "python"},
+		{Tool: "checkout", Symbol: "checkout", Path: "shop/cart.py", Line: 7, Language: "python"},
+		{Tool: "checkout_2", Symbol: "checkout", Path: "shop/cart_util.py", Line: 1, Language: "python", Warnings: []string{
+			`description is empty, using "Calls checkout."`,
+			"tool name checkout is already used, using checkout_2",
+		}},
+		{Tool: "listCarts", Symbol: "listCarts", Path: "web/api.ts", Line: 2, Language: "typescript"},
+	}, bundle.Sources)
+
+	require.Len(t, bundle.Skipped, 3)
+	assert.Equal(t, SkippedSymbol{Symbol: "Cart", Path: "shop/cart.py", Line: 1, Reason: "class is not callable"}, bundle.Skipped[0])
+	assert.Equal(t, SkippedSymbol{Symbol: "legacy", Path: "shop/cart.py", Line: 10, Reason: "deprecated"}, bundle.Skipped[1])
+	assert.Equal(t, "web/broken.go", bundle.Skipped[2].Path)
+	assert.Empty(t, bundle.Skipped[2].Symbol)
+
+	add := bundle.Tools[0].Function.Parameters
+	assert.Equal(t, []interface{}{"sku"}, add["required"])
+
+	withDeprecated, err := Convert(context.Background(), openRepo(t, root), ConvertOptions{Include: []string{"shop/cart.py"}, IncludeDeprecated: true})
+	require.NoError(t, err)
+	assert.Len(t, withDeprecated.Tools, 3)
+	assert.Equal(t, "Deprecated. Old.", withDeprecated.Tools[2].Function.Description)
+}
+
+func TestHandleConvertRepository(t *testing.T) {
+	writeTree(t, map[string]string{
+		"a.py": "def one():\n    pass\n",
+		"b.py": "def two():\n    pass\n",
+	})
+
+	result, err := handleConvertRepository(context.Background(), createMCPRequest(map[string]interface{}{"max_files": float64(1)}), nil)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var bundle ToolBundle
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &bundle))
+	require.Len(t, bundle.Tools, 1)
+	assert.Equal(t, "one", bundle.Tools[0].Function.Name)
+	assert.True(t, bundle.Truncated)
+}
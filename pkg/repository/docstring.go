@@ -0,0 +1,378 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+)
+
+// docComment is the structured form of a documentation comment
+type docComment struct {
+	Summary     string
+	Description string
+	Params      []docParam
+	ReturnType  string
+	Returns     string
+	Raises      []docParam // Name holds the exception type
+	Deprecated  bool
+	Examples    []string
+
+	DeprecationNote string
+}
+
+// docParam documents a single parameter or raised exception
+type docParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+var (
+	sphinxFieldRE   = regexp.MustCompile(`^:(param|parameter|arg|argument|key|keyword|type|returns?|rtype|raises?|except|exception)\b\s*([^:]*):\s*(.*)$`)
+	googleParamRE   = regexp.MustCompile(`^\*{0,2}([A-Za-z_][A-Za-z0-9_]*)\s*(?:\(([^)]*)\))?\s*:\s*(.*)$`)
+	numpyDividerRE  = regexp.MustCompile(`^-{3,}\s*$`)
+	googleSectionRE = regexp.MustCompile(`^([A-Za-z][A-Za-z ]*):\s*$`)
+	docCollectionRE = regexp.MustCompile(`^(list|tuple|set|sequence|iterable) of (.+)$`)
+)
+
+// docSections maps recognised section headers to the kind of content they hold
+var docSections = map[string]string{
+	"args":               "params",
+	"arguments":          "params",
+	"parameters":         "params",
+	"params":             "params",
+	"keyword args":       "params",
+	"keyword arguments":  "params",
+	"keyword parameters": "params",
+	"other parameters":   "params",
+	"kwargs":             "params",
+	"returns":            "returns",
+	"return":             "returns",
+	"yields":             "returns",
+	"yield":              "returns",
+	"raises":             "raises",
+	"raise":              "raises",
+	"exceptions":         "raises",
+	"except":             "raises",
+	"examples":           "examples",
+	"example":            "examples",
+	"note":               "other",
+	"notes":              "other",
+	"see also":           "other",
+	"warning":            "other",
+	"warnings":           "other",
+	"references":         "other",
+	"attributes":         "other",
+	"todo":               "other",
+	"methods":            "other",
+	"warns":              "other",
+	"receives":           "other",
+}
+
+// parseDocstring parses a cleaned Python docstring in Google, NumPy or
+// Sphinx/reST style into its summary, parameters, return value and exceptions
+func parseDocstring(text string) docComment {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	for _, line := range lines {
+		if sphinxFieldRE.MatchString(strings.TrimSpace(line)) {
+			return parseSphinxDocstring(lines)
+		}
+	}
+	return parseSectionedDocstring(lines)
+}
+
+// String renders the summary and extended description without the structured sections
+func (d docComment) String() string {
+	if d.Description == "" {
+		return d.Summary
+	}
+	if d.Summary == "" {
+		return d.Description
+	}
+	return d.Summary + "\n\n" + d.Description
+}
+
+// Param returns the documentation of the named parameter
+func (d docComment) Param(name string) (docParam, bool) {
+	for _, p := range d.Params {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return docParam{}, false
+}
+
+// setProse splits free text into the summary (first paragraph) and description
+func (d *docComment) setProse(lines []string) {
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+	if text == "" {
+		return
+	}
+	parts := strings.SplitN(text, "\n\n", 2)
+	d.Summary = joinDocLines(strings.Split(parts[0], "\n"))
+	if len(parts) > 1 {
+		d.Description = strings.TrimSpace(parts[1])
+	}
+}
+
+// parseSphinxDocstring parses :param x: style field lists
+func parseSphinxDocstring(lines []string) docComment {
+	var doc docComment
+	var prose []string
+	types := make(map[string]string)
+
+	// Join field continuation lines (indented text following a field)
+	var fields []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case sphinxFieldRE.MatchString(trimmed):
+			fields = append(fields, trimmed)
+		case len(fields) > 0 && trimmed != "" && line != trimmed:
+			fields[len(fields)-1] += " " + trimmed
+		case len(fields) == 0:
+			prose = append(prose, line)
+		}
+	}
+	doc.setProse(prose)
+
+	for _, field := range fields {
+		m := sphinxFieldRE.FindStringSubmatch(field)
+		kind, arg, body := m[1], strings.Fields(m[2]), strings.TrimSpace(m[3])
+
+		switch kind {
+		case "param", "parameter", "arg", "argument", "key", "keyword":
+			if len(arg) == 0 {
+				continue
+			}
+			p := docParam{Name: strings.TrimLeft(arg[len(arg)-1], "*"), Description: body}
+			if len(arg) > 1 {
+				p.Type = strings.Join(arg[:len(arg)-1], " ")
+			}
+			doc.Params = append(doc.Params, p)
+		case "type":
+			if len(arg) > 0 {
+				types[strings.TrimLeft(arg[0], "*")] = body
+			}
+		case "return", "returns":
+			doc.Returns = body
+		case "rtype":
+			doc.ReturnType = body
+		case "raise", "raises", "except", "exception":
+			doc.Raises = append(doc.Raises, docParam{Name: strings.Join(arg, " "), Description: body})
+		}
+	}
+
+	for i, p := range doc.Params {
+		if t, ok := types[p.Name]; ok && p.Type == "" {
+			doc.Params[i].Type = t
+		}
+	}
+
+	return doc
+}
+
+// parseSectionedDocstring parses Google style (Args:) and NumPy style
+// (Parameters followed by a dashed underline) sections
+func parseSectionedDocstring(lines []string) docComment {
+	var doc docComment
+
+	type section struct {
+		kind  string
+		numpy bool
+		lines []string
+	}
+	var sections []section
+	var prose []string
+
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		indented := lines[i] != strings.TrimLeft(lines[i], " ")
+
+		// NumPy: a header line underlined with dashes
+		if !indented && i+1 < len(lines) && numpyDividerRE.MatchString(strings.TrimSpace(lines[i+1])) {
+			if kind, ok := docSections[strings.ToLower(trimmed)]; ok {
+				sections = append(sections, section{kind: kind, numpy: true})
+				i++
+				continue
+			}
+		}
+
+		// Google: a header line ending with a colon
+		if m := googleSectionRE.FindStringSubmatch(trimmed); m != nil && !indented {
+			if kind, ok := docSections[strings.ToLower(m[1])]; ok {
+				sections = append(sections, section{kind: kind})
+				continue
+			}
+		}
+
+		if len(sections) == 0 {
+			prose = append(prose, lines[i])
+			continue
+		}
+		sections[len(sections)-1].lines = append(sections[len(sections)-1].lines, lines[i])
+	}
+	doc.setProse(prose)
+
+	// Sphinx's ".. deprecated:: 2.0" directive may appear in any style
+	if idx := strings.Index(doc.Description, ".. deprecated::"); idx >= 0 {
+		doc.Deprecated = true
+		doc.DeprecationNote = joinDocLines(strings.Split(doc.Description[idx+len(".. deprecated::"):], "\n"))
+		doc.Description = strings.TrimSpace(doc.Description[:idx])
+	}
+
+	for _, s := range sections {
+		if s.kind == "examples" {
+			if example := strings.Trim(cleanPythonDocstring("\n"+strings.Join(s.lines, "\n")), "\n"); example != "" {
+				doc.Examples = append(doc.Examples, example)
+			}
+			continue
+		}
+		for _, entry := range docEntries(s.lines) {
+			head, body := entry[0], joinDocLines(entry[1:])
+			switch s.kind {
+			case "params":
+				if p, ok := parseDocParamEntry(head, body, s.numpy); ok {
+					doc.Params = append(doc.Params, p)
+				}
+			case "returns":
+				returnType, description := parseDocReturnEntry(head, body, s.numpy)
+				if doc.ReturnType == "" {
+					doc.ReturnType = returnType
+				}
+				doc.Returns = strings.TrimSpace(strings.Join([]string{doc.Returns, description}, " "))
+			case "raises":
+				name, description := head, body
+				if typ, rest, ok := docTypePrefix(head); ok && !s.numpy {
+					name, description = typ, strings.TrimSpace(rest+" "+body)
+				}
+				doc.Raises = append(doc.Raises, docParam{Name: strings.TrimSuffix(strings.TrimSpace(name), ":"), Description: description})
+			}
+		}
+	}
+
+	return doc
+}
+
+// docEntries groups section lines into entries: a line at the section's base
+// indentation followed by its more indented continuation lines
+func docEntries(lines []string) [][]string {
+	base := -1
+	for _, line := range lines {
+		if trimmed := strings.TrimLeft(line, " "); trimmed != "" {
+			if indent := len(line) - len(trimmed); base < 0 || indent < base {
+				base = indent
+			}
+		}
+	}
+
+	var entries [][]string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if len(line)-len(strings.TrimLeft(line, " ")) == base || len(entries) == 0 {
+			entries = append(entries, []string{trimmed})
+			continue
+		}
+		entries[len(entries)-1] = append(entries[len(entries)-1], trimmed)
+	}
+	return entries
+}
+
+// parseDocParamEntry parses "name (type): description" (Google) or "name : type" (NumPy)
+func parseDocParamEntry(head, body string, numpy bool) (docParam, bool) {
+	if numpy {
+		name, typ := head, ""
+		if idx := strings.Index(head, ":"); idx >= 0 {
+			name, typ = strings.TrimSpace(head[:idx]), strings.TrimSpace(head[idx+1:])
+		}
+		name = strings.TrimLeft(name, "*")
+		if name == "" || strings.ContainsAny(name, " ") {
+			return docParam{}, false
+		}
+		return docParam{Name: name, Type: typ, Description: body}, true
+	}
+
+	m := googleParamRE.FindStringSubmatch(head)
+	if m == nil {
+		return docParam{}, false
+	}
+	return docParam{Name: m[1], Type: strings.TrimSpace(m[2]), Description: strings.TrimSpace(m[3] + " " + body)}, true
+}
+
+// parseDocReturnEntry parses "type: description" (Google) or a type line (NumPy)
+func parseDocReturnEntry(head, body string, numpy bool) (string, string) {
+	if numpy {
+		typ := head
+		if idx := strings.Index(head, ":"); idx >= 0 {
+			typ = strings.TrimSpace(head[idx+1:])
+		}
+		return typ, body
+	}
+	if typ, rest, ok := docTypePrefix(head); ok {
+		return typ, strings.TrimSpace(rest + " " + body)
+	}
+	return "", strings.TrimSpace(head + " " + body)
+}
+
+// docTypePrefix splits "type: description" when the text before the colon
+// looks like a type expression rather than the start of a sentence
+func docTypePrefix(head string) (string, string, bool) {
+	idx := pythonScanTo(head, ":")
+	if idx <= 0 {
+		return "", "", false
+	}
+	typ := strings.TrimSpace(head[:idx])
+
+	// Spaces are only allowed inside brackets or around union bars
+	outside := strings.ReplaceAll(typ, " | ", "|")
+	depth := 0
+	for _, c := range outside {
+		switch c {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case ' ':
+			if depth == 0 {
+				return "", "", false
+			}
+		}
+	}
+	return typ, strings.TrimSpace(head[idx+1:]), true
+}
+
+// docTypeAnnotation strips documentation-only qualifiers such as ", optional"
+// from a documented type so it can be read as an annotation
+func docTypeAnnotation(typ string) string {
+	typ = strings.ReplaceAll(typ, " or ", " | ")
+	parts := splitPythonTopLevel(typ, ',')
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		lower := strings.ToLower(part)
+		if lower == "optional" || strings.HasPrefix(lower, "default") {
+			continue
+		}
+		kept = append(kept, part)
+	}
+	annotation := strings.Join(kept, ", ")
+
+	// NumPy style "list of float"
+	if m := docCollectionRE.FindStringSubmatch(annotation); m != nil {
+		return m[1] + "[" + strings.TrimSuffix(m[2], "s") + "]"
+	}
+	return annotation
+}
+
+func joinDocLines(lines []string) string {
+	trimmed := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			trimmed = append(trimmed, line)
+		}
+	}
+	return strings.Join(trimmed, " ")
+}
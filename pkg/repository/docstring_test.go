@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDocstring(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected docComment
+	}{
+		{
+			name: "google",
+			text: `Greets a person.
+
+Uses their name and age.
+
+Args:
+    name (str): The person's name.
+    age (int, optional): The person's age,
+        in years.
+    **kwargs: Extra options.
+
+Returns:
+    str: A greeting
+        string.
+
+Raises:
+    ValueError: If age is negative.`,
+			expected: docComment{
+				Summary:     "Greets a person.",
+				Description: "Uses their name and age.",
+				Params: []docParam{
+					{Name: "name", Type: "str", Description: "The person's name."},
+					{Name: "age", Type: "int, optional", Description: "The person's age, in years."},
+					{Name: "kwargs", Description: "Extra options."},
+				},
+				ReturnType: "str",
+				Returns:    "A greeting string.",
+				Raises:     []docParam{{Name: "ValueError", Description: "If age is negative."}},
+			},
+		},
+		{
+			name: "numpy",
+			text: `Compute the mean.
+
+Parameters
+----------
+values : list of float
+    Samples to average.
+weights : array_like, optional
+    Per-sample weights.
+
+Returns
+-------
+float
+    The weighted mean.
+
+Raises
+------
+ZeroDivisionError
+    When values is empty.`,
+			expected: docComment{
+				Summary: "Compute the mean.",
+				Params: []docParam{
+					{Name: "values", Type: "list of float", Description: "Samples to average."},
+					{Name: "weights", Type: "array_like, optional", Description: "Per-sample weights."},
+				},
+				ReturnType: "float",
+				Returns:    "The weighted mean.",
+				Raises:     []docParam{{Name: "ZeroDivisionError", Description: "When values is empty."}},
+			},
+		},
+		{
+			name: "sphinx",
+			text: `Open a connection.
+
+:param str host: Server host name.
+:param port: TCP port,
+    defaults to 80.
+:type port: int
+:returns: The connection.
+:rtype: Connection
+:raises TimeoutError: If the server does not answer.`,
+			expected: docComment{
+				Summary: "Open a connection.",
+				Params: []docParam{
+					{Name: "host", Type: "str", Description: "Server host name."},
+					{Name: "port", Type: "int", Description: "TCP port, defaults to 80."},
+				},
+				ReturnType: "Connection",
+				Returns:    "The connection.",
+				Raises:     []docParam{{Name: "TimeoutError", Description: "If the server does not answer."}},
+			},
+		},
+		{
+			name: "plain",
+			text: "Just a summary\nspanning two lines.",
+			expected: docComment{
+				Summary: "Just a summary spanning two lines.",
+			},
+		},
+		{
+			name: "returns sentence with colon",
+			text: "Do it.\n\nReturns:\n    The value as follows: a string.",
+			expected: docComment{
+				Summary: "Do it.",
+				Returns: "The value as follows: a string.",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseDocstring(tc.text))
+		})
+	}
+}
+
+func TestDocTypeAnnotation(t *testing.T) {
+	assert.Equal(t, "int", docTypeAnnotation("int, optional"))
+	assert.Equal(t, "dict[str, int]", docTypeAnnotation("dict[str, int], default {}"))
+	assert.Equal(t, "str | None", docTypeAnnotation("str or None"))
+	assert.Equal(t, "list[float]", docTypeAnnotation("list of float"))
+	assert.Equal(t, "list[int]", docTypeAnnotation("list of ints"))
+}
+
+func TestExtractPythonSignaturesDocstrings(t *testing.T) {
+	code := `def scale(values, factor: float, clamp=False):
+    """Scale values.
+
+    Args:
+        values (list[int]): Numbers to scale.
+        factor: Multiplier.
+
+    Returns:
+        list[float]: The scaled values.
+
+    Raises:
+        ValueError: If factor is zero.
+    """
+`
+
+	signatures, err := extractPythonSignatures(code)
+	require.NoError(t, err)
+	require.Len(t, signatures, 1)
+
+	sig := signatures[0]
+	assert.Equal(t, "Scale values.", sig.Description)
+	assert.Equal(t, "list[float]: The scaled values.", sig.Returns)
+	assert.Equal(t, []string{"ValueError: If factor is zero."}, sig.Raises)
+
+	props := sig.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "integer"},
+		"description": "Numbers to scale.",
+	}, props["values"])
+	assert.Equal(t, map[string]interface{}{"type": "number", "description": "Multiplier."}, props["factor"])
+	assert.Equal(t, "Parameter clamp", props["clamp"].(map[string]interface{})["description"])
+}
+
+func TestExtractPythonSignaturesExamplesAndDeprecation(t *testing.T) {
+	code := `from warnings import deprecated
+
+
+def area(width, height):
+    """Compute an area.
+
+    .. deprecated:: 2.0
+       Use shapes.area instead.
+
+    Examples:
+        >>> area(2, 3)
+        6
+    """
+
+
+@deprecated("use area")
+def surface(width, height):
+    """Compute a surface."""
+`
+
+	signatures, err := extractPythonSignatures(code)
+	require.NoError(t, err)
+	require.Len(t, signatures, 2)
+
+	area := signatures[0]
+	assert.Equal(t, "Compute an area.", area.Description)
+	assert.True(t, area.Deprecated)
+	assert.Equal(t, []string{">>> area(2, 3)\n6"}, area.Examples)
+
+	assert.True(t, signatures[1].Deprecated)
+	assert.Empty(t, signatures[1].Examples)
+}
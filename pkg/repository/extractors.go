@@ -6,340 +6,637 @@ import (
 	"strings"
 )
 
-// extractPythonSignatures extracts function and class signatures from Python source code
+// extractPythonSignatures extracts function, class and method signatures from Python source code
 func extractPythonSignatures(code string) ([]FunctionSignature, error) {
 	var signatures []FunctionSignature
 
-	lines := strings.Split(code, "\n")
-	
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-		
-		// Match function definitions
-		if funcMatch := regexp.MustCompile(`^def\s+(\w+)\s*\((.*?)\)\s*(?:->.*?)?:`).FindStringSubmatch(line); funcMatch != nil {
-			name := funcMatch[1]
-			params := funcMatch[2]
-			
-			// Skip private functions (starting with _)
-			if strings.HasPrefix(name, "_") {
-				continue
-			}
-			
-			// Extract docstring
-			docstring := extractPythonDocstring(lines, i+1)
-			
-			// Parse parameters
-			parameters, required := parsePythonParameters(params)
-			
-			signatures = append(signatures, FunctionSignature{
-				Name:        name,
-				Type:        "function",
-				Signature:   line,
-				Description: docstring,
-				Parameters:  parameters,
-				Required:    required,
-			})
+	defs := parsePythonDefinitions(code)
+	enums := collectPythonEnums(defs)
+	classDocs := make(map[string]docComment)
+
+	for _, def := range defs {
+		// Skip private definitions (starting with _) and functions local to another function
+		if def.Private || def.Nested {
+			continue
 		}
-		
-		// Match class definitions
-		if classMatch := regexp.MustCompile(`^class\s+(\w+)(?:\(.*?\))?:`).FindStringSubmatch(line); classMatch != nil {
-			name := classMatch[1]
-			
-			// Skip private classes (starting with _)
-			if strings.HasPrefix(name, "_") {
-				continue
+
+		signature := def.Signature
+		if len(def.Decorators) > 0 {
+			signature = "@" + strings.Join(def.Decorators, "\n@") + "\n" + signature
+		}
+
+		doc := parseDocstring(def.Docstring)
+		for _, decorator := range def.Decorators {
+			// PEP 702 @deprecated("reason") / @warnings.deprecated(...)
+			if name := strings.SplitN(decorator, "(", 2)[0]; name == "deprecated" || strings.HasSuffix(name, ".deprecated") {
+				doc.Deprecated = true
 			}
-			
-			// Extract docstring
-			docstring := extractPythonDocstring(lines, i+1)
-			
+		}
+
+		if def.Kind == "class" {
+			classDocs[def.Name] = doc
 			signatures = append(signatures, FunctionSignature{
-				Name:        name,
+				Name:        def.Name,
 				Type:        "class",
-				Signature:   line,
-				Description: docstring,
+				Signature:   signature,
+				Description: doc.String(),
+				Deprecated:  doc.Deprecated,
+				Examples:    doc.Examples,
+				Line:        def.Line,
 			})
+			continue
+		}
+
+		kind := "function"
+		params := def.Params
+		static := false
+		if def.Class != "" {
+			kind = "method"
+			for _, decorator := range def.Decorators {
+				switch decorator {
+				case "staticmethod":
+					static = true
+				case "classmethod":
+					// The class is passed in place of the instance
+					static = true
+					params = pythonDropFirstParam(params)
+				}
+			}
+			if !static {
+				params = pythonDropFirstParam(params)
+			}
+
+			// Constructor arguments are commonly documented on the class
+			if strings.HasSuffix(def.Name, ".__init__") {
+				kind = "constructor"
+				classDoc := classDocs[def.Class]
+				if doc.String() == "" {
+					doc.Summary, doc.Description = classDoc.Summary, classDoc.Description
+				}
+				doc.Params = append(doc.Params, classDoc.Params...)
+			}
 		}
+
+		// Parse parameters
+		parameters, required := parsePythonParameters(params, enums, &doc)
+
+		signatures = append(signatures, FunctionSignature{
+			Name:        def.Name,
+			Type:        kind,
+			Class:       def.Class,
+			Static:      static,
+			Signature:   signature,
+			Description: doc.String(),
+			Parameters:  parameters,
+			Required:    required,
+			Returns:     docReturns(doc),
+			Raises:      docRaises(doc),
+			Deprecated:  doc.Deprecated,
+			Examples:    doc.Examples,
+			Line:        def.Line,
+		})
 	}
-	
+
 	return signatures, nil
 }
 
-// extractJavaScriptSignatures extracts function signatures from JavaScript/TypeScript source code
+var (
+	jsFunctionRE   = regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s+(\w+)\s*(?:<[^(]*>)?\s*\(`)
+	jsArrowRE      = regexp.MustCompile(`^(?:export\s+)?const\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:<[^(]*>)?\(`)
+	jsClassRE      = regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)(?:\s+extends\s+\w+)?(?:\s+implements\s+.*?)?`)
+	jsMethodRE     = regexp.MustCompile(`^((?:(?:public|protected|private|static|async|override|abstract|declare)\s+)*)(?:\*\s*)?(#?[A-Za-z_$][\w$]*)\s*\??\s*(?:<[^(]*>)?\s*\(`)
+	jsFieldArrowRE = regexp.MustCompile(`^((?:(?:public|protected|private|static|readonly|override|declare)\s+)*)(#?[A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:<[^(]*>)?\(`)
+)
+
+// jsClassScope is the class whose body is being scanned
+type jsClassScope struct {
+	Name    string
+	End     int // index of the first line after the body
+	Private bool
+	Doc     docComment
+	Seen    map[string]bool // members already reported, so overloads are listed once
+}
+
+// extractJavaScriptSignatures extracts function, class, method and constructor
+// signatures from JavaScript/TypeScript source code. Functions are only read at
+// the top level, and class members only directly inside the class body.
 func extractJavaScriptSignatures(code string) ([]FunctionSignature, error) {
 	var signatures []FunctionSignature
 
 	lines := strings.Split(code, "\n")
-	
+	types := collectTypeScriptTypes(code)
+	depths := jsLineDepths(code)
+
+	var class *jsClassScope
 	for i, line := range lines {
 		line = strings.TrimSpace(line)
-		
+		if class != nil && i >= class.End {
+			class = nil
+		}
+
+		if depths[i] > 0 {
+			if class != nil && depths[i] == 1 && !class.Private {
+				if sig, ok := jsMemberSignature(lines, i, class, types); ok {
+					signatures = append(signatures, sig)
+				}
+			}
+			continue
+		}
+
 		// Match function declarations: function name(params) or function name(params): returnType
-		if funcMatch := regexp.MustCompile(`^(?:export\s+)?(?:async\s+)?function\s+(\w+)\s*\((.*?)\)(?:\s*:\s*.*?)?`).FindStringSubmatch(line); funcMatch != nil {
+		if funcMatch := jsFunctionRE.FindStringSubmatch(line); funcMatch != nil {
 			name := funcMatch[1]
-			params := funcMatch[2]
-			
+			params := jsParameterList(line, len(funcMatch[0])-1)
+
 			// Skip private functions (starting with _)
 			if strings.HasPrefix(name, "_") {
 				continue
 			}
-			
+
 			// Extract JSDoc comment
-			jsdoc := extractJSDocComment(lines, i)
-			
+			jsdoc := parseJSDoc(extractJSDocComment(lines, i))
+
 			// Parse parameters
-			parameters, required := parseJavaScriptParameters(params)
-			
+			parameters, required := parseJavaScriptParameters(params, types, &jsdoc)
+
 			signatures = append(signatures, FunctionSignature{
 				Name:        name,
 				Type:        "function",
 				Signature:   line,
-				Description: jsdoc,
+				Description: jsdoc.String(),
 				Parameters:  parameters,
 				Required:    required,
+				Returns:     docReturns(jsdoc),
+				Raises:      docRaises(jsdoc),
+				Deprecated:  jsdoc.Deprecated,
+				Examples:    jsdoc.Examples,
+				Line:        i + 1,
 			})
 		}
-		
+
 		// Match arrow function exports: export const name = (params) => or const name = (params): returnType =>
-		if arrowMatch := regexp.MustCompile(`^(?:export\s+)?const\s+(\w+)\s*=\s*(?:async\s+)?\((.*?)\)(?:\s*:\s*.*?)?\s*=>`).FindStringSubmatch(line); arrowMatch != nil {
+		if arrowMatch := jsArrowRE.FindStringSubmatch(line); arrowMatch != nil && jsIsArrowFunction(line, len(arrowMatch[0])-1) {
 			name := arrowMatch[1]
-			params := arrowMatch[2]
-			
+			params := jsParameterList(line, len(arrowMatch[0])-1)
+
 			// Skip private functions (starting with _)
 			if strings.HasPrefix(name, "_") {
 				continue
 			}
-			
+
 			// Extract JSDoc comment
-			jsdoc := extractJSDocComment(lines, i)
-			
+			jsdoc := parseJSDoc(extractJSDocComment(lines, i))
+
 			// Parse parameters
-			parameters, required := parseJavaScriptParameters(params)
-			
+			parameters, required := parseJavaScriptParameters(params, types, &jsdoc)
+
 			signatures = append(signatures, FunctionSignature{
 				Name:        name,
 				Type:        "function",
 				Signature:   line,
-				Description: jsdoc,
+				Description: jsdoc.String(),
 				Parameters:  parameters,
 				Required:    required,
+				Returns:     docReturns(jsdoc),
+				Raises:      docRaises(jsdoc),
+				Deprecated:  jsdoc.Deprecated,
+				Examples:    jsdoc.Examples,
+				Line:        i + 1,
 			})
 		}
-		
+
 		// Match class definitions
-		if classMatch := regexp.MustCompile(`^(?:export\s+)?(?:abstract\s+)?class\s+(\w+)(?:\s+extends\s+\w+)?(?:\s+implements\s+.*?)?`).FindStringSubmatch(line); classMatch != nil {
+		if classMatch := jsClassRE.FindStringSubmatch(line); classMatch != nil {
 			name := classMatch[1]
-			
+
+			// Extract JSDoc comment
+			jsdoc := parseJSDoc(extractJSDocComment(lines, i))
+
+			end := i + 1
+			for end < len(lines) && depths[end] > 0 {
+				end++
+			}
+			class = &jsClassScope{
+				Name:    name,
+				End:     end,
+				Private: strings.HasPrefix(name, "_"),
+				Doc:     jsdoc,
+				Seen:    make(map[string]bool),
+			}
+
 			// Skip private classes (starting with _)
-			if strings.HasPrefix(name, "_") {
+			if class.Private {
 				continue
 			}
-			
-			// Extract JSDoc comment
-			jsdoc := extractJSDocComment(lines, i)
-			
+
 			signatures = append(signatures, FunctionSignature{
 				Name:        name,
 				Type:        "class",
 				Signature:   line,
-				Description: jsdoc,
+				Description: jsdoc.String(),
+				Deprecated:  jsdoc.Deprecated,
+				Examples:    jsdoc.Examples,
+				Line:        i + 1,
 			})
 		}
 	}
-	
+
 	return signatures, nil
 }
 
-// extractPythonDocstring extracts docstring from Python code starting at the given line
-func extractPythonDocstring(lines []string, startLine int) string {
-	if startLine >= len(lines) {
-		return ""
+// jsMemberSignature parses a method, constructor or arrow function property
+// declared on line i of a class body. Private, protected, #private and
+// _-prefixed members are skipped, as are accessors and repeated overloads.
+func jsMemberSignature(lines []string, i int, class *jsClassScope, types map[string]string) (FunctionSignature, bool) {
+	line := strings.TrimSpace(lines[i])
+
+	m := jsMethodRE.FindStringSubmatch(line)
+	if m == nil {
+		if m = jsFieldArrowRE.FindStringSubmatch(line); m == nil || !jsIsArrowFunction(line, len(m[0])-1) {
+			return FunctionSignature{}, false
+		}
 	}
-	
-	line := strings.TrimSpace(lines[startLine])
-	
-	// Check for triple-quoted docstring
-	if strings.HasPrefix(line, `"""`) || strings.HasPrefix(line, "'''") {
-		quote := line[:3]
-		
-		// Single line docstring
-		if len(line) > 6 && strings.HasSuffix(line, quote) {
-			return strings.Trim(line[3:len(line)-3], " \t")
-		}
-		
-		// Multi-line docstring
-		var docLines []string
-		if len(line) > 3 {
-			docLines = append(docLines, line[3:])
-		}
-		
-		for i := startLine + 1; i < len(lines); i++ {
-			line := lines[i]
-			if strings.Contains(line, quote) {
-				// Found end quote
-				endIdx := strings.Index(line, quote)
-				if endIdx > 0 {
-					docLines = append(docLines, line[:endIdx])
+	modifiers := strings.Fields(m[1])
+	name := m[2]
+	open := len(m[0]) - 1
+
+	static := false
+	for _, modifier := range modifiers {
+		switch modifier {
+		case "private", "protected":
+			return FunctionSignature{}, false
+		case "static":
+			static = true
+		}
+	}
+	if strings.HasPrefix(name, "#") || strings.HasPrefix(name, "_") || class.Seen[name] {
+		return FunctionSignature{}, false
+	}
+	class.Seen[name] = true
+
+	kind := "method"
+	jsdoc := parseJSDoc(extractJSDocComment(lines, i))
+	if name == "constructor" {
+		// Constructor arguments are commonly documented on the class
+		kind = "constructor"
+		if jsdoc.String() == "" {
+			jsdoc.Summary, jsdoc.Description = class.Doc.Summary, class.Doc.Description
+		}
+		jsdoc.Params = append(jsdoc.Params, class.Doc.Params...)
+	}
+
+	parameters, required := parseJavaScriptParameters(jsParameterList(line, open), types, &jsdoc)
+
+	return FunctionSignature{
+		Name:        class.Name + "." + name,
+		Type:        kind,
+		Class:       class.Name,
+		Static:      static,
+		Signature:   line,
+		Description: jsdoc.String(),
+		Parameters:  parameters,
+		Required:    required,
+		Returns:     docReturns(jsdoc),
+		Raises:      docRaises(jsdoc),
+		Deprecated:  jsdoc.Deprecated,
+		Examples:    jsdoc.Examples,
+		Line:        i + 1,
+	}, true
+}
+
+// jsLineDepths returns the brace depth at the start of every line, ignoring
+// braces in comments and string literals
+func jsLineDepths(code string) []int {
+	stripped := stripJSComments(code)
+	depths := []int{0}
+	depth := 0
+	for i := 0; i < len(stripped); i++ {
+		switch stripped[i] {
+		case '"', '\'', '`':
+			end := jsStringEnd(stripped, i)
+			// Template literals may span lines
+			for _, c := range stripped[i+1 : max(end-1, i+1)] {
+				if c == '\n' {
+					depths = append(depths, depth)
 				}
-				break
 			}
-			docLines = append(docLines, line)
+			i = end - 1
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case '\n':
+			depths = append(depths, depth)
 		}
-		
-		// Clean up and join
-		docstring := strings.Join(docLines, "\n")
-		return strings.TrimSpace(docstring)
 	}
-	
-	return ""
+	return depths
 }
 
-// extractJSDocComment extracts JSDoc comment from JavaScript/TypeScript code before the given line
+// extractJSDocComment extracts the JSDoc comment preceding the given line.
+// Comment delimiters and leading asterisks are removed; line breaks are kept
+// so tags such as @example retain their layout.
 func extractJSDocComment(lines []string, lineNum int) string {
+	// Skip blank lines and decorators between the comment and the declaration
+	end := lineNum - 1
+	for end >= 0 {
+		line := strings.TrimSpace(lines[end])
+		if line != "" && !strings.HasPrefix(line, "@") {
+			break
+		}
+		end--
+	}
+	if end < 0 || !strings.HasSuffix(strings.TrimSpace(lines[end]), "*/") {
+		return ""
+	}
+
+	start := end
+	for start >= 0 && !strings.Contains(lines[start], "/**") {
+		start--
+	}
+	if start < 0 {
+		return ""
+	}
+
 	var commentLines []string
-	
-	// Look backwards for JSDoc comment
-	for i := lineNum - 1; i >= 0; i-- {
+	for i := start; i <= end; i++ {
 		line := strings.TrimSpace(lines[i])
-		
-		if line == "" {
-			continue
+		if i == start {
+			line = line[strings.Index(line, "/**")+3:]
 		}
-		
-		if strings.HasPrefix(line, "*/") {
-			// Found end of comment block, continue looking backwards
-			continue
-		} else if strings.HasPrefix(line, "*") {
-			// Comment line
-			content := strings.TrimSpace(line[1:])
-			if content != "" {
-				commentLines = append([]string{content}, commentLines...)
-			}
-		} else if strings.HasPrefix(line, "/**") {
-			// Found start of JSDoc comment
-			content := strings.TrimSpace(line[3:])
-			if content != "" && !strings.HasSuffix(content, "*/") {
-				commentLines = append([]string{content}, commentLines...)
-			}
-			break
-		} else {
-			// Not a comment line, stop looking
-			break
+		if i == end {
+			line = strings.TrimSuffix(line, "*/")
+		}
+		if i != start {
+			line = strings.TrimPrefix(line, "*")
+			line = strings.TrimPrefix(line, " ")
 		}
+		commentLines = append(commentLines, strings.TrimRight(line, " \t"))
 	}
-	
-	return strings.Join(commentLines, " ")
+
+	return strings.Trim(strings.Join(commentLines, "\n"), "\n ")
 }
 
-// parsePythonParameters parses Python function parameters and returns parameter schema and required list
-func parsePythonParameters(params string) (map[string]interface{}, []string) {
+// pythonDropFirstParam removes the self or cls parameter of a method
+func pythonDropFirstParam(params string) string {
+	parts := splitPythonTopLevel(params, ',')
+	if len(parts) == 0 || strings.HasPrefix(strings.TrimSpace(parts[0]), "*") {
+		return params
+	}
+	return strings.Join(parts[1:], ",")
+}
+
+// parsePythonParameters parses Python function parameters and returns parameter schema and required list.
+// Type hints are mapped to JSON Schema, resolving annotations that name one of the module's enums.
+// When doc is set, documented descriptions are used and documented types fill in missing hints.
+func parsePythonParameters(params string, enums map[string][]interface{}, doc *docComment) (map[string]interface{}, []string) {
 	if params == "" {
 		return map[string]interface{}{
 			"type":       "object",
 			"properties": map[string]interface{}{},
 		}, []string{}
 	}
-	
+
 	properties := make(map[string]interface{})
 	var required []string
-	
-	// Split parameters by comma (simple approach)
-	paramList := strings.Split(params, ",")
-	
+
+	// Split on top-level commas so defaults like x=(1, 2) and annotations like dict[str, int] stay intact
+	paramList := splitPythonTopLevel(params, ',')
+
 	for _, param := range paramList {
 		param = strings.TrimSpace(param)
-		if param == "" || param == "self" {
+		if param == "" || param == "self" || param == "/" {
 			continue
 		}
-		
-		// Skip *args and **kwargs
+
+		// Skip *args, **kwargs and the bare * keyword-only marker
 		if strings.HasPrefix(param, "*") {
 			continue
 		}
-		
-		// Remove type hints for parsing
-		paramName := param
-		if idx := strings.Index(param, ":"); idx >= 0 {
-			paramName = strings.TrimSpace(param[:idx])
+
+		// Split off the default value and the type hint
+		declaration, defaultValue := param, ""
+		hasDefault := false
+		if idx := pythonScanTo(param, "="); idx >= 0 {
+			declaration, defaultValue = strings.TrimSpace(param[:idx]), strings.TrimSpace(param[idx+1:])
+			hasDefault = true
+		}
+
+		paramName, annotation := declaration, ""
+		if idx := pythonScanTo(declaration, ":"); idx >= 0 {
+			paramName, annotation = strings.TrimSpace(declaration[:idx]), strings.TrimSpace(declaration[idx+1:])
 		}
-		
-		// Check if parameter has default value
-		hasDefault := strings.Contains(param, "=")
+
 		if !hasDefault {
 			// Only add to required if no default value
 			required = append(required, paramName)
 		}
-		
-		if paramName != "" {
-			properties[paramName] = map[string]interface{}{
-				"type":        "string",
-				"description": fmt.Sprintf("Parameter %s", paramName),
+
+		if paramName == "" {
+			continue
+		}
+
+		var paramDoc docParam
+		if doc != nil {
+			paramDoc, _ = doc.Param(paramName)
+		}
+
+		var schema map[string]interface{}
+		value, literal := parsePythonLiteral(defaultValue)
+		switch {
+		case annotation != "":
+			schema = pythonTypeSchema(annotation, enums)
+		case paramDoc.Type != "":
+			schema = pythonTypeSchema(docTypeAnnotation(paramDoc.Type), enums)
+		case literal && value != nil:
+			// Infer the type from the default value when there is no hint
+			schema = map[string]interface{}{"type": jsonSchemaTypeOf(value)}
+		default:
+			schema = map[string]interface{}{"type": "string"}
+		}
+
+		if hasDefault && literal {
+			schema["default"] = value
+			if value == nil {
+				schema["nullable"] = true
 			}
 		}
+		schema["description"] = fmt.Sprintf("Parameter %s", paramName)
+		if paramDoc.Description != "" {
+			schema["description"] = paramDoc.Description
+		}
+		properties[paramName] = schema
 	}
-	
+
 	return map[string]interface{}{
 		"type":       "object",
 		"properties": properties,
 	}, required
 }
 
-// parseJavaScriptParameters parses JavaScript/TypeScript function parameters
-func parseJavaScriptParameters(params string) (map[string]interface{}, []string) {
-	if params == "" {
+// docReturns describes the documented return value, prefixed with its type when known
+func docReturns(doc docComment) string {
+	switch {
+	case doc.ReturnType != "" && doc.Returns != "":
+		return doc.ReturnType + ": " + doc.Returns
+	case doc.ReturnType != "":
+		return doc.ReturnType
+	}
+	return doc.Returns
+}
+
+// docRaises lists the documented exceptions as "Type: condition"
+func docRaises(doc docComment) []string {
+	var raises []string
+	for _, r := range doc.Raises {
+		if r.Description != "" {
+			raises = append(raises, r.Name+": "+r.Description)
+		} else {
+			raises = append(raises, r.Name)
+		}
+	}
+	return raises
+}
+
+// jsParameterList returns the text between the parenthesis at open and its match.
+// Unbalanced lists (parameters continuing on the next line) return what is available.
+func jsParameterList(line string, open int) string {
+	end := jsMatchingBracket(line, open)
+	if end < 0 {
+		return line[open+1:]
+	}
+	return line[open+1 : end]
+}
+
+// jsIsArrowFunction reports whether the parenthesized list at open is followed by =>
+func jsIsArrowFunction(line string, open int) bool {
+	end := jsMatchingBracket(line, open)
+	if end < 0 {
+		return false
+	}
+	rest := line[end+1:]
+	arrow := jsScanTo(rest, "=")
+	return arrow >= 0 && strings.HasPrefix(rest[arrow:], "=>")
+}
+
+// parseJavaScriptParameters parses JavaScript/TypeScript function parameters.
+// TypeScript annotations are mapped to JSON Schema, resolving the interfaces and type aliases in types.
+// When doc is set, @param descriptions are used and @param types fill in missing annotations.
+func parseJavaScriptParameters(params string, types map[string]string, doc *docComment) (map[string]interface{}, []string) {
+	if strings.TrimSpace(params) == "" {
 		return map[string]interface{}{
 			"type":       "object",
 			"properties": map[string]interface{}{},
 		}, []string{}
 	}
-	
+
 	properties := make(map[string]interface{})
 	var required []string
-	
-	// Split parameters by comma (simple approach)
-	paramList := strings.Split(params, ",")
-	
-	for _, param := range paramList {
+
+	// Split on top-level commas so object types, generics and defaults stay intact
+	paramList := splitJSTopLevel(params, ',')
+
+	for index, param := range paramList {
 		param = strings.TrimSpace(param)
 		if param == "" {
 			continue
 		}
-		
-		// Skip rest/spread parameters
-		if strings.HasPrefix(param, "...") {
+
+		// Rest parameters collect the remaining arguments into an array
+		rest := strings.HasPrefix(param, "...")
+		paramName, annotation, defaultValue, hasDefault, isOptional := splitJSParameter(strings.TrimPrefix(param, "..."), index)
+		if rest {
+			isOptional = true
+		}
+
+		if paramName == "" || paramName == "this" {
 			continue
 		}
-		
-		// Remove type annotations for parsing
-		paramName := param
-		if idx := strings.Index(param, ":"); idx >= 0 {
-			paramName = strings.TrimSpace(param[:idx])
-		}
-		
-		// Handle optional parameters (ending with ?)
-		isOptional := strings.HasSuffix(paramName, "?")
-		if isOptional {
-			paramName = strings.TrimSuffix(paramName, "?")
-		}
-		
-		// Check if parameter has default value
-		hasDefault := strings.Contains(param, "=")
-		
+
+		var paramDoc docParam
+		if doc != nil {
+			paramDoc, _ = doc.Param(paramName)
+		}
+
+		// JSDoc marks optional parameters as [name] or {type=}
+		if annotation == "" && strings.HasSuffix(paramDoc.Type, "=") {
+			isOptional = true
+		}
+
 		// Parameter is required if it's not optional and has no default value
 		if !isOptional && !hasDefault {
 			required = append(required, paramName)
 		}
-		
-		if paramName != "" {
-			properties[paramName] = map[string]interface{}{
-				"type":        "string",
-				"description": fmt.Sprintf("Parameter %s", paramName),
-			}
+
+		var schema map[string]interface{}
+		value, literal := parseJSLiteral(defaultValue)
+		switch {
+		case annotation != "":
+			schema = tsTypeSchema(annotation, types, map[string]bool{})
+		case paramDoc.Type != "":
+			schema = jsdocTypeSchema(paramDoc.Type, types)
+		case literal && value != nil:
+			// Infer the type from the default value when there is no annotation
+			schema = map[string]interface{}{"type": jsonSchemaTypeOf(value)}
+		case strings.HasPrefix(defaultValue, "{") || strings.HasPrefix(param, "{"):
+			// Object literals that are not JSON, such as { retries: 3 }, and destructured objects
+			schema = map[string]interface{}{"type": "object"}
+		case strings.HasPrefix(defaultValue, "[") || strings.HasPrefix(param, "[") || rest:
+			schema = map[string]interface{}{"type": "array"}
+		default:
+			schema = map[string]interface{}{"type": "string"}
 		}
+
+		if hasDefault && literal {
+			schema["default"] = value
+		}
+		schema["description"] = fmt.Sprintf("Parameter %s", paramName)
+		if paramDoc.Description != "" {
+			schema["description"] = paramDoc.Description
+		}
+		properties[paramName] = schema
 	}
-	
+
 	return map[string]interface{}{
 		"type":       "object",
 		"properties": properties,
 	}, required
-}
\ No newline at end of file
+}
+
+// splitJSParameter splits a JavaScript/TypeScript parameter into its name,
+// type annotation and default value, and reports whether it is optional.
+// Destructured parameters have no name of their own and are named "options"
+// for objects and argN, after their position, for arrays.
+func splitJSParameter(param string, index int) (name, annotation, defaultValue string, hasDefault, optional bool) {
+	// Split off the default value and the type annotation
+	declaration := param
+	if idx := jsScanTo(param, "="); idx >= 0 && !strings.HasPrefix(param[idx:], "=>") {
+		declaration, defaultValue = strings.TrimSpace(param[:idx]), strings.TrimSpace(param[idx+1:])
+		hasDefault = true
+	}
+
+	name = declaration
+	if idx := jsScanTo(declaration, ":"); idx >= 0 {
+		name, annotation = strings.TrimSpace(declaration[:idx]), strings.TrimSpace(declaration[idx+1:])
+	}
+
+	// Handle optional parameters (ending with ?)
+	optional = strings.HasSuffix(name, "?")
+	if optional {
+		name = strings.TrimSuffix(name, "?")
+	}
+
+	switch {
+	case strings.HasPrefix(name, "{"):
+		name = "options"
+	case strings.HasPrefix(name, "["):
+		name = fmt.Sprintf("arg%d", index)
+	default:
+		// TypeScript parameter properties such as "private readonly name: string"
+		if fields := strings.Fields(name); len(fields) > 1 {
+			name = fields[len(fields)-1]
+		}
+	}
+	return name, annotation, defaultValue, hasDefault, optional
+}
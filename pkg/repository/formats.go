@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolFormat renders function descriptors as the tool objects of one model
+// provider or protocol
+type toolFormat struct {
+	// Name is the value of the emit_tool_json format argument, e.g. "openai"
+	Name string
+	// MaxName and MaxDescription are the longest tool name and description
+	// the provider accepts, in characters; zero means unlimited
+	MaxName        int
+	MaxDescription int
+	// NameRune reports whether a character may appear at position i of a
+	// tool name; names are unrestricted when nil
+	NameRune func(r rune, i int) bool
+	// Render builds the tool object from a descriptor, with its parameters
+	// validated, and its name and description, already cut to the limits above
+	Render func(fn FunctionDescriptor, name, description string) interface{}
+}
+
+// nameRune allows ASCII letters, digits, underscores and the given
+// punctuation in tool names. With letterStart set, names must start with a
+// letter or an underscore.
+func nameRune(punctuation string, letterStart bool) func(r rune, i int) bool {
+	return func(r rune, i int) bool {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			return true
+		case i == 0 && letterStart:
+			return false
+		case r >= '0' && r <= '9':
+			return true
+		}
+		return strings.ContainsRune(punctuation, r)
+	}
+}
+
+// DefaultToolFormat is the format emit_tool_json uses when none is requested
+const DefaultToolFormat = "openai"
+
+// toolFormats are the supported output formats, keyed by name
+var toolFormats = map[string]toolFormat{
+	"openai": {
+		Name:           "openai",
+		MaxName:        64,
+		MaxDescription: 1024,
+		NameRune:       nameRune("-", false),
+		Render: func(fn FunctionDescriptor, name, description string) interface{} {
+			return ToolDefinition{
+				Type: "function",
+				Function: FunctionDef{
+					Name:        name,
+					Description: description,
+					Parameters:  fn.Parameters,
+				},
+			}
+		},
+	},
+	"mcp": {
+		Name:     "mcp",
+		MaxName:  128,
+		NameRune: nameRune("-.", false),
+		Render: func(fn FunctionDescriptor, name, description string) interface{} {
+			return MCPToolDefinition{
+				Name:        name,
+				Description: description,
+				InputSchema: fn.Parameters,
+				Annotations: &mcp.ToolAnnotation{Title: fn.Name},
+			}
+		},
+	},
+	"anthropic": {
+		Name:     "anthropic",
+		MaxName:  64,
+		NameRune: nameRune("-", false),
+		Render: func(fn FunctionDescriptor, name, description string) interface{} {
+			return AnthropicToolDefinition{
+				Name:        name,
+				Description: description,
+				InputSchema: fn.Parameters,
+			}
+		},
+	},
+	"gemini": {
+		Name:     "gemini",
+		MaxName:  64,
+		NameRune: nameRune("-.:", true),
+		Render: func(fn FunctionDescriptor, name, description string) interface{} {
+			return GeminiFunctionDeclaration{
+				Name:        name,
+				Description: description,
+				Parameters:  geminiSchema(fn.Parameters),
+			}
+		},
+	},
+	"jsonschema": {
+		Name: "jsonschema",
+		Render: func(fn FunctionDescriptor, name, description string) interface{} {
+			schema := map[string]interface{}{
+				"$schema":     "https://json-schema.org/draft/2020-12/schema",
+				"title":       name,
+				"description": description,
+			}
+			for key, value := range fn.Parameters {
+				schema[key] = value
+			}
+			return schema
+		},
+	},
+}
+
+// ToolFormats returns the sorted names of the supported output formats
+func ToolFormats() []string {
+	names := make([]string, 0, len(toolFormats))
+	for name := range toolFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupToolFormat returns the output format registered under a name
+func lookupToolFormat(name string) (toolFormat, bool) {
+	format, ok := toolFormats[name]
+	return format, ok
+}
+
+// truncateRunes cuts s to at most limit characters, ending with suffix when
+// it was cut. A limit of zero leaves s unchanged.
+func truncateRunes(s string, limit int, suffix string) string {
+	if limit <= 0 || utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	keep := limit - utf8.RuneCountInString(suffix)
+	for i := range s {
+		if keep == 0 {
+			return s[:i] + suffix
+		}
+		keep--
+	}
+	return s
+}
+
+// geminiSchemaKeys are the schema keywords of the OpenAPI subset accepted in
+// Gemini function declarations
+var geminiSchemaKeys = map[string]bool{
+	"type": true, "format": true, "title": true, "description": true, "nullable": true, "enum": true,
+	"properties": true, "required": true, "items": true, "minItems": true, "maxItems": true,
+	"minimum": true, "maximum": true, "minLength": true, "maxLength": true, "pattern": true, "anyOf": true,
+	"minProperties": true, "maxProperties": true, "propertyOrdering": true,
+}
+
+// geminiSchema copies a JSON Schema, dropping the keywords Gemini rejects,
+// such as default, additionalProperties and prefixItems
+func geminiSchema(schema map[string]interface{}) map[string]interface{} {
+	if schema == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if !geminiSchemaKeys[key] {
+			continue
+		}
+		switch key {
+		case "properties":
+			if props, ok := value.(map[string]interface{}); ok {
+				converted := make(map[string]interface{}, len(props))
+				for name, prop := range props {
+					if propSchema, ok := prop.(map[string]interface{}); ok {
+						converted[name] = geminiSchema(propSchema)
+					} else {
+						converted[name] = prop
+					}
+				}
+				value = converted
+			}
+		case "items":
+			if items, ok := value.(map[string]interface{}); ok {
+				value = geminiSchema(items)
+			}
+		case "anyOf":
+			if variants, ok := value.([]interface{}); ok {
+				converted := make([]interface{}, len(variants))
+				for i, variant := range variants {
+					if variantSchema, ok := variant.(map[string]interface{}); ok {
+						converted[i] = geminiSchema(variantSchema)
+					} else {
+						converted[i] = variant
+					}
+				}
+				value = converted
+			}
+		}
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	assert.Equal(t, "short", truncateRunes("short", 10, "..."))
+	assert.Equal(t, "unlimited", truncateRunes("unlimited", 0, "..."))
+	assert.Equal(t, "abcdefg...", truncateRunes("abcdefghijklmnop", 10, "..."))
+	assert.Equal(t, "héllo", truncateRunes("héllo wörld", 5, ""))
+}
+
+func TestGeminiSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"size":  map[string]interface{}{"type": "integer", "default": int64(3), "description": "Size"},
+			"meta":  map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+			"pairs": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "array", "prefixItems": []interface{}{}}},
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"size":  map[string]interface{}{"type": "integer", "description": "Size"},
+			"meta":  map[string]interface{}{"type": "object"},
+			"pairs": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "array"}},
+		},
+	}, geminiSchema(schema))
+}
+
+func TestHandleEmitToolJSONFormats(t *testing.T) {
+	parameters := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"n": map[string]interface{}{"type": "integer", "default": int64(1)}},
+	}
+	longName := "Service." + strings.Repeat("x", 70)
+	functions := []interface{}{
+		map[string]interface{}{
+			"name":        "Counter.increment",
+			"type":        "method",
+			"class":       "Counter",
+			"description": "Increments the counter.",
+			"parameters":  parameters,
+			"required":    []interface{}{},
+		},
+		map[string]interface{}{
+			"name":        longName,
+			"description": strings.Repeat("d", 1100),
+			"parameters":  parameters,
+			"required":    []interface{}{},
+		},
+	}
+
+	emit := func(format string) []map[string]interface{} {
+		t.Helper()
+		args := map[string]interface{}{"functions": functions}
+		if format != "" {
+			args["format"] = format
+		}
+		result, err := handleEmitToolJSON(context.Background(), createMCPRequest(args))
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+
+		var emitted struct {
+			Tools []map[string]interface{} `json:"tools"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &emitted))
+		require.Len(t, emitted.Tools, 2)
+		return emitted.Tools
+	}
+
+	openai := emit("")
+	assert.Equal(t, emit("openai"), openai)
+	function := openai[0]["function"].(map[string]interface{})
+	assert.Equal(t, "function", openai[0]["type"])
+	assert.Equal(t, "Counter_increment", function["name"])
+	long := openai[1]["function"].(map[string]interface{})
+	assert.Len(t, long["name"], 64)
+	assert.Len(t, long["description"], 1024)
+	assert.True(t, strings.HasSuffix(long["description"].(string), "..."))
+
+	mcpTools := emit("mcp")
+	assert.Equal(t, "Counter_increment", mcpTools[0]["name"])
+	assert.Equal(t, "Increments the counter.", mcpTools[0]["description"])
+	assert.Equal(t, "object", mcpTools[0]["inputSchema"].(map[string]interface{})["type"])
+	assert.Equal(t, map[string]interface{}{"title": "Counter.increment"}, mcpTools[0]["annotations"])
+	assert.Len(t, mcpTools[1]["name"], len("Service_")+70)
+	assert.Len(t, mcpTools[1]["description"], 1100)
+
+	anthropic := emit("anthropic")
+	assert.Equal(t, "Counter_increment", anthropic[0]["name"])
+	assert.Contains(t, anthropic[0], "input_schema")
+	assert.Len(t, anthropic[1]["name"], 64)
+
+	gemini := emit("gemini")
+	assert.Equal(t, map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"n": map[string]interface{}{"type": "integer"}},
+	}, gemini[0]["parameters"])
+
+	schemas := emit("jsonschema")
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", schemas[0]["$schema"])
+	assert.Equal(t, "Counter_increment", schemas[0]["title"])
+	assert.Equal(t, "Increments the counter.", schemas[0]["description"])
+	assert.Equal(t, "object", schemas[0]["type"])
+
+	result, err := handleEmitToolJSON(context.Background(), createMCPRequest(map[string]interface{}{"functions": functions, "format": "xml"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getTextResult(t, result).Text, "unsupported format: xml")
+}
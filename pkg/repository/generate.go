@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultServerDir is the directory, relative to the repository root, a
+// generated server project is written to when none is given
+const DefaultServerDir = "mcp-server"
+
+// GenerateOptions configures the MCP server project Generate scaffolds
+type GenerateOptions struct {
+	Language          string           // target language, "go" or "python"
+	Name              string           // server name; the base name of the repository root when empty
+	OutputDir         string           // where the project will be written, relative to the root unless absolute; DefaultServerDir when empty
+	Files             []FileSignatures // signatures to serve, as returned by extract_repository_signatures; extracted from the repository when nil
+	Include           []string         // globs of files to read when Files is nil
+	Exclude           []string         // globs of files to skip when Files is nil
+	MaxFiles          int              // maximum number of source files to read; defaultRepositoryFiles when zero
+	IncludeDeprecated bool             // also serve symbols marked as deprecated
+}
+
+// GeneratedFile is a file of a generated project
+type GeneratedFile struct {
+	Path    string `json:"path"` // slash-separated, relative to the project directory
+	Content string `json:"content,omitempty"`
+}
+
+// GeneratedServer is a scaffolded MCP server project: its files, the tools
+// it serves with the symbol each one calls, and the symbols left out
+type GeneratedServer struct {
+	Language  string          `json:"language"`
+	Name      string          `json:"name"`
+	OutputDir string          `json:"output_dir"`
+	Files     []GeneratedFile `json:"files"`
+	Tools     []ToolSource    `json:"tools"`
+	Skipped   []SkippedSymbol `json:"skipped"`
+}
+
+// servedSymbol is a symbol a generated tool calls
+type servedSymbol struct {
+	Sig         FunctionSignature
+	Source      ToolSource
+	Description string
+	Schema      map[string]interface{} // validated input schema of the tool
+	Call        interface{}            // how the target calls the symbol, e.g. a goCall
+}
+
+// serverTarget scaffolds server projects in one language
+type serverTarget struct {
+	// Language is the language of the source files the target can serve
+	Language string
+	// Imports are the imports every generated server has, keyed by path,
+	// with the identifier they are referred to by
+	Imports map[string]string
+	// Reserved are the other identifiers of the generated code, which
+	// imports of the served symbols may not be aliased to
+	Reserved []string
+	// Prepare reads a source file and returns the symbols of sigs the
+	// server can call, with the reasons the others cannot be called
+	Prepare func(g *generator, file FileSignatures, sigs []FunctionSignature) ([]servedSymbol, []SkippedSymbol, error)
+	// Render writes the project files for the served symbols
+	Render func(g *generator, symbols []servedSymbol) ([]GeneratedFile, error)
+}
+
+// serverTargets are the supported project languages, keyed by name
+var serverTargets = map[string]serverTarget{
+	"go":     goServerTarget,
+	"python": pythonServerTarget,
+}
+
+// ServerLanguages returns the sorted names of the languages Generate can
+// scaffold a server in
+func ServerLanguages() []string {
+	names := make([]string, 0, len(serverTargets))
+	for name := range serverTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// generator holds the state shared by the files of one generated project
+type generator struct {
+	Repo *RepoFS
+	Name string // server name
+	// RootFromOutput is the slash-separated path of the repository root
+	// relative to the project directory
+	RootFromOutput string
+	// Aliases maps import paths, or Python module names, to the identifier
+	// the generated server refers to them by
+	Aliases map[string]string
+	used    map[string]bool
+}
+
+// alias returns the identifier an import is referred to by, allocating one
+// derived from base, with a numeric suffix when it is taken, on first use
+func (g *generator) alias(importPath, base string) string {
+	if alias, ok := g.Aliases[importPath]; ok {
+		return alias
+	}
+	alias := base
+	for n := 2; g.used[alias]; n++ {
+		alias = fmt.Sprintf("%s%d", base, n)
+	}
+	g.used[alias] = true
+	g.Aliases[importPath] = alias
+	return alias
+}
+
+// Generate scaffolds an MCP server project exposing the public functions of
+// a repository. Each tool of the server decodes its arguments into
+// the types of the original function, calls it and returns its result as
+// JSON. Symbols the target cannot call, such as instance methods, are
+// reported as skipped. The project is returned, not written; see Write.
+func Generate(ctx context.Context, repo *RepoFS, opts GenerateOptions) (*GeneratedServer, error) {
+	target, ok := serverTargets[opts.Language]
+	if !ok {
+		return nil, fmt.Errorf("unsupported server language: %s (supported: %s)", opts.Language, strings.Join(ServerLanguages(), ", "))
+	}
+
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		outputDir = DefaultServerDir
+	}
+	// A repository that is not on disk, such as an archive, is placed at a
+	// virtual root, which is enough to relate it to a relative output directory
+	root := repo.Dir()
+	if root == "" {
+		if filepath.IsAbs(outputDir) {
+			return nil, fmt.Errorf("repository %s is not a local directory, so the output directory must be relative to it", repo.Name())
+		}
+		root = string(filepath.Separator)
+	}
+	absOutput := outputDir
+	if !filepath.IsAbs(absOutput) {
+		absOutput = filepath.Join(root, outputDir)
+	}
+	rootFromOutput, err := filepath.Rel(absOutput, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = repo.Name()
+	}
+
+	files := opts.Files
+	if files == nil {
+		files, err = extractSignatureFiles(ctx, repo, opts.Include, opts.Exclude, opts.MaxFiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	g := &generator{
+		Repo:           repo,
+		Name:           name,
+		RootFromOutput: filepath.ToSlash(rootFromOutput),
+		Aliases:        make(map[string]string),
+		used:           make(map[string]bool),
+	}
+	for importPath, alias := range target.Imports {
+		g.Aliases[importPath] = alias
+		g.used[alias] = true
+	}
+	for _, name := range target.Reserved {
+		g.used[name] = true
+	}
+	result := &GeneratedServer{
+		Language:  opts.Language,
+		Name:      name,
+		OutputDir: filepath.ToSlash(outputDir),
+		Files:     []GeneratedFile{},
+		Tools:     []ToolSource{},
+		Skipped:   []SkippedSymbol{},
+	}
+
+	var symbols []servedSymbol
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		switch {
+		case file.Error != "":
+			result.Skipped = append(result.Skipped, SkippedSymbol{Path: file.Path, Reason: file.Error})
+			continue
+		case file.Language != target.Language:
+			result.Skipped = append(result.Skipped, SkippedSymbol{Path: file.Path, Reason: fmt.Sprintf("%s files cannot be served by a %s server", file.Language, opts.Language)})
+			continue
+		}
+
+		sigs, skipped := callableSignatures(file, opts.IncludeDeprecated)
+		result.Skipped = append(result.Skipped, skipped...)
+		if len(sigs) == 0 {
+			continue
+		}
+
+		served, skipped, err := target.Prepare(g, file, sigs)
+		if err != nil {
+			result.Skipped = append(result.Skipped, SkippedSymbol{Path: file.Path, Reason: err.Error()})
+			continue
+		}
+		symbols = append(symbols, served...)
+		result.Skipped = append(result.Skipped, skipped...)
+	}
+
+	// Tools are named and validated as for emit_tool_json in the MCP format
+	descriptors := make([]FunctionDescriptor, len(symbols))
+	for i, symbol := range symbols {
+		descriptors[i] = signatureDescriptor(symbol.Sig)
+	}
+	tools, warnings, errors := toolFormats["mcp"].emit(descriptors, true)
+	for _, issue := range errors {
+		source := symbols[issue.Index].Source
+		result.Skipped = append(result.Skipped, SkippedSymbol{
+			Symbol: source.Symbol,
+			Path:   source.Path,
+			Line:   source.Line,
+			Reason: fmt.Sprintf("%s: %s", issue.Field, issue.Message),
+		})
+	}
+	for _, issue := range warnings {
+		symbols[issue.Index].Source.Warnings = append(symbols[issue.Index].Source.Warnings, issue.Message)
+	}
+
+	var kept []servedSymbol
+	for _, tool := range tools {
+		symbol := symbols[tool.Index]
+		definition := tool.Tool.(MCPToolDefinition)
+		symbol.Source.Tool = tool.Name
+		symbol.Description = definition.Description
+		symbol.Schema = definition.InputSchema
+		kept = append(kept, symbol)
+		result.Tools = append(result.Tools, symbol.Source)
+	}
+
+	result.Files, err = target.Render(g, kept)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// callableSignatures returns the signatures of a file that can be called,
+// reporting types and, unless includeDeprecated is set, deprecated symbols
+// as skipped
+func callableSignatures(file FileSignatures, includeDeprecated bool) ([]FunctionSignature, []SkippedSymbol) {
+	var sigs []FunctionSignature
+	var skipped []SkippedSymbol
+	for _, sig := range file.Signatures {
+		skip := func(reason string) {
+			skipped = append(skipped, SkippedSymbol{Symbol: sig.Name, Path: file.Path, Line: sig.Line, Reason: reason})
+		}
+		switch {
+		case !callable(sig):
+			skip(fmt.Sprintf("%s is not callable", sig.Type))
+		case sig.Deprecated && !includeDeprecated:
+			skip("deprecated")
+		default:
+			sigs = append(sigs, sig)
+		}
+	}
+	return sigs, skipped
+}
+
+// extractSignatureFiles extracts the signatures of up to maxFiles source
+// files of the repository matching the include and exclude globs
+func extractSignatureFiles(ctx context.Context, repo *RepoFS, include, exclude []string, maxFiles int) ([]FileSignatures, error) {
+	if maxFiles <= 0 {
+		maxFiles = defaultRepositoryFiles
+	}
+	sources, _, err := collectSourceFiles(ctx, repo, include, exclude, maxFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	files := make([]FileSignatures, 0, len(sources))
+	for _, source := range sources {
+		file := FileSignatures{Path: source.Path, Language: source.Language}
+		file.Signatures, err = extractFileSignatures(repo, source.Path, source.Language)
+		if err != nil {
+			file.Error = err.Error()
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// readSource reads a repository file named by a slash-separated path
+// relative to the root, refusing paths that leave the root
+func (g *generator) readSource(relPath string) ([]byte, error) {
+	cleaned, err := cleanRepoPath(relPath)
+	if err != nil || strings.HasPrefix(relPath, "/") || filepath.IsAbs(relPath) {
+		return nil, fmt.Errorf("path is outside the repository: %s", relPath)
+	}
+	content, err := g.Repo.ReadFile(cleaned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return content, nil
+}
+
+// Write writes the project files below dir, creating directories as needed
+func (s *GeneratedServer) Write(dir string) error {
+	for _, file := range s.Files {
+		if err := writeGeneratedFile(filepath.Join(dir, filepath.FromSlash(file.Path)), file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTo writes the project files below dir, a slash-separated directory of
+// a local repository. Each file is resolved within the repository before it
+// is written, so a symlink below dir cannot lead the write out of it.
+func (s *GeneratedServer) writeTo(repo *RepoFS, dir string) error {
+	for _, file := range s.Files {
+		local, err := repo.localPath(path.Join(dir, file.Path))
+		if err != nil {
+			return fmt.Errorf("cannot write %s: %w", file.Path, err)
+		}
+		if err := writeGeneratedFile(local, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGeneratedFile(local string, file GeneratedFile) error {
+	if err := os.MkdirAll(filepath.Dir(local), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(local, []byte(file.Content), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.Path, err)
+	}
+	return nil
+}
+
+// projectName turns a server name into a package or module name, replacing
+// characters other than letters, digits, "-", "_" and "." with "-"
+func projectName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	if project := strings.Trim(b.String(), "-."); project != "" {
+		return project
+	}
+	return "mcp-server"
+}
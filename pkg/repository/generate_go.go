@@ -0,0 +1,543 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// mcpGoModule and mcpGoVersion are the MCP library generated Go servers use
+const (
+	mcpGoModule  = "github.com/mark3labs/mcp-go"
+	mcpGoVersion = "v0.36.0"
+)
+
+// goCall describes how a generated Go handler calls a function
+type goCall struct {
+	Imports map[string]string // import path to alias, for the package and the parameter types
+	Func    string            // qualified function, e.g. "shop.Place"
+	Params  []goParam
+	Results int  // number of results, not counting a trailing error
+	Error   bool // the last result is an error
+}
+
+// goParam is a parameter of a called Go function
+type goParam struct {
+	Name     string // JSON argument name; empty when the argument is not decoded
+	Type     string // type as written in the generated server
+	Context  bool   // context.Context, passed the request context
+	Variadic bool
+}
+
+// goPredeclared are the predeclared Go types, which are never qualified
+var goPredeclared = map[string]bool{
+	"any": true, "bool": true, "byte": true, "complex64": true, "complex128": true, "error": true,
+	"float32": true, "float64": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"rune": true, "string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"uintptr": true,
+}
+
+// goServerImports are the imports of every generated Go server
+var goServerImports = map[string]string{
+	"context":               "context",
+	"encoding/json":         "json",
+	"fmt":                   "fmt",
+	"os":                    "os",
+	mcpGoModule + "/mcp":    "mcp",
+	mcpGoModule + "/server": "server",
+}
+
+var goServerTarget = serverTarget{
+	Language: "go",
+	Imports:  goServerImports,
+	Reserved: []string{"main", "s", "ctx", "req", "args", "err", "jsonResult"},
+	Prepare:  prepareGoFile,
+	Render:   renderGoServer,
+}
+
+// prepareGoFile finds the declarations of the signatures in a Go file and
+// works out how to call them from outside the package
+func prepareGoFile(g *generator, file FileSignatures, sigs []FunctionSignature) ([]servedSymbol, []SkippedSymbol, error) {
+	dir := path.Dir(file.Path)
+	switch {
+	case strings.HasSuffix(file.Path, "_test.go"):
+		return nil, nil, fmt.Errorf("test files cannot be imported")
+	case dir == "internal" || strings.HasPrefix(dir, "internal/") || strings.Contains(dir, "/internal/") || strings.HasSuffix(dir, "/internal"):
+		return nil, nil, fmt.Errorf("internal packages cannot be imported by the generated server")
+	}
+
+	module, _, err := readGoModule(g.Repo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := g.readSource(file.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, file.Path, content, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Go source: %w", err)
+	}
+	if parsed.Name.Name == "main" {
+		return nil, nil, fmt.Errorf("package main cannot be imported")
+	}
+
+	importPath := module
+	if dir != "." {
+		importPath = module + "/" + dir
+	}
+
+	decls := make(map[string]*ast.FuncDecl)
+	for _, decl := range parsed.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+			decls[fn.Name.Name] = fn
+		}
+	}
+
+	var served []servedSymbol
+	var skipped []SkippedSymbol
+	for _, sig := range sigs {
+		skip := func(reason string) {
+			skipped = append(skipped, SkippedSymbol{Symbol: sig.Name, Path: file.Path, Line: sig.Line, Reason: reason})
+		}
+		if sig.Type == "method" {
+			skip("methods need a receiver")
+			continue
+		}
+		decl, ok := decls[sig.Name]
+		if !ok {
+			skip("declaration not found")
+			continue
+		}
+		if decl.Type.TypeParams != nil {
+			skip("generic functions need type arguments")
+			continue
+		}
+
+		call, err := goFuncCall(g, parsed, decl, importPath)
+		if err != nil {
+			skip(err.Error())
+			continue
+		}
+		served = append(served, servedSymbol{
+			Sig:    sig,
+			Source: ToolSource{Symbol: sig.Name, Path: file.Path, Line: sig.Line, Language: file.Language},
+			Call:   call,
+		})
+	}
+	return served, skipped, nil
+}
+
+// goFuncCall works out the arguments and results of a function declared in
+// the package at importPath
+func goFuncCall(g *generator, file *ast.File, decl *ast.FuncDecl, importPath string) (goCall, error) {
+	call := goCall{Imports: make(map[string]string)}
+	pkgAlias := g.alias(importPath, file.Name.Name)
+	call.Imports[importPath] = pkgAlias
+	call.Func = pkgAlias + "." + decl.Name.Name
+
+	index := 0
+	for _, field := range decl.Type.Params.List {
+		names := make([]string, 0, len(field.Names))
+		for _, ident := range field.Names {
+			names = append(names, ident.Name)
+		}
+		if len(names) == 0 {
+			// Unnamed parameters are named as by the signature extractor
+			names = append(names, fmt.Sprintf("arg%d", index))
+		}
+		index += len(names)
+
+		if goIsContext(field.Type) {
+			for range names {
+				call.Params = append(call.Params, goParam{Context: true})
+			}
+			continue
+		}
+
+		typ := field.Type
+		variadic := false
+		if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+			typ = &ast.ArrayType{Elt: ellipsis.Elt}
+			variadic = true
+		}
+		typeString, err := goQualifiedType(g, file, typ, pkgAlias, call.Imports)
+		if err != nil {
+			return goCall{}, err
+		}
+		for _, name := range names {
+			if name == "_" {
+				name = ""
+			}
+			call.Params = append(call.Params, goParam{Name: name, Type: typeString, Variadic: variadic})
+		}
+	}
+
+	if decl.Type.Results != nil {
+		for _, field := range decl.Type.Results.List {
+			call.Results += max(len(field.Names), 1)
+		}
+		last := decl.Type.Results.List[len(decl.Type.Results.List)-1].Type
+		if ident, ok := last.(*ast.Ident); ok && ident.Name == "error" {
+			call.Results--
+			call.Error = true
+		}
+	}
+	return call, nil
+}
+
+// goQualifiedType writes a parameter type as seen from outside its package:
+// package-level types are qualified with the package alias, and types of
+// other packages use aliases added to imports. Types JSON cannot be decoded
+// into, such as channels and functions, are rejected.
+func goQualifiedType(g *generator, file *ast.File, expr ast.Expr, pkgAlias string, imports map[string]string) (string, error) {
+	qualify := func(expr ast.Expr) (string, error) {
+		return goQualifiedType(g, file, expr, pkgAlias, imports)
+	}
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch {
+		case goPredeclared[t.Name]:
+			return t.Name, nil
+		case !ast.IsExported(t.Name):
+			return "", fmt.Errorf("parameter type %s is unexported", t.Name)
+		}
+		return pkgAlias + "." + t.Name, nil
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return "", fmt.Errorf("unsupported parameter type")
+		}
+		importPath, ok := goImportPath(file, pkg.Name)
+		if !ok {
+			return "", fmt.Errorf("import of %s not found", pkg.Name)
+		}
+		alias := g.alias(importPath, pkg.Name)
+		imports[importPath] = alias
+		return alias + "." + t.Sel.Name, nil
+	case *ast.StarExpr:
+		elem, err := qualify(t.X)
+		return "*" + elem, err
+	case *ast.ArrayType:
+		elem, err := qualify(t.Elt)
+		if err != nil || t.Len == nil {
+			return "[]" + elem, err
+		}
+		length, ok := t.Len.(*ast.BasicLit)
+		if !ok {
+			return "", fmt.Errorf("array lengths must be literals")
+		}
+		return "[" + length.Value + "]" + elem, nil
+	case *ast.MapType:
+		key, err := qualify(t.Key)
+		if err != nil {
+			return "", err
+		}
+		value, err := qualify(t.Value)
+		return "map[" + key + "]" + value, err
+	case *ast.InterfaceType:
+		if t.Methods == nil || len(t.Methods.List) == 0 {
+			return "interface{}", nil
+		}
+		return "", fmt.Errorf("interface parameters cannot be decoded from JSON")
+	case *ast.ChanType:
+		return "", fmt.Errorf("channel parameters cannot be decoded from JSON")
+	case *ast.FuncType:
+		return "", fmt.Errorf("function parameters cannot be decoded from JSON")
+	}
+	return "", fmt.Errorf("unsupported parameter type")
+}
+
+// goImportPath returns the path of the package a file refers to by name
+func goImportPath(file *ast.File, name string) (string, bool) {
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return importPath, true
+			}
+			continue
+		}
+		if goPackageName(importPath) == name {
+			return importPath, true
+		}
+	}
+	return "", false
+}
+
+// goPackageName guesses the name of a package from its import path: the last
+// element, without a major version suffix such as "/v2" or ".v3" and without
+// a "go-" prefix or "-go" suffix
+func goPackageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	if base, version, ok := strings.Cut(name, ".v"); ok && strings.Trim(version, "0123456789") == "" {
+		name = base
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "go-"), "-go")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// readGoModule reads the module path and Go version from the go.mod file at
+// the repository root
+func readGoModule(repo *RepoFS) (module, goVersion string, err error) {
+	f, err := repo.Open("go.mod")
+	if err != nil {
+		return "", "", fmt.Errorf("no go.mod at the repository root, so its packages cannot be imported")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			module = strings.Trim(fields[1], `"`)
+		case "go":
+			goVersion = fields[1]
+		}
+	}
+	if module == "" {
+		return "", "", fmt.Errorf("go.mod does not declare a module path")
+	}
+	return module, goVersion, scanner.Err()
+}
+
+// renderGoServer writes a Go module with a main package serving the symbols
+// over stdio with mcp-go
+func renderGoServer(g *generator, symbols []servedSymbol) ([]GeneratedFile, error) {
+	module, goVersion, err := readGoModule(g.Repo)
+	if err != nil && len(symbols) > 0 {
+		return nil, err
+	}
+	if goVersion == "" {
+		goVersion = "1.23"
+	}
+	name := projectName(g.Name)
+
+	imports := make(map[string]string)
+	for importPath, alias := range goServerImports {
+		imports[importPath] = alias
+	}
+	handlers := make([]string, len(symbols))
+	usedHandlers := make(map[string]bool)
+	for i, symbol := range symbols {
+		for importPath, alias := range symbol.Call.(goCall).Imports {
+			imports[importPath] = alias
+		}
+		handler := "handle" + goIdentifier(symbol.Source.Tool)
+		for n := 2; usedHandlers[handler]; n++ {
+			handler = fmt.Sprintf("handle%s%d", goIdentifier(symbol.Source.Tool), n)
+		}
+		usedHandlers[handler] = true
+		handlers[i] = handler
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Command %s is an MCP server, scaffolded by mcp-prime, whose tools call\n", name)
+	fmt.Fprintf(&b, "// the functions of %s.\n", module)
+	b.WriteString("package main\n\nimport (\n")
+	// Standard library imports come first, as goimports groups them
+	var std, others []string
+	for importPath := range imports {
+		if first, _, _ := strings.Cut(importPath, "/"); strings.Contains(first, ".") {
+			others = append(others, importPath)
+		} else {
+			std = append(std, importPath)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+	for i, group := range [][]string{std, others} {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, importPath := range group {
+			if alias := imports[importPath]; alias != goPackageName(importPath) {
+				fmt.Fprintf(&b, "\t%s %q\n", alias, importPath)
+				continue
+			}
+			fmt.Fprintf(&b, "\t%q\n", importPath)
+		}
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("func main() {\n")
+	fmt.Fprintf(&b, "\ts := server.NewMCPServer(%q, \"0.1.0\", server.WithToolCapabilities(false))\n", g.Name)
+	for i, symbol := range symbols {
+		schema, err := json.Marshal(symbol.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema of %s: %w", symbol.Source.Tool, err)
+		}
+		literal := strconv.Quote(string(schema))
+		if !bytes.ContainsRune(schema, '`') {
+			literal = "`" + string(schema) + "`"
+		}
+		fmt.Fprintf(&b, "\ts.AddTool(mcp.NewToolWithRawSchema(%q, %q, json.RawMessage(%s)), %s)\n",
+			symbol.Source.Tool, symbol.Description, literal, handlers[i])
+	}
+	b.WriteString("\n\tif err := server.ServeStdio(s); err != nil {\n\t\tfmt.Fprintln(os.Stderr, err)\n\t\tos.Exit(1)\n\t}\n}\n")
+
+	for i, symbol := range symbols {
+		writeGoHandler(&b, handlers[i], symbol)
+	}
+
+	b.WriteString(`
+// jsonResult returns a function result as JSON text
+func jsonResult(v any) (*mcp.CallToolResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+`)
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated server: %w", err)
+	}
+
+	var mod strings.Builder
+	fmt.Fprintf(&mod, "module %s\n\ngo %s\n\nrequire (\n", name, goVersion)
+	requires := []string{mcpGoModule + " " + mcpGoVersion}
+	if module != "" {
+		requires = append(requires, module+" v0.0.0-00010101000000-000000000000")
+	}
+	sort.Strings(requires)
+	for _, require := range requires {
+		fmt.Fprintf(&mod, "\t%s\n", require)
+	}
+	mod.WriteString(")\n")
+	if module != "" {
+		fmt.Fprintf(&mod, "\nreplace %s => %s\n", module, g.RootFromOutput)
+	}
+
+	readme := fmt.Sprintf(`# %s
+
+MCP server scaffolded by mcp-prime. Each tool decodes its arguments into the
+parameter types of a function of %s, calls it and returns its results as JSON.
+
+The module points at the repository with a replace directive. Resolve the
+dependencies and run the server over stdio with:
+
+    go mod tidy
+    go run .
+`, g.Name, module)
+
+	return []GeneratedFile{
+		{Path: "go.mod", Content: mod.String()},
+		{Path: "main.go", Content: string(source)},
+		{Path: "README.md", Content: readme},
+	}, nil
+}
+
+// writeGoHandler writes the tool handler calling one function
+func writeGoHandler(b *bytes.Buffer, handler string, symbol servedSymbol) {
+	call := symbol.Call.(goCall)
+
+	fmt.Fprintf(b, "\n// %s calls %s\n", handler, call.Func)
+	fmt.Fprintf(b, "func %s(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {\n", handler)
+
+	fields := make(map[string]bool)
+	var arguments []string
+	var decoded bytes.Buffer
+	for _, param := range call.Params {
+		switch {
+		case param.Context:
+			arguments = append(arguments, "ctx")
+			continue
+		case param.Name == "":
+			arguments = append(arguments, fmt.Sprintf("*new(%s)", param.Type))
+			continue
+		}
+		field := goIdentifier(param.Name)
+		for n := 2; fields[field]; n++ {
+			field = fmt.Sprintf("%s%d", goIdentifier(param.Name), n)
+		}
+		fields[field] = true
+		fmt.Fprintf(&decoded, "\t\t%s %s `json:%q`\n", field, param.Type, param.Name)
+
+		argument := "args." + field
+		if param.Variadic {
+			argument += "..."
+		}
+		arguments = append(arguments, argument)
+	}
+	if decoded.Len() > 0 {
+		fmt.Fprintf(b, "\tvar args struct {\n%s\t}\n", decoded.String())
+		b.WriteString("\tif err := req.BindArguments(&args); err != nil {\n")
+		b.WriteString("\t\treturn mcp.NewToolResultError(fmt.Sprintf(\"invalid arguments: %v\", err)), nil\n\t}\n")
+	}
+
+	results := make([]string, call.Results)
+	for i := range results {
+		results[i] = fmt.Sprintf("r%d", i)
+	}
+	invocation := fmt.Sprintf("%s(%s)", call.Func, strings.Join(arguments, ", "))
+	errorCheck := "err != nil {\n\t\treturn mcp.NewToolResultError(err.Error()), nil\n\t}\n"
+	switch {
+	case call.Error && len(results) == 0:
+		fmt.Fprintf(b, "\tif err := %s; %s", invocation, errorCheck)
+	case call.Error:
+		fmt.Fprintf(b, "\t%s, err := %s\n\tif %s", strings.Join(results, ", "), invocation, errorCheck)
+	case len(results) > 0:
+		fmt.Fprintf(b, "\t%s := %s\n", strings.Join(results, ", "), invocation)
+	default:
+		fmt.Fprintf(b, "\t%s\n", invocation)
+	}
+
+	switch len(results) {
+	case 0:
+		b.WriteString("\treturn jsonResult(nil)\n}\n")
+	case 1:
+		b.WriteString("\treturn jsonResult(r0)\n}\n")
+	default:
+		fmt.Fprintf(b, "\treturn jsonResult([]any{%s})\n}\n", strings.Join(results, ", "))
+	}
+}
+
+// goIdentifier turns a name into an exported Go identifier, dropping
+// characters identifiers cannot hold and capitalizing the word after each
+func goIdentifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	identifier := b.String()
+	if identifier == "" || unicode.IsDigit([]rune(identifier)[0]) {
+		identifier = "X" + identifier
+	}
+	return identifier
+}
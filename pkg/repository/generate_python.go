@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// pythonCall describes how a generated Python tool calls a function
+type pythonCall struct {
+	Module string // dotted module name, e.g. "shop.cart"
+	Alias  string // name the module is imported as
+	Target string // attribute of the module, e.g. "checkout" or "Cart.parse"
+	Async  bool
+	Params []pythonParam
+	Src    bool // the module is below a top-level src directory
+}
+
+// pythonParam is a parameter of a called Python function
+type pythonParam struct {
+	Name           string
+	PositionalOnly bool // declared before "/", so it cannot be passed by keyword
+}
+
+var pythonServerTarget = serverTarget{
+	Language: "python",
+	Imports: map[string]string{
+		"importlib": "importlib",
+		"sys":       "sys",
+	},
+	Reserved: []string{"mcp", "Path", "Annotated", "Any", "Literal", "FastMCP", "Field", "REPOSITORY"},
+	Prepare:  preparePythonFile,
+	Render:   renderPythonServer,
+}
+
+// preparePythonFile finds the definitions of the signatures in a Python
+// module. Functions and static and class methods are served; instance
+// methods and constructors are skipped as they need or return an object.
+func preparePythonFile(g *generator, file FileSignatures, sigs []FunctionSignature) ([]servedSymbol, []SkippedSymbol, error) {
+	module, src, err := pythonModuleName(file.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := g.readSource(file.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defs := make(map[string]pythonDef)
+	for _, def := range parsePythonDefinitions(string(content)) {
+		if def.Kind == "def" && !def.Nested {
+			defs[def.Name] = def
+		}
+	}
+
+	var served []servedSymbol
+	var skipped []SkippedSymbol
+	for _, sig := range sigs {
+		skip := func(reason string) {
+			skipped = append(skipped, SkippedSymbol{Symbol: sig.Name, Path: file.Path, Line: sig.Line, Reason: reason})
+		}
+		switch {
+		case sig.Type == "constructor":
+			skip("constructors return objects that cannot be returned as JSON")
+			continue
+		case sig.Type == "method" && !sig.Static:
+			skip("instance methods need an object to call")
+			continue
+		}
+		def, ok := defs[sig.Name]
+		if !ok {
+			skip("definition not found")
+			continue
+		}
+
+		params := def.Params
+		for _, decorator := range def.Decorators {
+			if decorator == "classmethod" {
+				params = pythonDropFirstParam(params)
+			}
+		}
+
+		alias := g.alias(module, "_"+strings.NewReplacer(".", "_", "-", "_").Replace(module))
+		served = append(served, servedSymbol{
+			Sig:    sig,
+			Source: ToolSource{Symbol: sig.Name, Path: file.Path, Line: sig.Line, Language: file.Language},
+			Call: pythonCall{
+				Module: module,
+				Alias:  alias,
+				Target: sig.Name,
+				Async:  def.Async,
+				Params: pythonCallParams(params),
+				Src:    src,
+			},
+		})
+	}
+	return served, skipped, nil
+}
+
+// pythonModuleName returns the dotted name a Python file is imported by,
+// relative to the repository root or, for files below a top-level src
+// directory, to that directory
+func pythonModuleName(relPath string) (string, bool, error) {
+	if path.Ext(relPath) != ".py" {
+		return "", false, fmt.Errorf("only .py files can be imported")
+	}
+	elems := strings.Split(strings.TrimSuffix(relPath, ".py"), "/")
+	src := len(elems) > 1 && elems[0] == "src"
+	if src {
+		elems = elems[1:]
+	}
+	if len(elems) > 1 && elems[len(elems)-1] == "__init__" {
+		elems = elems[:len(elems)-1]
+	}
+	for _, elem := range elems {
+		if elem == "" || strings.Contains(elem, ".") {
+			return "", false, fmt.Errorf("%s is not an importable module path", relPath)
+		}
+	}
+	return strings.Join(elems, "."), src, nil
+}
+
+// pythonCallParams lists the named parameters of a parameter list, marking
+// those that must be passed by position. *args and **kwargs are left out.
+func pythonCallParams(params string) []pythonParam {
+	var out []pythonParam
+	for _, param := range splitPythonTopLevel(params, ',') {
+		param = strings.TrimSpace(param)
+		switch {
+		case param == "":
+			continue
+		case param == "/":
+			for i := range out {
+				out[i].PositionalOnly = true
+			}
+			continue
+		case strings.HasPrefix(param, "*"):
+			continue
+		}
+		name := param
+		if idx := pythonScanTo(name, "="); idx >= 0 {
+			name = name[:idx]
+		}
+		if idx := pythonScanTo(name, ":"); idx >= 0 {
+			name = name[:idx]
+		}
+		out = append(out, pythonParam{Name: strings.TrimSpace(name)})
+	}
+	return out
+}
+
+// renderPythonServer writes a Python project with a FastMCP server serving
+// the symbols over stdio
+func renderPythonServer(g *generator, symbols []servedSymbol) ([]GeneratedFile, error) {
+	name := projectName(g.Name)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\"\"\"MCP server %s, scaffolded by mcp-prime.\n\n", name)
+	b.WriteString("Each tool calls a function of the repository and returns its result.\n\"\"\"\n\n")
+	b.WriteString("import importlib\nimport sys\nfrom pathlib import Path\nfrom typing import Annotated, Any, Literal\n\n")
+	b.WriteString("from mcp.server.fastmcp import FastMCP\nfrom pydantic import Field\n\n")
+	fmt.Fprintf(&b, "REPOSITORY = (Path(__file__).parent / %s).resolve()\n", pythonString(g.RootFromOutput))
+
+	src := false
+	for _, symbol := range symbols {
+		src = src || symbol.Call.(pythonCall).Src
+	}
+	if src {
+		b.WriteString("sys.path.insert(0, str(REPOSITORY / \"src\"))\n")
+	}
+	b.WriteString("sys.path.insert(0, str(REPOSITORY))\n\n")
+
+	imported := make(map[string]bool)
+	for _, symbol := range symbols {
+		call := symbol.Call.(pythonCall)
+		if imported[call.Module] {
+			continue
+		}
+		imported[call.Module] = true
+		fmt.Fprintf(&b, "%s = importlib.import_module(%s)\n", call.Alias, pythonString(call.Module))
+	}
+	if len(imported) > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "mcp = FastMCP(%s)\n", pythonString(g.Name))
+
+	for _, symbol := range symbols {
+		writePythonTool(&b, symbol)
+	}
+	b.WriteString("\n\nif __name__ == \"__main__\":\n    mcp.run()\n")
+
+	pyproject := fmt.Sprintf(`[project]
+name = %s
+version = "0.1.0"
+description = "MCP server scaffolded by mcp-prime"
+requires-python = ">=3.10"
+dependencies = ["mcp[cli]>=1.2.0"]
+`, pythonString(name))
+
+	readme := fmt.Sprintf(`# %s
+
+MCP server scaffolded by mcp-prime. Each tool validates its arguments against
+the parameter types of a function of the repository, imports its module,
+calls it and returns the result.
+
+The server finds the repository at %s, relative to server.py. Install the
+dependencies of the repository and the MCP SDK, then run it over stdio with:
+
+    pip install "mcp[cli]"
+    python server.py
+`, g.Name, g.RootFromOutput)
+
+	return []GeneratedFile{
+		{Path: "pyproject.toml", Content: pyproject},
+		{Path: "server.py", Content: b.String()},
+		{Path: "README.md", Content: readme},
+	}, nil
+}
+
+// writePythonTool writes the tool function calling one Python function.
+// Arguments are typed from the tool schema so FastMCP validates and converts
+// them; optional arguments without a literal default are only passed when
+// given, leaving the function's own default in place.
+func writePythonTool(b *strings.Builder, symbol servedSymbol) {
+	call := symbol.Call.(pythonCall)
+	properties, _ := symbol.Schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	if list, ok := symbol.Schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	type argument struct {
+		Param       pythonParam
+		Declaration string
+		Omittable   bool // passed only when not None
+	}
+	var arguments []argument
+	var mandatory, optional []string
+	for _, param := range call.Params {
+		schema, ok := properties[param.Name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		annotation := pythonAnnotation(schema)
+		field := "Field()"
+		if description, ok := schema["description"].(string); ok && description != "" {
+			field = fmt.Sprintf("Field(description=%s)", pythonString(description))
+		}
+
+		arg := argument{Param: param}
+		switch value, hasDefault := schema["default"]; {
+		case required[param.Name]:
+			arg.Declaration = fmt.Sprintf("%s: Annotated[%s, %s]", param.Name, annotation, field)
+			mandatory = append(mandatory, arg.Declaration)
+		case hasDefault && pythonLiteral(value) != "" && value != nil:
+			arg.Declaration = fmt.Sprintf("%s: Annotated[%s, %s] = %s", param.Name, annotation, field, pythonLiteral(value))
+			optional = append(optional, arg.Declaration)
+		default:
+			if annotation != "Any" && !strings.HasSuffix(annotation, " | None") {
+				annotation += " | None"
+			}
+			arg.Declaration = fmt.Sprintf("%s: Annotated[%s, %s] = None", param.Name, annotation, field)
+			arg.Omittable = true
+			optional = append(optional, arg.Declaration)
+		}
+		arguments = append(arguments, arg)
+	}
+
+	def := "def"
+	await := ""
+	if call.Async {
+		def, await = "async def", "await "
+	}
+	fmt.Fprintf(b, "\n\n@mcp.tool(name=%s, description=%s)\n", pythonString(symbol.Source.Tool), pythonString(symbol.Description))
+	fmt.Fprintf(b, "%s %s(", def, pythonIdentifier("tool_"+symbol.Source.Tool))
+	if declarations := append(mandatory, optional...); len(declarations) > 0 {
+		b.WriteString("\n")
+		for _, declaration := range declarations {
+			fmt.Fprintf(b, "    %s,\n", declaration)
+		}
+	}
+	b.WriteString(") -> Any:\n")
+
+	target := call.Alias + "." + call.Target
+	if len(arguments) == 0 {
+		fmt.Fprintf(b, "    return %s%s()\n", await, target)
+		return
+	}
+
+	var positional, keyword []string
+	var omittable []argument
+	for _, arg := range arguments {
+		switch {
+		case arg.Omittable:
+			omittable = append(omittable, arg)
+		case arg.Param.PositionalOnly:
+			positional = append(positional, arg.Param.Name)
+		default:
+			keyword = append(keyword, fmt.Sprintf("%s: %s", pythonString(arg.Param.Name), arg.Param.Name))
+		}
+	}
+	fmt.Fprintf(b, "    call_args: list[Any] = [%s]\n", strings.Join(positional, ", "))
+	fmt.Fprintf(b, "    call_kwargs: dict[str, Any] = {%s}\n", strings.Join(keyword, ", "))
+	for _, arg := range omittable {
+		fmt.Fprintf(b, "    if %s is not None:\n", arg.Param.Name)
+		if arg.Param.PositionalOnly {
+			fmt.Fprintf(b, "        call_args.append(%s)\n", arg.Param.Name)
+			continue
+		}
+		fmt.Fprintf(b, "        call_kwargs[%s] = %s\n", pythonString(arg.Param.Name), arg.Param.Name)
+	}
+	fmt.Fprintf(b, "    return %s%s(*call_args, **call_kwargs)\n", await, target)
+}
+
+// pythonAnnotation returns the Python type a JSON Schema describes
+func pythonAnnotation(schema map[string]interface{}) string {
+	if values, ok := schema["enum"].([]interface{}); ok && len(values) > 0 {
+		literals := make([]string, 0, len(values))
+		for _, value := range values {
+			literal := pythonLiteral(value)
+			if literal == "" {
+				literals = nil
+				break
+			}
+			literals = append(literals, literal)
+		}
+		if literals != nil {
+			return "Literal[" + strings.Join(literals, ", ") + "]"
+		}
+	}
+
+	types, ok := schema["type"].([]interface{})
+	if !ok {
+		types = []interface{}{schema["type"]}
+	}
+	var annotations []string
+	nullable := schema["nullable"] == true
+	for _, typ := range types {
+		switch typ {
+		case "string":
+			annotations = append(annotations, "str")
+		case "integer":
+			annotations = append(annotations, "int")
+		case "number":
+			annotations = append(annotations, "float")
+		case "boolean":
+			annotations = append(annotations, "bool")
+		case "array":
+			item := "Any"
+			if items, ok := schema["items"].(map[string]interface{}); ok {
+				item = pythonAnnotation(items)
+			}
+			annotations = append(annotations, "list["+item+"]")
+		case "object":
+			annotations = append(annotations, "dict[str, Any]")
+		case "null":
+			nullable = true
+		default:
+			return "Any"
+		}
+	}
+	if len(annotations) == 0 {
+		return "Any"
+	}
+	if nullable {
+		annotations = append(annotations, "None")
+	}
+	return strings.Join(annotations, " | ")
+}
+
+// pythonLiteral writes a scalar JSON value as a Python literal, or returns
+// an empty string for arrays and objects
+func pythonLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case string:
+		return pythonString(v)
+	}
+	return ""
+}
+
+// pythonString writes a Python string literal
+func pythonString(s string) string {
+	// Go's escapes are a subset of Python's
+	return strconv.Quote(s)
+}
+
+// pythonIdentifier replaces the characters Python identifiers cannot hold
+// with underscores
+func pythonIdentifier(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generatedFile returns the content of a file of a generated project
+func generatedFile(t *testing.T, project *GeneratedServer, path string) string {
+	t.Helper()
+	for _, file := range project.Files {
+		if file.Path == path {
+			return file.Content
+		}
+	}
+	require.Failf(t, "file not generated", "%s", path)
+	return ""
+}
+
+// skipReasons indexes the skipped symbols, or files, of a project by name
+func skipReasons(project *GeneratedServer) map[string]string {
+	reasons := make(map[string]string)
+	for _, skipped := range project.Skipped {
+		name := skipped.Symbol
+		if name == "" {
+			name = skipped.Path
+		}
+		reasons[name] = skipped.Reason
+	}
+	return reasons
+}
+
+func TestGenerateGo(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"orders/orders.go": `package orders
+
+import (
+	"context"
+	"time"
+)
+
+// Order is an order.
+type Order struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// Place places an order.
+func Place(ctx context.Context, order Order, delay time.Duration) (string, error) {
+	return order.ID, nil
+}
+
+// Sum adds numbers.
+func Sum(xs ...int) int { return 0 }
+
+// Check validates.
+func Check(_ int, strict bool) error { return nil }
+
+// Total of an order.
+func (o Order) Total() int { return 0 }
+
+// Watch takes a channel.
+func Watch(c chan int) {}
+
+// Map is generic.
+func Map[T any](xs []T) []T { return xs }
+
+type key struct{}
+
+// Lookup takes an unexported type.
+func Lookup(k key) {}
+`,
+		"internal/store/store.go": "package store\n\n// Open opens.\nfunc Open() {}\n",
+		"cmd/shop/main.go":        "package main\n\n// Run runs.\nfunc Run() {}\n\nfunc main() {}\n",
+		"web/api.py":              "def handler(x: int):\n    pass\n",
+	})
+
+	project, err := Generate(context.Background(), openRepo(t, root), GenerateOptions{Language: "go", Name: "shop-server"})
+	require.NoError(t, err)
+
+	var tools []string
+	for _, tool := range project.Tools {
+		tools = append(tools, tool.Tool)
+	}
+	assert.Equal(t, []string{"Place", "Sum", "Check"}, tools)
+	assert.Equal(t, "mcp-server", project.OutputDir)
+
+	reasons := skipReasons(project)
+	assert.Equal(t, "methods need a receiver", reasons["Order.Total"])
+	assert.Equal(t, "channel parameters cannot be decoded from JSON", reasons["Watch"])
+	assert.Equal(t, "generic functions need type arguments", reasons["Map"])
+	assert.Equal(t, "parameter type key is unexported", reasons["Lookup"])
+	assert.Equal(t, "struct is not callable", reasons["Order"])
+	assert.Equal(t, "internal packages cannot be imported by the generated server", reasons["internal/store/store.go"])
+	assert.Equal(t, "package main cannot be imported", reasons["cmd/shop/main.go"])
+	assert.Equal(t, "python files cannot be served by a go server", reasons["web/api.py"])
+
+	mod := generatedFile(t, project, "go.mod")
+	assert.Contains(t, mod, "module shop-server\n\ngo 1.22\n")
+	assert.Contains(t, mod, "\tgithub.com/mark3labs/mcp-go v0.36.0\n")
+	assert.Contains(t, mod, "replace example.com/shop => ..\n")
+
+	main := generatedFile(t, project, "main.go")
+	assert.Contains(t, main, "\t\"time\"\n\n\t\"example.com/shop/orders\"\n")
+	assert.Contains(t, main, `s.AddTool(mcp.NewToolWithRawSchema("Place", "Place places an order.", json.RawMessage(`)
+	assert.Contains(t, main, "\t\tOrder orders.Order  `json:\"order\"`\n\t\tDelay time.Duration `json:\"delay\"`\n")
+	assert.Contains(t, main, "\tr0, err := orders.Place(ctx, args.Order, args.Delay)\n")
+	assert.Contains(t, main, "\tr0 := orders.Sum(args.Xs...)\n")
+	assert.Contains(t, main, "\tif err := orders.Check(*new(int), args.Strict); err != nil {\n")
+}
+
+func TestGeneratePython(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"shop/__init__.py": "",
+		"shop/cart.py": `def checkout(cart_id: str, qty: int = 1, note: str | None = None, tags: list[str] = []):
+    """Checks out."""
+
+def pick(a, b, /, c=None, *, mode: str = "fast", **options):
+    """Picks."""
+
+async def refresh(cart_id: str) -> bool:
+    """Refreshes."""
+
+class Cart:
+    def __init__(self, owner: str):
+        self.owner = owner
+
+    def total(self) -> int:
+        return 0
+
+    @staticmethod
+    def parse(text: str):
+        """Parses."""
+
+    @classmethod
+    def empty(cls, owner: str):
+        """Makes an empty cart."""
+`,
+		"src/util/text.py": "def shout(s: str) -> str:\n    \"\"\"Shouts.\"\"\"\n",
+		"bin/tool":         "#!/usr/bin/env python3\ndef run():\n    pass\n",
+	})
+
+	project, err := Generate(context.Background(), openRepo(t, root), GenerateOptions{Language: "python", OutputDir: "servers/py"})
+	require.NoError(t, err)
+
+	var tools []string
+	for _, tool := range project.Tools {
+		tools = append(tools, tool.Tool)
+	}
+	assert.Equal(t, []string{"checkout", "pick", "refresh", "Cart_parse", "Cart_empty", "shout"}, tools)
+
+	reasons := skipReasons(project)
+	assert.Equal(t, "constructors return objects that cannot be returned as JSON", reasons["Cart.__init__"])
+	assert.Equal(t, "instance methods need an object to call", reasons["Cart.total"])
+	assert.Equal(t, "only .py files can be imported", reasons["bin/tool"])
+
+	server := generatedFile(t, project, "server.py")
+	assert.Contains(t, server, `REPOSITORY = (Path(__file__).parent / "../..").resolve()`)
+	assert.Contains(t, server, "sys.path.insert(0, str(REPOSITORY / \"src\"))\n")
+	assert.Contains(t, server, `_shop_cart = importlib.import_module("shop.cart")`)
+	assert.Contains(t, server, `_util_text = importlib.import_module("util.text")`)
+	assert.Contains(t, server, `@mcp.tool(name="checkout", description="Checks out.")
+def tool_checkout(
+    cart_id: Annotated[str, Field(description="Parameter cart_id")],
+    qty: Annotated[int, Field(description="Parameter qty")] = 1,
+    note: Annotated[str | None, Field(description="Parameter note")] = None,
+    tags: Annotated[list[str] | None, Field(description="Parameter tags")] = None,
+) -> Any:
+    call_args: list[Any] = []
+    call_kwargs: dict[str, Any] = {"cart_id": cart_id, "qty": qty}
+    if note is not None:
+        call_kwargs["note"] = note
+    if tags is not None:
+        call_kwargs["tags"] = tags
+    return _shop_cart.checkout(*call_args, **call_kwargs)
+`)
+	assert.Contains(t, server, `    call_args: list[Any] = [a, b]
+    call_kwargs: dict[str, Any] = {"mode": mode}
+    if c is not None:
+        call_kwargs["c"] = c
+    return _shop_cart.pick(*call_args, **call_kwargs)
+`)
+	assert.Contains(t, server, "async def tool_refresh(")
+	assert.Contains(t, server, "    return await _shop_cart.refresh(*call_args, **call_kwargs)\n")
+	assert.Contains(t, server, "    return _shop_cart.Cart.empty(*call_args, **call_kwargs)\n")
+
+	assert.Contains(t, generatedFile(t, project, "pyproject.toml"), `dependencies = ["mcp[cli]>=1.2.0"]`)
+}
+
+func TestGenerateUnsupportedLanguage(t *testing.T) {
+	root := writeTree(t, map[string]string{"main.rs": "pub fn run() {}\n"})
+
+	_, err := Generate(context.Background(), openRepo(t, root), GenerateOptions{Language: "rust"})
+	assert.EqualError(t, err, "unsupported server language: rust (supported: go, python)")
+}
+
+func TestHandleGenerateServer(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"calc.py": "def add(a: int, b: int) -> int:\n    \"\"\"Adds.\"\"\"\n    return a + b\n",
+	})
+
+	_, handler := GenerateServerTool(nil)
+
+	t.Run("returns the files", func(t *testing.T) {
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{"language": "python"}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+
+		var project GeneratedServer
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &project))
+		require.Len(t, project.Tools, 1)
+		assert.Equal(t, "add", project.Tools[0].Tool)
+		assert.Contains(t, generatedFile(t, &project, "server.py"), "return _calc.add(*call_args, **call_kwargs)")
+
+		_, err = os.Stat(filepath.Join(root, "mcp-server"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("writes the files", func(t *testing.T) {
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+			"language":   "python",
+			"output_dir": "tools/server",
+			"write":      true,
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+
+		var project GeneratedServer
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &project))
+		for _, file := range project.Files {
+			assert.Empty(t, file.Content)
+		}
+
+		content, err := os.ReadFile(filepath.Join(root, "tools", "server", "server.py"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `REPOSITORY = (Path(__file__).parent / "../..").resolve()`)
+	})
+
+	t.Run("uses the given signatures", func(t *testing.T) {
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+			"language": "python",
+			"files": []interface{}{map[string]interface{}{
+				"path":     "calc.py",
+				"language": "python",
+				"signatures": []interface{}{map[string]interface{}{
+					"name":        "add",
+					"type":        "function",
+					"description": "Adds two numbers.",
+					"parameters":  map[string]interface{}{"type": "object", "properties": map[string]interface{}{"a": map[string]interface{}{"type": "integer"}}},
+					"required":    []interface{}{"a"},
+				}},
+			}},
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+		assert.Contains(t, getTextResult(t, result).Text, `@mcp.tool(name=\"add\", description=\"Adds two numbers.\")`)
+	})
+
+	t.Run("refuses output outside the repository", func(t *testing.T) {
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+			"language":   "go",
+			"output_dir": "../elsewhere",
+		}))
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		assert.Equal(t, "output_dir must be a subdirectory of the repository", getTextResult(t, result).Text)
+	})
+
+	t.Run("refuses symlinks leading out of the repository", func(t *testing.T) {
+		outside := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "out"), 0o750))
+		require.NoError(t, os.Symlink(outside, filepath.Join(root, "out", "cmd")))
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "gen"), 0o750))
+		require.NoError(t, os.Symlink(filepath.Join(outside, "server.py"), filepath.Join(root, "gen", "server.py")))
+
+		for _, dir := range []string{"out/cmd", "gen"} {
+			result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+				"language":   "python",
+				"output_dir": dir,
+				"write":      true,
+			}))
+			require.NoError(t, err)
+			require.True(t, result.IsError, dir)
+			assert.Contains(t, getTextResult(t, result).Text, "cannot write the project", dir)
+		}
+
+		entries, err := os.ReadDir(outside)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,836 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxGitObjectSize bounds the size of a git object read into memory
+	maxGitObjectSize = 512 << 20
+
+	// maxDeltaDepth bounds the chains of deltas of a packed object; git
+	// itself writes chains of at most 4095 deltas
+	maxDeltaDepth = 5000
+
+	// gitCacheSize bounds the memory of the objects a store keeps, which
+	// spares decoding the shared bases of delta chains again
+	gitCacheSize = 64 << 20
+)
+
+// gitObjectType is the type of a git object, numbered as in packfiles
+type gitObjectType int
+
+const (
+	gitCommit   gitObjectType = 1
+	gitTree     gitObjectType = 2
+	gitBlob     gitObjectType = 3
+	gitTag      gitObjectType = 4
+	gitOfsDelta gitObjectType = 6
+	gitRefDelta gitObjectType = 7
+)
+
+var gitObjectTypes = map[string]gitObjectType{"commit": gitCommit, "tree": gitTree, "blob": gitBlob, "tag": gitTag}
+
+func (t gitObjectType) String() string {
+	for name, typ := range gitObjectTypes {
+		if typ == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("object type %d", int(t))
+}
+
+// gitID is the SHA-1 name of a git object
+type gitID [20]byte
+
+func (id gitID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// gitObject is a git object read from the object database
+type gitObject struct {
+	Type gitObjectType
+	Data []byte
+}
+
+// gitStore reads the objects and refs of a local git repository without the
+// git command: loose objects, packfiles with their deltas, loose refs and
+// packed-refs. It keeps the objects it decodes up to gitCacheSize, and is
+// safe for concurrent use. Its packfiles stay open until Close.
+type gitStore struct {
+	gitDir    string // the .git directory, or the worktree directory of a linked worktree
+	commonDir string // the directory holding objects and shared refs
+	packs     []*gitPack
+
+	mu     sync.Mutex
+	cache  map[string]gitObject
+	cached int
+}
+
+// gitPack is an opened packfile with its index
+type gitPack struct {
+	name string // the path of the pack, without its extension
+	idx  *packIndex
+	f    *os.File
+}
+
+// openGitStore opens the git repository whose work tree holds dir, which
+// may be a subdirectory of the work tree. It also returns the slash-separated
+// path of dir within the work tree, "." at its top.
+func openGitStore(dir string) (*gitStore, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	top := dir
+	gitDir := filepath.Join(top, ".git")
+	info, err := os.Stat(gitDir)
+	for err != nil {
+		parent := filepath.Dir(top)
+		if parent == top {
+			return nil, "", fmt.Errorf("%s is not a git repository", dir)
+		}
+		top = parent
+		gitDir = filepath.Join(top, ".git")
+		info, err = os.Stat(gitDir)
+	}
+	prefix, err := filepath.Rel(top, dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !info.IsDir() {
+		// Linked worktrees and submodules point at their git directory
+		content, err := os.ReadFile(gitDir)
+		if err != nil {
+			return nil, "", err
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+		if !ok {
+			return nil, "", fmt.Errorf("%s is not a git repository", top)
+		}
+		gitDir = strings.TrimSpace(target)
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(top, gitDir)
+		}
+	}
+
+	commonDir := gitDir
+	if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(content))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	if format, err := os.ReadFile(filepath.Join(commonDir, "config")); err == nil && bytes.Contains(bytes.ToLower(format), []byte("objectformat = sha256")) {
+		return nil, "", fmt.Errorf("SHA-256 git repositories are not supported")
+	}
+
+	store := &gitStore{gitDir: gitDir, commonDir: commonDir, cache: make(map[string]gitObject)}
+	indexes, _ := filepath.Glob(filepath.Join(commonDir, "objects", "pack", "*.idx"))
+	sort.Strings(indexes)
+	for _, name := range indexes {
+		pack, err := openGitPack(strings.TrimSuffix(name, ".idx"))
+		if err != nil {
+			store.Close()
+			return nil, "", err
+		}
+		store.packs = append(store.packs, pack)
+	}
+	return store, filepath.ToSlash(prefix), nil
+}
+
+// openGitPack opens a packfile and its index, given their path without the
+// extension
+func openGitPack(name string) (*gitPack, error) {
+	idx, err := openPackIndex(name + ".idx")
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name + ".pack")
+	if err != nil {
+		idx.Close()
+		return nil, err
+	}
+	return &gitPack{name: name, idx: idx, f: f}, nil
+}
+
+// Close closes the packfiles of the store
+func (s *gitStore) Close() error {
+	var errs []error
+	for _, pack := range s.packs {
+		errs = append(errs, pack.idx.Close(), pack.f.Close())
+	}
+	s.packs = nil
+	return errors.Join(errs...)
+}
+
+// revisionRE splits a revision into a name and its ~N and ^N suffixes
+var revisionRE = regexp.MustCompile(`^(.*?)((?:[~^][0-9]*)*)$`)
+
+// resolveRevision resolves a revision to a commit: a full or abbreviated
+// object name, HEAD, a branch, tag or remote ref, any of them followed by
+// ~N or ^N to select an ancestor, as git rev-parse does. Annotated tags are
+// peeled to their commit.
+func (s *gitStore) resolveRevision(rev string) (gitID, error) {
+	m := revisionRE.FindStringSubmatch(strings.TrimSpace(rev))
+	name, suffix := m[1], m[2]
+	if name == "" {
+		name = "HEAD"
+	}
+
+	id, err := s.resolveName(name)
+	if err != nil {
+		return gitID{}, err
+	}
+	id, err = s.peel(id, gitCommit)
+	if err != nil {
+		return gitID{}, fmt.Errorf("%s: %w", rev, err)
+	}
+
+	for suffix != "" {
+		op := suffix[0]
+		end := 1
+		for end < len(suffix) && suffix[end] >= '0' && suffix[end] <= '9' {
+			end++
+		}
+		n := 1
+		if end > 1 {
+			n, _ = strconv.Atoi(suffix[1:end])
+		}
+		suffix = suffix[end:]
+
+		switch op {
+		case '~':
+			for ; n > 0; n-- {
+				if id, err = s.parent(id, 1, rev); err != nil {
+					return gitID{}, err
+				}
+			}
+		case '^':
+			if n > 0 {
+				if id, err = s.parent(id, n, rev); err != nil {
+					return gitID{}, err
+				}
+			}
+		}
+	}
+	return id, nil
+}
+
+// resolveName resolves a ref name or an object name, preferring refs
+func (s *gitStore) resolveName(name string) (gitID, error) {
+	for _, candidate := range []string{name, "refs/" + name, "refs/tags/" + name, "refs/heads/" + name, "refs/remotes/" + name, "refs/remotes/" + name + "/HEAD"} {
+		id, ok, err := s.readRef(candidate, 0)
+		if err != nil {
+			return gitID{}, err
+		}
+		if ok {
+			return id, nil
+		}
+	}
+
+	if len(name) >= 4 && len(name) <= 40 && isHex(name) {
+		return s.expandID(strings.ToLower(name))
+	}
+	return gitID{}, fmt.Errorf("unknown revision: %s", name)
+}
+
+// readRef reads a ref, following symbolic refs
+func (s *gitStore) readRef(name string, depth int) (gitID, bool, error) {
+	if depth > 10 {
+		return gitID{}, false, fmt.Errorf("symbolic ref loop at %s", name)
+	}
+	if (name != "HEAD" && !strings.HasPrefix(name, "refs/")) || !fs.ValidPath(name) {
+		return gitID{}, false, nil
+	}
+
+	// Refs of a linked worktree, such as HEAD, live in its own git directory
+	for _, dir := range []string{s.gitDir, s.commonDir} {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(content))
+		if target, ok := strings.CutPrefix(value, "ref:"); ok {
+			return s.readRef(strings.TrimSpace(target), depth+1)
+		}
+		id, err := parseGitID(value)
+		if err != nil {
+			return gitID{}, false, fmt.Errorf("invalid ref %s: %w", name, err)
+		}
+		return id, true, nil
+	}
+
+	packed, err := os.ReadFile(filepath.Join(s.commonDir, "packed-refs"))
+	if err != nil {
+		return gitID{}, false, nil
+	}
+	for _, line := range strings.Split(string(packed), "\n") {
+		value, ref, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || ref != name || strings.HasPrefix(value, "#") || strings.HasPrefix(value, "^") {
+			continue
+		}
+		id, err := parseGitID(value)
+		if err != nil {
+			return gitID{}, false, fmt.Errorf("invalid packed ref %s: %w", name, err)
+		}
+		return id, true, nil
+	}
+	return gitID{}, false, nil
+}
+
+// expandID finds the object whose name starts with an abbreviated name
+func (s *gitStore) expandID(prefix string) (gitID, error) {
+	matches := make(map[gitID]bool)
+
+	entries, _ := os.ReadDir(filepath.Join(s.commonDir, "objects", prefix[:2]))
+	for _, entry := range entries {
+		if name := prefix[:2] + entry.Name(); strings.HasPrefix(name, prefix) {
+			if id, err := parseGitID(name); err == nil {
+				matches[id] = true
+			}
+		}
+	}
+	for _, pack := range s.packs {
+		ids, err := pack.idx.withPrefix(prefix)
+		if err != nil {
+			return gitID{}, err
+		}
+		for _, id := range ids {
+			matches[id] = true
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return gitID{}, fmt.Errorf("unknown revision: %s", prefix)
+	case 1:
+		for id := range matches {
+			return id, nil
+		}
+	}
+	return gitID{}, fmt.Errorf("abbreviated object name %s is ambiguous", prefix)
+}
+
+// peel follows tag objects until an object of the wanted type
+func (s *gitStore) peel(id gitID, want gitObjectType) (gitID, error) {
+	for depth := 0; depth < 10; depth++ {
+		obj, err := s.object(id)
+		if err != nil {
+			return gitID{}, err
+		}
+		if obj.Type == want {
+			return id, nil
+		}
+		switch {
+		case obj.Type == gitTag:
+			id, err = headerID(obj.Data, "object")
+		case obj.Type == gitCommit && want == gitTree:
+			id, err = headerID(obj.Data, "tree")
+		default:
+			return gitID{}, fmt.Errorf("%s is a %s, not a %s", id, obj.Type, want)
+		}
+		if err != nil {
+			return gitID{}, err
+		}
+	}
+	return gitID{}, fmt.Errorf("too many nested tags at %s", id)
+}
+
+// parent returns the nth parent of a commit
+func (s *gitStore) parent(id gitID, n int, rev string) (gitID, error) {
+	obj, err := s.object(id)
+	if err != nil {
+		return gitID{}, err
+	}
+	header, _, _ := bytes.Cut(obj.Data, []byte("\n\n"))
+	for _, line := range strings.Split(string(header), "\n") {
+		if value, ok := strings.CutPrefix(line, "parent "); ok {
+			if n--; n == 0 {
+				return parseGitID(value)
+			}
+		}
+	}
+	return gitID{}, fmt.Errorf("unknown revision: %s, commit %s has no such parent", rev, id)
+}
+
+// headerID reads an object name from a header line of a commit or tag
+func headerID(data []byte, key string) (gitID, error) {
+	header, _, _ := bytes.Cut(data, []byte("\n\n"))
+	for _, line := range strings.Split(string(header), "\n") {
+		if value, ok := strings.CutPrefix(line, key+" "); ok {
+			return parseGitID(value)
+		}
+	}
+	return gitID{}, fmt.Errorf("object has no %s header", key)
+}
+
+// gitTreeEntry is an entry of a tree object
+type gitTreeEntry struct {
+	Name string
+	Mode uint32
+	ID   gitID
+}
+
+// Tree entry modes
+const (
+	gitModeDir     = 0o040000
+	gitModeSymlink = 0o120000
+	gitModeGitlink = 0o160000
+)
+
+// tree reads and parses a tree object
+func (s *gitStore) tree(id gitID) ([]gitTreeEntry, error) {
+	obj, err := s.object(id)
+	if err != nil {
+		return nil, err
+	}
+	if obj.Type != gitTree {
+		return nil, fmt.Errorf("%s is a %s, not a tree", id, obj.Type)
+	}
+
+	var entries []gitTreeEntry
+	data := obj.Data
+	for len(data) > 0 {
+		header, rest, ok := bytes.Cut(data, []byte{0})
+		if !ok || len(rest) < len(gitID{}) {
+			return nil, fmt.Errorf("corrupt tree %s", id)
+		}
+		mode, name, ok := strings.Cut(string(header), " ")
+		if !ok {
+			return nil, fmt.Errorf("corrupt tree %s", id)
+		}
+		value, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt tree %s: %w", id, err)
+		}
+		entry := gitTreeEntry{Name: name, Mode: uint32(value)}
+		copy(entry.ID[:], rest)
+		entries = append(entries, entry)
+		data = rest[len(gitID{}):]
+	}
+	return entries, nil
+}
+
+// object reads an object, loose or packed
+func (s *gitStore) object(id gitID) (gitObject, error) {
+	key := id.String()
+	if obj, ok := s.recall(key); ok {
+		return obj, nil
+	}
+
+	obj, err := s.looseObject(id)
+	if errors.Is(err, fs.ErrNotExist) {
+		obj, err = s.packedObject(id)
+	}
+	if err != nil {
+		return gitObject{}, err
+	}
+	s.remember(key, obj)
+	return obj, nil
+}
+
+// recall returns a cached object
+func (s *gitStore) recall(key string) (gitObject, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.cache[key]
+	return obj, ok
+}
+
+// remember caches an object, emptying the cache when it is full
+func (s *gitStore) remember(key string, obj gitObject) {
+	if len(obj.Data) > gitCacheSize/4 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached+len(obj.Data) > gitCacheSize {
+		s.cache = make(map[string]gitObject)
+		s.cached = 0
+	}
+	s.cache[key] = obj
+	s.cached += len(obj.Data)
+}
+
+// looseObject reads an object stored in its own zlib-compressed file
+func (s *gitStore) looseObject(id gitID) (gitObject, error) {
+	name := id.String()
+	f, err := os.Open(filepath.Join(s.commonDir, "objects", name[:2], name[2:]))
+	if err != nil {
+		return gitObject{}, err
+	}
+	defer f.Close()
+
+	z, err := zlib.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return gitObject{}, fmt.Errorf("corrupt object %s: %w", name, err)
+	}
+	defer z.Close()
+
+	r := bufio.NewReader(z)
+	header, err := r.ReadString(0)
+	if err != nil {
+		return gitObject{}, fmt.Errorf("corrupt object %s: %w", name, err)
+	}
+	typeName, sizeText, _ := strings.Cut(strings.TrimSuffix(header, "\x00"), " ")
+	typ, ok := gitObjectTypes[typeName]
+	size, err := strconv.ParseInt(sizeText, 10, 64)
+	if !ok || err != nil || size < 0 {
+		return gitObject{}, fmt.Errorf("corrupt object %s: invalid header %q", name, header)
+	}
+	if size > maxGitObjectSize {
+		return gitObject{}, fmt.Errorf("object %s is larger than %d bytes", name, maxGitObjectSize)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return gitObject{}, fmt.Errorf("corrupt object %s: %w", name, err)
+	}
+	return gitObject{Type: typ, Data: data}, nil
+}
+
+// packedObject finds an object in the packfiles
+func (s *gitStore) packedObject(id gitID) (gitObject, error) {
+	for _, pack := range s.packs {
+		offset, ok, err := pack.idx.find(id)
+		if err != nil {
+			return gitObject{}, err
+		}
+		if ok {
+			return s.readPacked(pack, offset, 0)
+		}
+	}
+	return gitObject{}, fmt.Errorf("object %s not found", id)
+}
+
+// readPacked reads the object at an offset of a packfile, applying its
+// deltas to their base objects
+func (s *gitStore) readPacked(pack *gitPack, offset int64, depth int) (gitObject, error) {
+	if depth > maxDeltaDepth {
+		return gitObject{}, fmt.Errorf("delta chain in %s is too deep", filepath.Base(pack.name))
+	}
+	key := fmt.Sprintf("%s@%d", pack.name, offset)
+	if obj, ok := s.recall(key); ok {
+		return obj, nil
+	}
+
+	corrupt := func(err error) error {
+		return fmt.Errorf("corrupt packfile %s at offset %d: %w", filepath.Base(pack.name), offset, err)
+	}
+	r := bufio.NewReader(io.NewSectionReader(pack.f, offset, 1<<62))
+
+	// The header holds the type and the inflated size in a varint
+	b, err := r.ReadByte()
+	if err != nil {
+		return gitObject{}, corrupt(err)
+	}
+	typ := gitObjectType(b >> 4 & 7)
+	size := int64(b & 0x0f)
+	for shift := 4; b&0x80 != 0; shift += 7 {
+		if b, err = r.ReadByte(); err != nil {
+			return gitObject{}, corrupt(err)
+		}
+		if shift > 56 {
+			return gitObject{}, corrupt(errors.New("invalid object size"))
+		}
+		size |= int64(b&0x7f) << shift
+	}
+	if size > maxGitObjectSize {
+		return gitObject{}, fmt.Errorf("object at offset %d of %s is larger than %d bytes", offset, filepath.Base(pack.name), maxGitObjectSize)
+	}
+
+	var base gitObject
+	switch typ {
+	case gitCommit, gitTree, gitBlob, gitTag:
+	case gitOfsDelta:
+		// The base precedes the object, at a distance in a big-endian varint
+		// where each continuation adds one
+		if b, err = r.ReadByte(); err != nil {
+			return gitObject{}, corrupt(err)
+		}
+		distance := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = r.ReadByte(); err != nil {
+				return gitObject{}, corrupt(err)
+			}
+			if distance > offset {
+				return gitObject{}, corrupt(errors.New("invalid delta base offset"))
+			}
+			distance = (distance+1)<<7 | int64(b&0x7f)
+		}
+		if distance <= 0 || distance > offset {
+			return gitObject{}, corrupt(errors.New("invalid delta base offset"))
+		}
+		if base, err = s.readPacked(pack, offset-distance, depth+1); err != nil {
+			return gitObject{}, err
+		}
+	case gitRefDelta:
+		var baseID gitID
+		if _, err := io.ReadFull(r, baseID[:]); err != nil {
+			return gitObject{}, corrupt(err)
+		}
+		if base, err = s.object(baseID); err != nil {
+			return gitObject{}, err
+		}
+	default:
+		return gitObject{}, corrupt(fmt.Errorf("unknown object type %d", int(typ)))
+	}
+
+	z, err := zlib.NewReader(r)
+	if err != nil {
+		return gitObject{}, corrupt(err)
+	}
+	defer z.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(z, data); err != nil {
+		return gitObject{}, corrupt(err)
+	}
+
+	obj := gitObject{Type: typ, Data: data}
+	if typ == gitOfsDelta || typ == gitRefDelta {
+		patched, err := applyDelta(base.Data, data)
+		if err != nil {
+			return gitObject{}, corrupt(err)
+		}
+		obj = gitObject{Type: base.Type, Data: patched}
+	}
+	s.remember(key, obj)
+	return obj, nil
+}
+
+// applyDelta rebuilds an object from its base and a delta: the sizes of the
+// base and the result, followed by instructions copying ranges of the base
+// or inserting new bytes
+func applyDelta(base, delta []byte) ([]byte, error) {
+	varint := func() (int, error) {
+		value, shift := 0, 0
+		for {
+			if len(delta) == 0 || shift > 56 {
+				return 0, errors.New("truncated delta header")
+			}
+			b := delta[0]
+			delta = delta[1:]
+			value |= int(b&0x7f) << shift
+			if b&0x80 == 0 {
+				return value, nil
+			}
+			shift += 7
+		}
+	}
+
+	baseSize, err := varint()
+	if err != nil {
+		return nil, err
+	}
+	if baseSize != len(base) {
+		return nil, fmt.Errorf("delta base is %d bytes, expected %d", len(base), baseSize)
+	}
+	size, err := varint()
+	if err != nil {
+		return nil, err
+	}
+	if size > maxGitObjectSize {
+		return nil, fmt.Errorf("delta result is larger than %d bytes", maxGitObjectSize)
+	}
+
+	out := make([]byte, 0, size)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		switch {
+		case op&0x80 != 0:
+			// Copy: bits 0-3 select the offset bytes and bits 4-6 the size bytes
+			var offset, length int
+			for i := 0; i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, errors.New("truncated delta copy")
+				}
+				if i < 4 {
+					offset |= int(delta[0]) << (8 * i)
+				} else {
+					length |= int(delta[0]) << (8 * (i - 4))
+				}
+				delta = delta[1:]
+			}
+			if length == 0 {
+				length = 0x10000
+			}
+			if offset+length > len(base) || len(out)+length > size {
+				return nil, errors.New("delta copy out of range")
+			}
+			out = append(out, base[offset:offset+length]...)
+		case op != 0:
+			length := int(op)
+			if length > len(delta) || len(out)+length > size {
+				return nil, errors.New("truncated delta insert")
+			}
+			out = append(out, delta[:length]...)
+			delta = delta[length:]
+		default:
+			return nil, errors.New("invalid delta instruction")
+		}
+	}
+	if len(out) != size {
+		return nil, fmt.Errorf("delta result is %d bytes, expected %d", len(out), size)
+	}
+	return out, nil
+}
+
+// packIndex is an opened version 2 pack index, read on demand
+type packIndex struct {
+	f      *os.File
+	name   string
+	fanout [256]uint32
+}
+
+func openPackIndex(name string) (*packIndex, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	idx := &packIndex{f: f, name: filepath.Base(name)}
+
+	header := make([]byte, 8+256*4)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("corrupt pack index %s: %w", idx.name, err)
+	}
+	if !bytes.Equal(header[:4], []byte("\377tOc")) || binary.BigEndian.Uint32(header[4:8]) != 2 {
+		f.Close()
+		return nil, fmt.Errorf("unsupported pack index %s, only version 2 is read", idx.name)
+	}
+	for i := range idx.fanout {
+		idx.fanout[i] = binary.BigEndian.Uint32(header[8+4*i:])
+	}
+	return idx, nil
+}
+
+func (idx *packIndex) Close() error {
+	return idx.f.Close()
+}
+
+// count is the number of objects in the pack
+func (idx *packIndex) count() uint32 {
+	return idx.fanout[255]
+}
+
+// id reads the ith object name, in sorted order
+func (idx *packIndex) id(i uint32) (gitID, error) {
+	var id gitID
+	if _, err := idx.f.ReadAt(id[:], 8+256*4+int64(i)*20); err != nil {
+		return gitID{}, fmt.Errorf("corrupt pack index %s: %w", idx.name, err)
+	}
+	return id, nil
+}
+
+// search returns the index of the first object name not below id, among
+// those sharing its first byte
+func (idx *packIndex) search(id gitID) (uint32, error) {
+	lo := uint32(0)
+	if id[0] > 0 {
+		lo = idx.fanout[id[0]-1]
+	}
+	hi := idx.fanout[id[0]]
+	var err error
+	i := lo + uint32(sort.Search(int(hi-lo), func(n int) bool {
+		candidate, e := idx.id(lo + uint32(n))
+		if e != nil {
+			err = e
+			return true
+		}
+		return bytes.Compare(candidate[:], id[:]) >= 0
+	}))
+	return i, err
+}
+
+// find returns the offset of an object in the pack
+func (idx *packIndex) find(id gitID) (int64, bool, error) {
+	i, err := idx.search(id)
+	if err != nil || i >= idx.fanout[id[0]] {
+		return 0, false, err
+	}
+	if candidate, err := idx.id(i); err != nil || candidate != id {
+		return 0, false, err
+	}
+
+	// Offsets follow the names and their CRCs; large ones are stored apart
+	n := int64(idx.count())
+	var buf [8]byte
+	if _, err := idx.f.ReadAt(buf[:4], 8+256*4+n*24+int64(i)*4); err != nil {
+		return 0, false, fmt.Errorf("corrupt pack index %s: %w", idx.name, err)
+	}
+	offset := binary.BigEndian.Uint32(buf[:4])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true, nil
+	}
+	if _, err := idx.f.ReadAt(buf[:], 8+256*4+n*28+int64(offset&0x7fffffff)*8); err != nil {
+		return 0, false, fmt.Errorf("corrupt pack index %s: %w", idx.name, err)
+	}
+	return int64(binary.BigEndian.Uint64(buf[:])), true, nil
+}
+
+// withPrefix returns the names of the objects starting with a hex prefix
+func (idx *packIndex) withPrefix(prefix string) ([]gitID, error) {
+	var low gitID
+	decoded, err := hex.DecodeString(prefix[:len(prefix)&^1])
+	if err != nil {
+		return nil, err
+	}
+	copy(low[:], decoded)
+
+	i, err := idx.search(low)
+	if err != nil {
+		return nil, err
+	}
+	var ids []gitID
+	for ; i < idx.fanout[low[0]]; i++ {
+		id, err := idx.id(i)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(id.String(), prefix) {
+			break
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseGitID(value string) (gitID, error) {
+	var id gitID
+	if len(value) != 40 || !isHex(value) {
+		return id, fmt.Errorf("invalid object name %q", value)
+	}
+	_, err := hex.Decode(id[:], []byte(strings.ToLower(value)))
+	return id, err
+}
+
+func isHex(value string) bool {
+	for _, c := range value {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitCommand runs git in dir, isolated from the user configuration
+func gitCommand(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "init.defaultBranch=main", "-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	return strings.TrimSpace(string(out))
+}
+
+// writeGitHistory creates a repository with two commits: v1, tagged with an
+// annotated tag, and the current one, on the main branch. The long file
+// changes by one line, so packing it stores a delta.
+func writeGitHistory(t *testing.T) (root, v1 string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	var long strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&long, "line %d of a file long enough to be stored as a delta\n", i)
+	}
+	root = writeTree(t, map[string]string{
+		"app.py":       "def greet(name: str) -> str:\n    return 'hello ' + name\n",
+		"old/notes.md": "# Notes\n",
+		"long.txt":     long.String(),
+	})
+	gitCommand(t, root, "init", "-q")
+	gitCommand(t, root, "add", "-A")
+	gitCommand(t, root, "commit", "-q", "-m", "v1")
+	gitCommand(t, root, "tag", "-a", "v1.0", "-m", "release 1.0")
+	v1 = gitCommand(t, root, "rev-parse", "HEAD")
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app.py"), []byte("def greet(name: str, polite: bool) -> str:\n    return 'hi ' + name\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "long.txt"), []byte(strings.Replace(long.String(), "line 150 ", "LINE 150 ", 1)), 0o600))
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "old")))
+	gitCommand(t, root, "add", "-A")
+	gitCommand(t, root, "commit", "-q", "-m", "v2")
+
+	// The work tree differs from both commits
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app.py"), []byte("uncommitted\n"), 0o600))
+	return root, v1
+}
+
+func TestAtRevision(t *testing.T) {
+	root, v1 := writeGitHistory(t)
+
+	check := func(t *testing.T) {
+		repo := openRepo(t, root)
+		for _, rev := range []string{"v1.0", "tags/v1.0", "refs/tags/v1.0", "HEAD~1", "main^", "main~", "HEAD^1", v1, v1[:7], strings.ToUpper(v1[:10])} {
+			at, err := repo.AtRevision(rev)
+			require.NoError(t, err, rev)
+			content, err := at.ReadFile("app.py")
+			require.NoError(t, err, rev)
+			assert.Equal(t, "def greet(name: str) -> str:\n    return 'hello ' + name\n", string(content), rev)
+		}
+
+		head, err := repo.AtRevision("HEAD")
+		require.NoError(t, err)
+		content, err := head.ReadFile("app.py")
+		require.NoError(t, err)
+		assert.Equal(t, "def greet(name: str, polite: bool) -> str:\n    return 'hi ' + name\n", string(content))
+
+		long, err := head.ReadFile("long.txt")
+		require.NoError(t, err)
+		assert.Contains(t, string(long), "LINE 150 of a file")
+		_, err = head.Stat("old/notes.md")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+
+		at, err := repo.AtRevision("v1.0")
+		require.NoError(t, err)
+		var files []string
+		require.NoError(t, walkRepository(at, func(relPath string) error {
+			files = append(files, relPath)
+			return nil
+		}))
+		assert.Equal(t, []string{"app.py", "long.txt", "old/notes.md"}, files)
+		info, err := at.Stat("long.txt")
+		require.NoError(t, err)
+		assert.EqualValues(t, len(long), info.Size())
+
+		for rev, msg := range map[string]string{
+			"v9":     "unknown revision: v9",
+			"HEAD~5": "has no such parent",
+			"main^2": "has no such parent",
+		} {
+			_, err := repo.AtRevision(rev)
+			assert.ErrorContains(t, err, msg, rev)
+		}
+	}
+
+	t.Run("loose objects", check)
+
+	t.Run("packfiles", func(t *testing.T) {
+		gitCommand(t, root, "repack", "-a", "-d", "-f", "-q", "--depth=50", "--window=50")
+		gitCommand(t, root, "prune")
+		gitCommand(t, root, "pack-refs", "--all")
+		loose, err := filepath.Glob(filepath.Join(root, ".git", "objects", "??", "*"))
+		require.NoError(t, err)
+		assert.Empty(t, loose)
+		_, err = os.Stat(filepath.Join(root, ".git", "refs", "tags", "v1.0"))
+		assert.ErrorIs(t, err, os.ErrNotExist, "refs are packed")
+
+		packs, err := filepath.Glob(filepath.Join(root, ".git", "objects", "pack", "*.pack"))
+		require.NoError(t, err)
+		require.Len(t, packs, 1)
+		assert.Contains(t, gitCommand(t, root, "verify-pack", "-v", packs[0]), "chain length = 1", "the pack holds deltas")
+
+		check(t)
+
+		at, err := openRepo(t, root).AtRevision("HEAD")
+		require.NoError(t, err)
+		assert.Len(t, at.fsys.(*gitFS).store.packs, 1, "the pack is opened once")
+		require.NoError(t, at.Close())
+		_, err = at.ReadFile("long.txt")
+		assert.Error(t, err, "the closed store reads no packs")
+	})
+
+	t.Run("subdirectory", func(t *testing.T) {
+		require.NoError(t, os.Mkdir(filepath.Join(root, "old"), 0o755))
+		repo := openRepo(t, filepath.Join(root, "old"))
+
+		at, err := repo.AtRevision("v1.0")
+		require.NoError(t, err)
+		content, err := at.ReadFile("notes.md")
+		require.NoError(t, err)
+		assert.Equal(t, "# Notes\n", string(content))
+
+		_, err = repo.AtRevision("HEAD")
+		assert.ErrorContains(t, err, "directory old does not exist at revision HEAD")
+	})
+
+	t.Run("not a git repository", func(t *testing.T) {
+		_, err := openRepo(t, t.TempDir()).AtRevision("HEAD")
+		assert.ErrorContains(t, err, "is not a git repository")
+	})
+}
+
+func TestToolsReadRevision(t *testing.T) {
+	root, _ := writeGitHistory(t)
+	roots, err := ParseRoots([]string{root})
+	require.NoError(t, err)
+
+	result, err := handleGetFileList(context.Background(), createMCPRequest(map[string]interface{}{"ref": "v1.0", "extension": "md"}), roots)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getTextResult(t, result).Text)
+	var files []FileEntry
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &files))
+	assert.Equal(t, []FileEntry{{Path: "old/notes.md", Size: 8, Language: "markdown"}}, files)
+
+	result, err = handleGetFileContent(context.Background(), createMCPRequest(map[string]interface{}{"path": "app.py", "ref": "HEAD"}), roots, defaultContentWindowSize)
+	require.NoError(t, err)
+	assert.Equal(t, "def greet(name: str, polite: bool) -> str:\n    return 'hi ' + name\n", getTextResult(t, result).Text)
+
+	result, err = handleGetFileContent(context.Background(), createMCPRequest(map[string]interface{}{"path": "app.py"}), roots, defaultContentWindowSize)
+	require.NoError(t, err)
+	assert.Equal(t, "uncommitted\n", getTextResult(t, result).Text)
+
+	result, err = handleExtractRepositorySignatures(context.Background(), createMCPRequest(map[string]interface{}{"ref": "v1.0"}), roots)
+	require.NoError(t, err)
+	var signatures RepositorySignatures
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &signatures))
+	require.Len(t, signatures.Files, 1)
+	assert.Equal(t, "def greet(name: str) -> str:", signatures.Files[0].Signatures[0].Signature)
+
+	result, err = handleSearchCode(context.Background(), createMCPRequest(map[string]interface{}{"query": "hello", "ref": "v1.0"}), roots)
+	require.NoError(t, err)
+	var search SearchResults
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &search))
+	require.Len(t, search.Matches, 1)
+	assert.Equal(t, "    return 'hello ' + name", search.Matches[0].Text)
+
+	result, err = handleGetFileContent(context.Background(), createMCPRequest(map[string]interface{}{"path": "app.py", "ref": "nope"}), roots, defaultContentWindowSize)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Equal(t, "unknown revision: nope", getTextResult(t, result).Text)
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("hello, world")
+	// Sizes 12 and 16, copy "hello", insert " big", copy " world", insert "!"
+	delta := []byte{12, 16, 0x90, 5, 4, ' ', 'b', 'i', 'g', 0x91, 6, 6, 1, '!'}
+	out, err := applyDelta(base, delta)
+	require.NoError(t, err)
+	assert.Equal(t, "hello big world!", string(out))
+
+	_, err = applyDelta(base, []byte{11, 1, 1, 'x'})
+	assert.ErrorContains(t, err, "delta base is 12 bytes")
+	_, err = applyDelta(base, []byte{12, 4, 0x91, 10, 4})
+	assert.ErrorContains(t, err, "out of range")
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// AtRevision returns the tree of a git revision of the repository, read
+// from its local object database without touching the work tree or the
+// network. The revision is anything resolveRevision accepts, such as a tag,
+// a branch, an abbreviated commit or HEAD~2. A repository in a subdirectory
+// of a work tree reads that subdirectory of the revision. Symlinks and
+// submodules of the tree are left out. The returned repository keeps the
+// packfiles open until it is closed.
+func (r *RepoFS) AtRevision(rev string) (*RepoFS, error) {
+	if r.dir == "" {
+		return nil, fmt.Errorf("repository %s is not a local directory, so it has no git history to read", r.name)
+	}
+	store, prefix, err := openGitStore(r.dir)
+	if err != nil {
+		return nil, err
+	}
+	root, err := revisionTree(store, rev, prefix)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return &RepoFS{fsys: &gitFS{store: store, root: root}, name: r.name, rev: rev, closer: store}, nil
+}
+
+// revisionTree returns the tree of a directory of a revision, given by its
+// slash-separated path
+func revisionTree(store *gitStore, rev, prefix string) (gitID, error) {
+	commit, err := store.resolveRevision(rev)
+	if err != nil {
+		return gitID{}, err
+	}
+	tree, err := store.peel(commit, gitTree)
+	if err != nil {
+		return gitID{}, err
+	}
+	entry, err := (&gitFS{store: store, root: tree}).lookup("open", prefix)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && entry.Mode != gitModeDir) {
+		return gitID{}, fmt.Errorf("directory %s does not exist at revision %s", prefix, rev)
+	}
+	if err != nil {
+		return gitID{}, err
+	}
+	return entry.ID, nil
+}
+
+// gitFS is the tree of a git commit
+type gitFS struct {
+	store *gitStore
+	root  gitID
+}
+
+// lookup finds the tree entry of a path
+func (g *gitFS) lookup(op, name string) (gitTreeEntry, error) {
+	if !fs.ValidPath(name) {
+		return gitTreeEntry{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	entry := gitTreeEntry{Name: ".", Mode: gitModeDir, ID: g.root}
+	if name == "." {
+		return entry, nil
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if entry.Mode != gitModeDir {
+			return gitTreeEntry{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		entries, err := g.store.tree(entry.ID)
+		if err != nil {
+			return gitTreeEntry{}, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		found := false
+		for _, child := range entries {
+			if child.Name == part && servedGitEntry(child) {
+				entry, found = child, true
+				break
+			}
+		}
+		if !found {
+			return gitTreeEntry{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return entry, nil
+}
+
+// servedGitEntry reports whether a tree entry is a file or directory, as
+// opposed to a symlink or a submodule
+func servedGitEntry(entry gitTreeEntry) bool {
+	return entry.Mode != gitModeSymlink && entry.Mode != gitModeGitlink
+}
+
+func (g *gitFS) Open(name string) (fs.File, error) {
+	entry, err := g.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	node := &memNode{name: path.Base(name), mode: fs.FileMode(entry.Mode & 0o777)}
+
+	if entry.Mode != gitModeDir {
+		obj, err := g.store.object(entry.ID)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		if obj.Type != gitBlob {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("%s is a %s, not a blob", entry.ID, obj.Type)}
+		}
+		node.content = obj.Data
+		return &memFile{node: node, Reader: strings.NewReader(string(obj.Data))}, nil
+	}
+
+	children, err := g.store.tree(entry.ID)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	node.mode = fs.ModeDir | 0o755
+	node.children = make(map[string]*memNode)
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, child := range children {
+		if servedGitEntry(child) {
+			entries = append(entries, gitDirEntry{fsys: g, dir: name, entry: child})
+		}
+	}
+	// Trees sort directories as if their name ended with a slash, unlike
+	// fs.ReadDir
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return &memDir{node: node, entries: entries}, nil
+}
+
+// gitDirEntry is an entry of a directory of a gitFS, whose size is only
+// read when its info is asked for
+type gitDirEntry struct {
+	fsys  *gitFS
+	dir   string
+	entry gitTreeEntry
+}
+
+func (e gitDirEntry) Name() string { return e.entry.Name }
+func (e gitDirEntry) IsDir() bool  { return e.entry.Mode == gitModeDir }
+
+func (e gitDirEntry) Type() fs.FileMode {
+	if e.IsDir() {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+func (e gitDirEntry) Info() (fs.FileInfo, error) {
+	return fs.Stat(e.fsys, path.Join(e.dir, e.entry.Name))
+}
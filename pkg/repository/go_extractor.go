@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// extractGoSignatures extracts exported functions, methods, structs and interfaces from Go source code
+func extractGoSignatures(code string) ([]FunctionSignature, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "source.go", code, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Go source: %w", err)
+	}
+
+	// go/doc needs an import path but never resolves it
+	pkg, err := doc.NewFromFiles(fset, []*ast.File{file}, "source", doc.PreserveAST)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Go documentation: %w", err)
+	}
+
+	types := collectGoTypes(file)
+
+	type positioned struct {
+		pos token.Pos
+		sig FunctionSignature
+	}
+	var found []positioned
+
+	// constructorOf names the type a NewFoo function constructs
+	addFunc := func(fn *doc.Func, constructorOf string) {
+		sig := goFuncSignature(fset, fn, types)
+		if constructorOf != "" {
+			sig.Type = "constructor"
+			sig.Class = constructorOf
+		}
+		sig.Line = fset.Position(fn.Decl.Pos()).Line
+		found = append(found, positioned{pos: fn.Decl.Pos(), sig: sig})
+	}
+
+	for _, fn := range pkg.Funcs {
+		addFunc(fn, "")
+	}
+
+	for _, typ := range pkg.Types {
+		for _, spec := range typ.Decl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.Name.Name != typ.Name {
+				continue
+			}
+
+			var kind string
+			switch ts.Type.(type) {
+			case *ast.StructType:
+				kind = "struct"
+			case *ast.InterfaceType:
+				kind = "interface"
+			default:
+				continue
+			}
+
+			sig := FunctionSignature{
+				Name:        typ.Name,
+				Type:        kind,
+				Signature:   "type " + goNodeString(fset, ts),
+				Description: strings.TrimSpace(typ.Doc),
+				Line:        fset.Position(ts.Pos()).Line,
+			}
+			if kind == "struct" {
+				schema := goTypeSchema(ts.Type, types, map[string]bool{typ.Name: true})
+				sig.Parameters = map[string]interface{}{
+					"type":       "object",
+					"properties": schema["properties"],
+				}
+				sig.Required = goStringSlice(schema["required"])
+			}
+			found = append(found, positioned{pos: ts.Pos(), sig: sig})
+		}
+
+		// Constructors such as NewFoo are attached to their result type by go/doc
+		for _, fn := range typ.Funcs {
+			if fn.Name == "New"+typ.Name {
+				addFunc(fn, typ.Name)
+				continue
+			}
+			addFunc(fn, "")
+		}
+		for _, fn := range typ.Methods {
+			addFunc(fn, "")
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].pos < found[j].pos
+	})
+
+	signatures := make([]FunctionSignature, 0, len(found))
+	for _, f := range found {
+		signatures = append(signatures, f.sig)
+	}
+
+	return signatures, nil
+}
+
+// goFuncSignature builds the signature of a function or method declaration
+func goFuncSignature(fset *token.FileSet, fn *doc.Func, types map[string]ast.Expr) FunctionSignature {
+	name := fn.Name
+	kind := "function"
+	class := strings.TrimPrefix(fn.Recv, "*")
+	if fn.Recv != "" {
+		name = class + "." + fn.Name
+		kind = "method"
+	}
+
+	// Print the declaration without its body or doc comment
+	decl := *fn.Decl
+	decl.Body = nil
+	decl.Doc = nil
+
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	if decl.Type.Params != nil {
+		index := 0
+		for _, field := range decl.Type.Params.List {
+			names := make([]string, 0, len(field.Names))
+			for _, ident := range field.Names {
+				names = append(names, ident.Name)
+			}
+			if len(names) == 0 {
+				names = append(names, fmt.Sprintf("arg%d", index))
+			}
+			index += len(names)
+
+			// context.Context is supplied by the caller, not the model
+			if goIsContext(field.Type) {
+				continue
+			}
+
+			typ := field.Type
+			variadic := false
+			if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+				typ = &ast.ArrayType{Elt: ellipsis.Elt}
+				variadic = true
+			}
+
+			for _, paramName := range names {
+				if paramName == "_" {
+					continue
+				}
+				schema := goTypeSchema(typ, types, map[string]bool{})
+				schema["description"] = fmt.Sprintf("Parameter %s", paramName)
+				properties[paramName] = schema
+				if !variadic {
+					required = append(required, paramName)
+				}
+			}
+		}
+	}
+
+	return FunctionSignature{
+		Name:        name,
+		Type:        kind,
+		Class:       class,
+		Signature:   goNodeString(fset, &decl),
+		Description: strings.TrimSpace(fn.Doc),
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		},
+		Required: required,
+	}
+}
+
+// collectGoTypes indexes every type declared in the file by name
+func collectGoTypes(file *ast.File) map[string]ast.Expr {
+	types := make(map[string]ast.Expr)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				types[ts.Name.Name] = ts.Type
+			}
+		}
+	}
+	return types
+}
+
+// goTypeSchema maps a Go type expression to a JSON Schema, following the encoding/json rules.
+// seen guards against infinite recursion through self-referencing types.
+func goTypeSchema(expr ast.Expr, types map[string]ast.Expr, seen map[string]bool) map[string]interface{} {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "bool":
+			return map[string]interface{}{"type": "boolean"}
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+			return map[string]interface{}{"type": "integer"}
+		case "float32", "float64":
+			return map[string]interface{}{"type": "number"}
+		case "string":
+			return map[string]interface{}{"type": "string"}
+		case "error":
+			return map[string]interface{}{"type": "string"}
+		case "any":
+			return map[string]interface{}{}
+		}
+		if underlying, ok := types[t.Name]; ok && !seen[t.Name] {
+			nested := make(map[string]bool, len(seen)+1)
+			for k, v := range seen {
+				nested[k] = v
+			}
+			nested[t.Name] = true
+			return goTypeSchema(underlying, types, nested)
+		}
+		return map[string]interface{}{"type": "object"}
+	case *ast.StarExpr:
+		return goTypeSchema(t.X, types, seen)
+	case *ast.ParenExpr:
+		return goTypeSchema(t.X, types, seen)
+	case *ast.ArrayType:
+		// encoding/json writes []byte as a base64 string
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" && t.Len == nil {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": goTypeSchema(t.Elt, types, seen),
+		}
+	case *ast.MapType:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": goTypeSchema(t.Value, types, seen),
+		}
+	case *ast.StructType:
+		return goStructSchema(t, types, seen)
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			switch pkg.Name + "." + t.Sel.Name {
+			case "time.Time":
+				return map[string]interface{}{"type": "string", "format": "date-time"}
+			case "time.Duration":
+				return map[string]interface{}{"type": "integer"}
+			case "json.RawMessage", "json.Number":
+				return map[string]interface{}{}
+			}
+		}
+		return map[string]interface{}{"type": "object"}
+	case *ast.InterfaceType:
+		return map[string]interface{}{}
+	}
+
+	// Channels, functions and generic instantiations have no JSON representation
+	return map[string]interface{}{}
+}
+
+// goStructSchema maps struct fields to object properties using their json tags
+func goStructSchema(st *ast.StructType, types map[string]ast.Expr, seen map[string]bool) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for _, field := range st.Fields.List {
+		name, omitEmpty, skip := goJSONTag(field)
+		if skip {
+			continue
+		}
+
+		// Embedded structs are flattened into the parent like encoding/json does
+		if len(field.Names) == 0 {
+			embedded := goTypeSchema(field.Type, types, seen)
+			if name == "" {
+				if props, ok := embedded["properties"].(map[string]interface{}); ok {
+					for k, v := range props {
+						properties[k] = v
+					}
+					required = append(required, goStringSlice(embedded["required"])...)
+					continue
+				}
+				name = goEmbeddedName(field.Type)
+			}
+			if name == "" || !ast.IsExported(name) {
+				continue
+			}
+			properties[name] = embedded
+			if !omitEmpty {
+				required = append(required, name)
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			fieldName := ident.Name
+			if name != "" {
+				fieldName = name
+			}
+
+			schema := goTypeSchema(field.Type, types, seen)
+			if text := strings.TrimSpace(field.Doc.Text()); text != "" {
+				schema["description"] = text
+			} else if text := strings.TrimSpace(field.Comment.Text()); text != "" {
+				schema["description"] = text
+			}
+			properties[fieldName] = schema
+
+			_, pointer := field.Type.(*ast.StarExpr)
+			if !omitEmpty && !pointer {
+				required = append(required, fieldName)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// goJSONTag reads the json struct tag of a field
+func goJSONTag(field *ast.Field) (name string, omitEmpty bool, skip bool) {
+	if field.Tag == nil {
+		return "", false, false
+	}
+
+	tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+	value, ok := tag.Lookup("json")
+	if !ok {
+		return "", false, false
+	}
+	if value == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(value, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, false
+}
+
+// goEmbeddedName returns the field name of an embedded type
+func goEmbeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return goEmbeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// goIsContext reports whether the expression is context.Context
+func goIsContext(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "context" && sel.Sel.Name == "Context"
+}
+
+// goNodeString prints an AST node as Go source
+func goNodeString(fset *token.FileSet, node interface{}) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func goStringSlice(v interface{}) []string {
+	s, _ := v.([]string)
+	return s
+}
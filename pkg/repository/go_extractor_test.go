@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractGoSignatures(t *testing.T) {
+	code := `package shop
+
+import (
+	"context"
+	"time"
+)
+
+// Item is a line in an order.
+type Item struct {
+	// SKU identifies the product.
+	SKU      string            ` + "`json:\"sku\"`" + `
+	Quantity int               ` + "`json:\"quantity\"`" + `
+	Tags     []string          ` + "`json:\"tags,omitempty\"`" + `
+	Meta     map[string]int    ` + "`json:\"meta,omitempty\"`" + `
+	Secret   string            ` + "`json:\"-\"`" + `
+	internal bool
+}
+
+// Order groups items.
+type Order struct {
+	ID      string    ` + "`json:\"id\"`" + `
+	Items   []Item    ` + "`json:\"items\"`" + `
+	Created time.Time ` + "`json:\"created\"`" + `
+	Note    *string   ` + "`json:\"note\"`" + `
+}
+
+// NewOrder starts an empty order.
+func NewOrder(id string) *Order {
+	return &Order{ID: id}
+}
+
+// Store persists orders.
+type Store interface {
+	Save(ctx context.Context, o Order) error
+}
+
+// Place submits an order for fulfilment.
+func Place(ctx context.Context, order Order, priority int, rush bool, ratio float64) (string, error) {
+	return "", nil
+}
+
+// Total sums the quantities.
+func (o *Order) Total(extra ...int) int {
+	return 0
+}
+
+func helper() {}
+
+func (o *Order) unexported() {}
+`
+
+	signatures, err := extractGoSignatures(code)
+	require.NoError(t, err)
+
+	byName := make(map[string]FunctionSignature)
+	var names []string
+	for _, sig := range signatures {
+		byName[sig.Name] = sig
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{"Item", "Order", "NewOrder", "Store", "Place", "Order.Total"}, names)
+
+	item := byName["Item"]
+	assert.Equal(t, "struct", item.Type)
+	assert.Equal(t, "Item is a line in an order.", item.Description)
+	itemProps := item.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "SKU identifies the product."}, itemProps["sku"])
+	assert.Equal(t, map[string]interface{}{"type": "integer"}, itemProps["quantity"])
+	assert.Equal(t, map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}, itemProps["tags"])
+	assert.Equal(t, map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "integer"}}, itemProps["meta"])
+	assert.NotContains(t, itemProps, "Secret")
+	assert.NotContains(t, itemProps, "internal")
+	assert.ElementsMatch(t, []string{"sku", "quantity"}, item.Required)
+
+	assert.Equal(t, "interface", byName["Store"].Type)
+
+	place := byName["Place"]
+	assert.Equal(t, "function", place.Type)
+	assert.Equal(t, "Place submits an order for fulfilment.", place.Description)
+	assert.NotContains(t, place.Signature, "return")
+	assert.Equal(t, []string{"order", "priority", "rush", "ratio"}, place.Required)
+	placeProps := place.Parameters["properties"].(map[string]interface{})
+	assert.NotContains(t, placeProps, "ctx")
+	assert.Equal(t, "integer", placeProps["priority"].(map[string]interface{})["type"])
+	assert.Equal(t, "boolean", placeProps["rush"].(map[string]interface{})["type"])
+	assert.Equal(t, "number", placeProps["ratio"].(map[string]interface{})["type"])
+
+	order := placeProps["order"].(map[string]interface{})
+	assert.Equal(t, "object", order["type"])
+	orderProps := order["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, orderProps["created"])
+	items := orderProps["items"].(map[string]interface{})
+	assert.Equal(t, "array", items["type"])
+	assert.Equal(t, "object", items["items"].(map[string]interface{})["type"])
+	assert.ElementsMatch(t, []string{"id", "items", "created"}, order["required"])
+
+	newOrder := byName["NewOrder"]
+	assert.Equal(t, "constructor", newOrder.Type)
+	assert.Equal(t, "Order", newOrder.Class)
+	assert.Equal(t, []string{"id"}, newOrder.Required)
+	assert.Empty(t, place.Class)
+
+	total := byName["Order.Total"]
+	assert.Equal(t, "method", total.Type)
+	assert.Equal(t, "Order", total.Class)
+	assert.Empty(t, total.Required)
+	extra := total.Parameters["properties"].(map[string]interface{})["extra"].(map[string]interface{})
+	assert.Equal(t, "array", extra["type"])
+}
+
+func TestExtractGoSignaturesInvalidSource(t *testing.T) {
+	_, err := extractGoSignatures("func {")
+	require.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createMCPRequest is a helper function to create a MCP request with the given arguments.
+func createMCPRequest(args any) mcp.CallToolRequest {
+	return mcp.CallToolRequest{
+		Params: struct {
+			Name      string    `json:"name"`
+			Arguments any       `json:"arguments,omitempty"`
+			Meta      *mcp.Meta `json:"_meta,omitempty"`
+		}{
+			Arguments: args,
+		},
+	}
+}
+
+// getTextResult is a helper function that returns a text result from a tool call.
+func getTextResult(t *testing.T, result *mcp.CallToolResult) mcp.TextContent {
+	t.Helper()
+	assert.NotNil(t, result)
+	require.Len(t, result.Content, 1)
+	require.IsType(t, mcp.TextContent{}, result.Content[0])
+	textContent := result.Content[0].(mcp.TextContent)
+	assert.Equal(t, "text", textContent.Type)
+	return textContent
+}
+
+// writeTree creates the given files, keyed by slash-separated path, below a
+// temporary directory and makes it the working directory for the test.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(root))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return root
+}
+
+// openRepo opens the repository in a local directory
+func openRepo(t *testing.T, dir string) *RepoFS {
+	t.Helper()
+	repo, err := OpenDir(dir)
+	require.NoError(t, err)
+	return repo
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"path"
+	"strings"
+)
+
+// IgnoreFileName is the project file listing paths the repository tools skip,
+// in addition to those ignored by git. It uses the .gitignore syntax.
+const IgnoreFileName = ".mcpprimeignore"
+
+// ignoreFiles are the files read in every directory of the repository, in
+// increasing order of precedence
+var ignoreFiles = []string{".gitignore", IgnoreFileName}
+
+// ignoreRule is a pattern of an ignore file
+type ignoreRule struct {
+	base     string   // slash-separated directory of the ignore file, "" at the root
+	segments []string // pattern split at slashes
+	anchored bool     // matched against the path below base rather than the name
+	dirOnly  bool     // only matches directories
+	negate   bool     // re-includes matching paths
+}
+
+// parseIgnoreRules parses the patterns of an ignore file in the base
+// directory, following the .gitignore syntax
+func parseIgnoreRules(base, content string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Trailing spaces are ignored unless escaped
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+			line = line[:len(line)-1]
+		}
+
+		rule := ignoreRule{base: base}
+		switch {
+		case strings.HasPrefix(line, "!"):
+			rule.negate = true
+			line = line[1:]
+		case strings.HasPrefix(line, "\\!"), strings.HasPrefix(line, "\\#"):
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// A slash at the start or in the middle anchors the pattern to base
+		rule.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		rule.segments = strings.Split(strings.ReplaceAll(line, "[!", "[^"), "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matches reports whether the rule matches a slash-separated path relative to
+// the repository root
+func (r ignoreRule) matches(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(relPath, r.base+"/") {
+			return false
+		}
+		relPath = relPath[len(r.base)+1:]
+	}
+	if !r.anchored {
+		ok, _ := path.Match(r.segments[0], path.Base(relPath))
+		return ok
+	}
+	return matchIgnoreSegments(r.segments, strings.Split(relPath, "/"))
+}
+
+// matchIgnoreSegments matches a whole path against an anchored pattern. A
+// "**" segment matches any number of directories, and a trailing "**"
+// everything inside a directory but not the directory itself.
+func matchIgnoreSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return len(name) > 0
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchIgnoreSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ignoreMatcher collects the ignore rules of the directories visited by a
+// walk. Directories are visited before their contents, so the rules of
+// deeper directories come later and take precedence.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// newIgnoreMatcher returns a matcher holding the repository-wide rules of
+// .git/info/exclude
+func newIgnoreMatcher(fsys *RepoFS) *ignoreMatcher {
+	m := &ignoreMatcher{}
+	if content, err := fsys.ReadFile(".git/info/exclude"); err == nil {
+		m.rules = parseIgnoreRules("", string(content))
+	}
+	return m
+}
+
+// load adds the rules of the ignore files of a directory, given by its
+// slash-separated path
+func (m *ignoreMatcher) load(fsys *RepoFS, dir string) {
+	base := dir
+	if base == "." {
+		base = ""
+	}
+	for _, name := range ignoreFiles {
+		content, err := fsys.ReadFile(path.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		m.rules = append(m.rules, parseIgnoreRules(base, string(content))...)
+	}
+}
+
+// ignored reports whether a slash-separated path relative to the root is
+// ignored; the last matching rule decides
+func (m *ignoreMatcher) ignored(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.matches(relPath, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	m := &ignoreMatcher{}
+	m.rules = append(m.rules, parseIgnoreRules("", `# build output
+*.log
+!keep.log
+/coverage
+docs/generated/
+**/fixtures/*.bin
+out/**
+\#notes
+trailing.txt  
+[!a]bc
+`)...)
+	m.rules = append(m.rules, parseIgnoreRules("web", "dist\n!debug.log\n/local.env\n")...)
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"server.log", false, true},
+		{"a/b/server.log", false, true},
+		{"keep.log", false, false},
+		{"coverage", true, true},
+		{"src/coverage", true, false},
+		{"docs/generated", true, true},
+		{"docs/generated", false, false},
+		{"x/docs/generated", true, false},
+		{"fixtures/a.bin", false, true},
+		{"test/unit/fixtures/a.bin", false, true},
+		{"fixtures/a.txt", false, false},
+		{"out", true, false},
+		{"out/a/b.txt", false, true},
+		{"#notes", false, true},
+		{"trailing.txt", false, true},
+		{"xbc", false, true},
+		{"abc", false, false},
+		{"web/dist", true, true},
+		{"dist", true, false},
+		{"web/debug.log", false, false},
+		{"web/app/debug.log", false, false},
+		{"web/local.env", false, true},
+		{"web/app/local.env", false, false},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, m.ignored(tc.path, tc.isDir), "%s (dir: %v)", tc.path, tc.isDir)
+	}
+}
+
+func TestWalkRepositoryIgnores(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".git/info/exclude":  "scratch/\n",
+		".gitignore":         "*.tmp\ngen/\n",
+		".mcpprimeignore":    "fixtures\n!gen/\n",
+		"main.go":            "package main\n",
+		"cache.tmp":          "",
+		"gen/types.go":       "package gen\n",
+		"fixtures/big.bin":   "",
+		"scratch/try.py":     "",
+		"web/.gitignore":     "!keep.tmp\n",
+		"web/keep.tmp":       "",
+		"web/other.tmp":      "",
+		"node_modules/x.js":  "",
+		"web/src/index.html": "",
+	})
+
+	var files []string
+	require.NoError(t, walkRepository(openRepo(t, root), func(relPath string) error {
+		files = append(files, relPath)
+		return nil
+	}))
+	assert.Equal(t, []string{"gen/types.go", "main.go", "web/keep.tmp", "web/src/index.html"}, files)
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	jsdocTagRE   = regexp.MustCompile(`^@([A-Za-z]+)\b\s*(.*)$`)
+	jsdocParamRE = regexp.MustCompile(`^(?:\{(.*)\}\s*)?(\[[^\]]*\]|[A-Za-z_$][\w$.]*)\s*(?:-\s*)?(.*)$`)
+)
+
+// parseJSDoc parses a JSDoc comment body (as returned by extractJSDocComment)
+// into its description and @param, @returns, @throws, @deprecated and @example tags
+func parseJSDoc(text string) docComment {
+	var doc docComment
+	var prose []string
+
+	type tag struct {
+		name  string
+		lines []string
+	}
+	var tags []tag
+
+	for _, line := range strings.Split(text, "\n") {
+		if m := jsdocTagRE.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			tags = append(tags, tag{name: m[1], lines: []string{m[2]}})
+			continue
+		}
+		if len(tags) == 0 {
+			prose = append(prose, line)
+			continue
+		}
+		tags[len(tags)-1].lines = append(tags[len(tags)-1].lines, line)
+	}
+
+	for _, t := range tags {
+		body := joinDocLines(t.lines)
+		switch t.name {
+		case "param", "arg", "argument":
+			if p, ok := parseJSDocParam(body); ok {
+				doc.Params = append(doc.Params, p)
+			}
+		case "returns", "return":
+			doc.ReturnType, doc.Returns = jsdocTypePrefix(body)
+		case "throws", "throw", "exception":
+			typ, description := jsdocTypePrefix(body)
+			doc.Raises = append(doc.Raises, docParam{Name: typ, Description: description})
+		case "deprecated":
+			doc.Deprecated = true
+			doc.DeprecationNote = body
+		case "example":
+			// Examples keep their line breaks and indentation
+			if example := strings.Trim(strings.Join(t.lines, "\n"), "\n "); example != "" {
+				doc.Examples = append(doc.Examples, example)
+			}
+		case "description", "desc":
+			prose = append(prose, t.lines...)
+		}
+	}
+
+	doc.setProse(prose)
+	return doc
+}
+
+// parseJSDocParam parses "{type} name - description", including optional
+// "[name]" and "[name=default]" forms. Nested members such as options.limit
+// are skipped as they describe a property, not a parameter.
+func parseJSDocParam(body string) (docParam, bool) {
+	typ := ""
+	if strings.HasPrefix(body, "{") {
+		end := jsMatchingBracket(body, 0)
+		if end < 0 {
+			return docParam{}, false
+		}
+		typ = strings.TrimSpace(body[1:end])
+		body = strings.TrimSpace(body[end+1:])
+	}
+
+	m := jsdocParamRE.FindStringSubmatch(body)
+	if m == nil {
+		return docParam{}, false
+	}
+
+	name := m[2]
+	if strings.HasPrefix(name, "[") {
+		name = strings.Trim(name, "[]")
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name = name[:idx]
+		}
+		if typ != "" {
+			typ += "="
+		}
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, ".") {
+		return docParam{}, false
+	}
+
+	return docParam{Name: name, Type: typ, Description: strings.TrimSpace(m[3])}, true
+}
+
+// jsdocTypePrefix splits "{type} description"
+func jsdocTypePrefix(body string) (string, string) {
+	if !strings.HasPrefix(body, "{") {
+		return "", body
+	}
+	end := jsMatchingBracket(body, 0)
+	if end < 0 {
+		return "", body
+	}
+	description := strings.TrimSpace(body[end+1:])
+	description = strings.TrimSpace(strings.TrimPrefix(description, "-"))
+	return strings.TrimSpace(body[1:end]), description
+}
+
+// jsdocTypeSchema maps a JSDoc type expression to a JSON Schema. JSDoc adds
+// a few forms to TypeScript syntax: * for any, ?T for nullable, T= for
+// optional and ...T for the arguments of a rest parameter.
+func jsdocTypeSchema(typ string, types map[string]string) map[string]interface{} {
+	typ = strings.TrimSuffix(strings.TrimSpace(typ), "=")
+	if strings.HasPrefix(typ, "...") {
+		return map[string]interface{}{"type": "array", "items": jsdocTypeSchema(typ[3:], types)}
+	}
+
+	nullable := false
+	if strings.HasPrefix(typ, "?") {
+		nullable = true
+		typ = typ[1:]
+	}
+	typ = strings.TrimPrefix(typ, "!")
+
+	var schema map[string]interface{}
+	if typ == "*" || typ == "" {
+		schema = map[string]interface{}{}
+	} else {
+		schema = tsTypeSchema(strings.NewReplacer("Array.<", "Array<", "Object.<", "Record<").Replace(typ), types, map[string]bool{})
+	}
+	if nullable {
+		schema["nullable"] = true
+	}
+	return schema
+}
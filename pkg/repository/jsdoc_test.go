@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSDoc(t *testing.T) {
+	text := `Fetches a page of users.
+
+Results are cached.
+@param {number} count - how many
+@param {string} [cursor] where to start
+@param {Object} options
+@param {boolean} options.active - nested, ignored
+@returns {Promise<User[]>} the users
+@throws {RangeError} if count is negative
+@deprecated use listUsers instead
+@example
+fetchUsers(10)
+  .then(print)
+@example fetchUsers(5, "abc")`
+
+	doc := parseJSDoc(text)
+	assert.Equal(t, "Fetches a page of users.", doc.Summary)
+	assert.Equal(t, "Results are cached.", doc.Description)
+	assert.Equal(t, []docParam{
+		{Name: "count", Type: "number", Description: "how many"},
+		{Name: "cursor", Type: "string=", Description: "where to start"},
+		{Name: "options", Type: "Object"},
+	}, doc.Params)
+	assert.Equal(t, "Promise<User[]>", doc.ReturnType)
+	assert.Equal(t, "the users", doc.Returns)
+	assert.Equal(t, []docParam{{Name: "RangeError", Description: "if count is negative"}}, doc.Raises)
+	assert.True(t, doc.Deprecated)
+	assert.Equal(t, "use listUsers instead", doc.DeprecationNote)
+	assert.Equal(t, []string{"fetchUsers(10)\n  .then(print)", `fetchUsers(5, "abc")`}, doc.Examples)
+}
+
+func TestJSDocTypeSchema(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{}, jsdocTypeSchema("*", nil))
+	assert.Equal(t, map[string]interface{}{"type": "number", "nullable": true}, jsdocTypeSchema("?number", nil))
+	assert.Equal(t, map[string]interface{}{"type": "string"}, jsdocTypeSchema("string=", nil))
+	assert.Equal(t, map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"type": "string"},
+	}, jsdocTypeSchema("Array.<string>", nil))
+	assert.Equal(t, map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"type": "number"},
+	}, jsdocTypeSchema("...number", nil))
+}
+
+func TestJSDocRestParameter(t *testing.T) {
+	doc := parseJSDoc("@param {...number} nums the numbers")
+	parameters, required := parseJavaScriptParameters("...nums", nil, &doc)
+	assert.Empty(t, required)
+	assert.Equal(t, map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "number"},
+		"description": "the numbers",
+	}, parameters["properties"].(map[string]interface{})["nums"])
+}
+
+func TestExtractJavaScriptSignaturesJSDoc(t *testing.T) {
+	code := `
+/**
+ * Moves the cursor.
+ * @param {number} steps - how far to move
+ * @param {"up"|"down"} [direction] which way
+ * @returns {boolean} whether it moved
+ * @example move(3, "up")
+ */
+export function move(steps, direction) {}
+
+/** @deprecated */
+function old() {}
+`
+
+	signatures, err := extractJavaScriptSignatures(code)
+	require.NoError(t, err)
+	require.Len(t, signatures, 2)
+
+	move := signatures[0]
+	assert.Equal(t, "Moves the cursor.", move.Description)
+	assert.Equal(t, []string{"steps"}, move.Required)
+	assert.Equal(t, "boolean: whether it moved", move.Returns)
+	assert.Equal(t, []string{`move(3, "up")`}, move.Examples)
+	assert.False(t, move.Deprecated)
+
+	props := move.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "number", "description": "how far to move"}, props["steps"])
+	assert.Equal(t, map[string]interface{}{
+		"type":        "string",
+		"enum":        []interface{}{"up", "down"},
+		"description": "which way",
+	}, props["direction"])
+
+	assert.True(t, signatures[1].Deprecated)
+}
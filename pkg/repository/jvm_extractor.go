@@ -0,0 +1,627 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// jvmSource is Java or Kotlin code prepared for scanning
+type jvmSource struct {
+	*braceSource
+	kotlin bool
+}
+
+// jvmDecl is a Java or Kotlin declaration
+type jvmDecl struct {
+	Kind        string // "class", "interface", "enum", "record", "object", "annotation", "method", "constructor", "property", "typealias", ...
+	Name        string
+	Modifiers   []string
+	Annotations []string // annotation text without the @, e.g. `Deprecated(since = "2")`
+	Header      string   // declaration without annotations or body, whitespace collapsed
+	Params      string   // text between the parameter parentheses, or the record or primary constructor components
+	HasParams   bool
+	Hidden      bool   // Kotlin primary constructor declared private, protected or internal
+	Returns     string // declared return type, or the aliased type of a typealias
+	Doc         string
+	Line        int
+	Body        [2]int // offsets of the block contents; both zero when there is no block
+}
+
+// jvmParam is a method, record or primary constructor parameter
+type jvmParam struct {
+	Name       string
+	Type       string
+	Default    string // Kotlin default value
+	Vararg     bool
+	Property   bool // Kotlin val/var constructor parameter
+	Nullable   bool // annotated @Nullable
+	Private    bool // Kotlin private, protected or internal constructor property
+	HasDefault bool
+}
+
+var (
+	jvmJavaModifiers = map[string]bool{
+		"public": true, "protected": true, "private": true, "static": true, "final": true,
+		"abstract": true, "default": true, "synchronized": true, "native": true, "strictfp": true,
+		"transient": true, "volatile": true, "sealed": true, "non-sealed": true,
+	}
+	jvmKotlinModifiers = map[string]bool{
+		"public": true, "protected": true, "private": true, "internal": true, "open": true,
+		"override": true, "abstract": true, "final": true, "data": true, "inner": true,
+		"sealed": true, "enum": true, "annotation": true, "companion": true, "suspend": true,
+		"inline": true, "infix": true, "operator": true, "tailrec": true, "external": true,
+		"lateinit": true, "const": true, "value": true, "expect": true, "actual": true,
+		"noinline": true, "crossinline": true, "vararg": true,
+	}
+
+	jvmIdentRE          = regexp.MustCompile(`^[A-Za-z_$][\w$]*`)
+	jvmTrailingIdentRE  = regexp.MustCompile(`([A-Za-z_$][\w$]*)\s*$`)
+	jvmInlineTagRE      = regexp.MustCompile(`\{@(code|literal|link|linkplain|value)\s+([^}]*)\}`)
+	jvmHTMLTagRE        = regexp.MustCompile(`(?i)</?(?:b|i|em|strong|code|tt|pre|ul|ol|li|br|p)\s*/?>`)
+	jvmParagraphRE      = regexp.MustCompile(`(?i)\s*<p\s*/?>\s*`)
+	jvmKDocLinkRE       = regexp.MustCompile(`\[([A-Za-z_][\w.#]*)\]`)
+	jvmJavaDeclKeywords = []string{"class", "interface", "enum", "record", "@interface"}
+)
+
+func newJavaSource(text string) *jvmSource {
+	return &jvmSource{braceSource: newBraceSource(text, quotedLiteralEnd, false)}
+}
+
+// newKotlinSource prepares Kotlin code, whose block comments nest
+func newKotlinSource(text string) *jvmSource {
+	return &jvmSource{braceSource: newBraceSource(text, quotedLiteralEnd, true), kotlin: true}
+}
+
+// decls parses the declarations between start and end
+func (s *jvmSource) decls(start, end int) []jvmDecl {
+	var decls []jvmDecl
+	for _, d := range s.declarations(start, end, s.kotlin) {
+		decl := s.parseDecl(d.Start, d.HeaderEnd)
+		decl.Doc = d.Doc
+		decl.Body = d.Body
+		decls = append(decls, decl)
+	}
+	return decls
+}
+
+// parseDecl classifies the header between start and end
+func (s *jvmSource) parseDecl(start, end int) jvmDecl {
+	decl := jvmDecl{Line: s.lineAt(start)}
+
+	// Leading annotations, e.g. @Deprecated or @RequestMapping(path = "/x")
+	pos := start
+	for {
+		for pos < end && strings.IndexByte(" \t\r\n", s.masked[pos]) >= 0 {
+			pos++
+		}
+		if pos >= end || s.masked[pos] != '@' || strings.HasPrefix(s.masked[pos:end], "@interface") {
+			break
+		}
+		nameEnd := pos + 1
+		for nameEnd < end && (braceIsIdentByte(s.masked[nameEnd]) || s.masked[nameEnd] == '.' || s.masked[nameEnd] == ':') {
+			nameEnd++
+		}
+		if nameEnd < end && s.masked[nameEnd] == '(' {
+			nameEnd = braceMatchingBracket(s.masked, nameEnd, end) + 1
+		}
+		decl.Annotations = append(decl.Annotations, strings.Join(strings.Fields(s.code[pos+1:nameEnd]), " "))
+		pos = nameEnd
+	}
+
+	rest := strings.Join(strings.Fields(s.code[pos:end]), " ")
+	modifiers := jvmJavaModifiers
+	if s.kotlin {
+		modifiers = jvmKotlinModifiers
+	}
+	for {
+		word, after, _ := strings.Cut(rest, " ")
+		if !modifiers[word] {
+			break
+		}
+		decl.Modifiers = append(decl.Modifiers, word)
+		rest = after
+	}
+	decl.Header = strings.TrimSpace(strings.Join(append(append([]string{}, decl.Modifiers...), rest), " "))
+
+	if s.kotlin {
+		s.parseKotlinHeader(&decl, rest)
+	} else {
+		parseJavaHeader(&decl, rest)
+	}
+	return decl
+}
+
+// parseJavaHeader reads the kind, name, parameters and return type of a Java declaration
+func parseJavaHeader(decl *jvmDecl, rest string) {
+	for _, keyword := range jvmJavaDeclKeywords {
+		if !strings.HasPrefix(rest, keyword+" ") {
+			continue
+		}
+		decl.Kind = strings.TrimPrefix(keyword, "@")
+		if decl.Kind == "interface" && keyword == "@interface" {
+			decl.Kind = "annotation"
+		}
+		after := strings.TrimSpace(rest[len(keyword):])
+		decl.Name = jvmIdentRE.FindString(after)
+		if decl.Kind == "record" {
+			jvmComponents(decl, after[len(decl.Name):])
+		}
+		return
+	}
+
+	if strings.HasPrefix(rest, "package ") || strings.HasPrefix(rest, "import ") {
+		decl.Kind = strings.SplitN(rest, " ", 2)[0]
+		return
+	}
+
+	open := braceScanTo(rest, "(")
+	eq := braceScanTo(rest, "=")
+	if open < 0 || (eq >= 0 && eq < open) {
+		decl.Kind = "field"
+		return
+	}
+
+	before := strings.TrimSpace(rest[:open])
+	if strings.HasPrefix(before, "<") {
+		before = strings.TrimSpace(before[braceMatchingBracket(before, 0, len(before))+1:])
+	}
+	m := jvmTrailingIdentRE.FindStringSubmatch(before)
+	if m == nil {
+		return
+	}
+	decl.Name = m[1]
+	decl.Returns = strings.TrimSpace(before[:len(before)-len(m[0])])
+	decl.Kind = "method"
+	if decl.Returns == "" {
+		decl.Kind = "constructor"
+	}
+
+	closing := braceMatchingBracket(rest, open, len(rest))
+	decl.Params = rest[open+1 : closing]
+	decl.HasParams = true
+}
+
+// parseKotlinHeader reads the kind, name, parameters and return type of a Kotlin declaration
+func (s *jvmSource) parseKotlinHeader(decl *jvmDecl, rest string) {
+	keyword, after, _ := strings.Cut(rest, " ")
+	if keyword == "fun" && strings.HasPrefix(after, "interface ") {
+		keyword, after, _ = strings.Cut(after, " ")
+	}
+	after = strings.TrimSpace(after)
+
+	switch keyword {
+	case "class", "interface", "object":
+		decl.Kind = keyword
+		decl.Name = jvmIdentRE.FindString(after)
+		if keyword == "object" && decl.Name == "" {
+			decl.Name = "Companion"
+		}
+		if keyword == "class" {
+			jvmComponents(decl, after[len(decl.Name):])
+		}
+	case "fun":
+		decl.Kind = "method"
+		if strings.HasPrefix(after, "<") {
+			after = strings.TrimSpace(after[braceMatchingBracket(after, 0, len(after))+1:])
+		}
+		open := braceScanTo(after, "(")
+		if open < 0 {
+			return
+		}
+		// Extension functions are named without their receiver: fun String.shout() is shout
+		m := jvmTrailingIdentRE.FindStringSubmatch(after[:open])
+		if m == nil {
+			return
+		}
+		decl.Name = m[1]
+		closing := braceMatchingBracket(after, open, len(after))
+		decl.Params = after[open+1 : closing]
+		decl.HasParams = true
+
+		tail := strings.TrimSpace(after[closing+1:])
+		if idx := braceScanTo(tail, "="); idx >= 0 {
+			// Expression bodies are not part of the signature
+			decl.Header = strings.TrimSpace(strings.TrimSuffix(decl.Header, tail) + tail[:idx])
+			tail = tail[:idx]
+		}
+		if idx := braceScanTo(tail, " where "); idx >= 0 {
+			tail = tail[:idx]
+		}
+		if strings.HasPrefix(tail, ":") {
+			decl.Returns = strings.TrimSpace(tail[1:])
+		}
+	case "constructor":
+		decl.Kind = "constructor"
+		if open := braceScanTo(rest, "("); open >= 0 {
+			decl.Params = rest[open+1 : braceMatchingBracket(rest, open, len(rest))]
+			decl.HasParams = true
+		}
+	case "val", "var":
+		decl.Kind = "property"
+	case "typealias":
+		decl.Kind = "typealias"
+		decl.Name = jvmIdentRE.FindString(after)
+		if idx := strings.Index(after, "="); idx >= 0 {
+			decl.Returns = strings.TrimSpace(after[idx+1:])
+		}
+	case "package", "import", "init":
+		decl.Kind = keyword
+	}
+}
+
+// jvmComponents reads the components of a record or the parameters of a
+// Kotlin primary constructor following the type name
+func jvmComponents(decl *jvmDecl, after string) {
+	after = strings.TrimSpace(after)
+	if strings.HasPrefix(after, "<") {
+		after = strings.TrimSpace(after[braceMatchingBracket(after, 0, len(after))+1:])
+	}
+	open := braceScanTo(after, "(")
+	if open < 0 {
+		return
+	}
+	// Only modifiers and annotations, as in "private constructor(", may precede the parameters
+	before := strings.TrimSpace(after[:open])
+	if before != "" && !strings.HasSuffix(before, "constructor") {
+		return
+	}
+	for _, modifier := range strings.Fields(before) {
+		switch modifier {
+		case "private", "protected", "internal":
+			decl.Hidden = true
+		}
+	}
+	decl.Params = after[open+1 : braceMatchingBracket(after, open, len(after))]
+	decl.HasParams = true
+}
+
+// parseJVMParams parses a Java or Kotlin parameter list
+func parseJVMParams(text string, kotlin bool) []jvmParam {
+	var params []jvmParam
+	masked := newBraceSource(text, quotedLiteralEnd, kotlin).masked
+	for i, part := range splitBraceMasked(text, masked, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var p jvmParam
+		// Annotations and modifiers
+		for {
+			part = strings.TrimSpace(part)
+			if strings.HasPrefix(part, "@") {
+				end := 1
+				for end < len(part) && (braceIsIdentByte(part[end]) || part[end] == '.' || part[end] == ':') {
+					end++
+				}
+				name := part[1:end]
+				if end < len(part) && part[end] == '(' {
+					end = braceMatchingBracket(part, end, len(part)) + 1
+				}
+				if strings.HasSuffix(name, "Nullable") {
+					p.Nullable = true
+				}
+				part = part[end:]
+				continue
+			}
+			word, after, _ := strings.Cut(part, " ")
+			switch {
+			case word == "final":
+			case word == "vararg":
+				p.Vararg = true
+			case kotlin && (word == "val" || word == "var"):
+				p.Property = true
+			case kotlin && (word == "private" || word == "protected" || word == "internal"):
+				p.Private = true
+			case kotlin && jvmKotlinModifiers[word]:
+			default:
+				goto parsed
+			}
+			part = after
+		}
+	parsed:
+
+		if kotlin {
+			if idx := braceScanTo(part, "="); idx >= 0 {
+				p.Default = strings.TrimSpace(part[idx+1:])
+				p.HasDefault = true
+				part = strings.TrimSpace(part[:idx])
+			}
+			name, typ, _ := strings.Cut(part, ":")
+			p.Name, p.Type = strings.TrimSpace(name), strings.TrimSpace(typ)
+		} else {
+			m := jvmTrailingIdentRE.FindStringSubmatch(part)
+			if m == nil {
+				continue
+			}
+			p.Name, p.Type = m[1], strings.TrimSpace(part[:len(part)-len(m[0])])
+			if strings.HasSuffix(p.Type, "...") {
+				p.Type = strings.TrimSpace(strings.TrimSuffix(p.Type, "..."))
+				p.Vararg = true
+			}
+		}
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("arg%d", i)
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// jvmDocText turns Javadoc or KDoc markup into plain text for parseJSDoc
+func jvmDocText(text string) string {
+	text = jvmInlineTagRE.ReplaceAllStringFunc(text, func(tag string) string {
+		m := jvmInlineTagRE.FindStringSubmatch(tag)
+		body := strings.TrimSpace(m[2])
+		if m[1] == "code" || m[1] == "literal" {
+			return body
+		}
+		// {@link Foo#bar label} reads as its label
+		if _, label, ok := strings.Cut(body, " "); ok {
+			return strings.TrimSpace(label)
+		}
+		return strings.TrimPrefix(body, "#")
+	})
+	text = jvmParagraphRE.ReplaceAllString(text, "\n\n")
+	text = jvmHTMLTagRE.ReplaceAllString(text, "")
+	text = jvmKDocLinkRE.ReplaceAllString(text, "$1")
+	// KDoc documents primary constructor properties with @property
+	return strings.ReplaceAll(text, "@property ", "@param ")
+}
+
+// parseJVMDoc parses a Javadoc or KDoc comment. Unlike JSDoc, @throws names
+// the exception type without braces.
+func parseJVMDoc(text string) docComment {
+	doc := parseJSDoc(jvmDocText(text))
+	for i, raised := range doc.Raises {
+		if raised.Name == "" {
+			name, description, _ := strings.Cut(raised.Description, " ")
+			doc.Raises[i] = docParam{Name: name, Description: strings.TrimSpace(description)}
+		}
+	}
+	return doc
+}
+
+// jvmAnnotated reports whether a declaration carries the named annotation
+func jvmAnnotated(decl jvmDecl, name string) bool {
+	for _, annotation := range decl.Annotations {
+		annotation = strings.SplitN(annotation, "(", 2)[0]
+		if annotation == name || strings.HasSuffix(annotation, "."+name) {
+			return true
+		}
+	}
+	return false
+}
+
+func jvmHasModifier(decl jvmDecl, modifier string) bool {
+	for _, m := range decl.Modifiers {
+		if m == modifier {
+			return true
+		}
+	}
+	return false
+}
+
+// public reports whether a declaration is part of the public API. Java
+// members are package-private unless declared public, except in interfaces;
+// Kotlin declarations are public unless declared otherwise.
+func (s *jvmSource) public(decl jvmDecl, inInterface bool) bool {
+	if s.kotlin || inInterface {
+		return !jvmHasModifier(decl, "private") && !jvmHasModifier(decl, "protected") && !jvmHasModifier(decl, "internal")
+	}
+	return jvmHasModifier(decl, "public")
+}
+
+// extractJavaSignatures extracts public classes, interfaces, enums, records
+// and methods from Java source code
+func extractJavaSignatures(code string) ([]FunctionSignature, error) {
+	return extractJVMSignatures(newJavaSource(code))
+}
+
+// extractKotlinSignatures extracts public classes, objects, interfaces and
+// functions from Kotlin source code
+func extractKotlinSignatures(code string) ([]FunctionSignature, error) {
+	return extractJVMSignatures(newKotlinSource(code))
+}
+
+func extractJVMSignatures(src *jvmSource) ([]FunctionSignature, error) {
+	decls := src.decls(0, len(src.text))
+	types := collectJVMTypes(src, decls)
+
+	var signatures []FunctionSignature
+	src.appendSignatures(&signatures, decls, "", false, false, types)
+	return signatures, nil
+}
+
+// appendSignatures adds the public declarations, named Class#method and
+// Outer.Inner for nested types. owner is the enclosing type, if any; static
+// marks the members of Kotlin objects and companion objects.
+func (s *jvmSource) appendSignatures(signatures *[]FunctionSignature, decls []jvmDecl, owner string, inInterface, static bool, types map[string]jvmTypeDecl) {
+	for _, decl := range decls {
+		if !s.public(decl, inInterface) || jvmHasModifier(decl, "annotation") {
+			continue
+		}
+
+		switch decl.Kind {
+		case "class", "interface", "enum", "record", "object":
+			name := decl.Name
+			if owner != "" {
+				name = owner + "." + decl.Name
+			}
+			// Companion object members belong to the enclosing class
+			if decl.Kind == "object" && jvmHasModifier(decl, "companion") && owner != "" {
+				name = owner
+			} else {
+				kind := decl.Kind
+				switch {
+				case jvmHasModifier(decl, "enum"):
+					kind = "enum"
+				case kind == "record" || kind == "object":
+					kind = "class"
+				}
+				sig := s.declSignature(decl, name, kind)
+				if decl.HasParams {
+					s.setParameters(&sig, decl, types, true)
+					// Classes whose constructor declares no properties have no fields to describe
+					if props, _ := sig.Parameters["properties"].(map[string]interface{}); len(props) == 0 {
+						sig.Parameters, sig.Required = nil, nil
+					}
+				}
+				*signatures = append(*signatures, sig)
+
+				// Records and Kotlin primary constructors declare the constructor in the header
+				if decl.HasParams && s.constructible(decl) && (decl.Kind == "record" || s.kotlin && decl.Kind == "class") {
+					ctor := s.declSignature(decl, name+"#"+decl.Name, "constructor")
+					ctor.Class = name
+					s.setParameters(&ctor, decl, types, false)
+					*signatures = append(*signatures, ctor)
+				}
+			}
+			if decl.Body != [2]int{} {
+				start, _ := s.enumConstants(decl)
+				members := static || decl.Kind == "object"
+				s.appendSignatures(signatures, s.decls(start, decl.Body[1]), name, decl.Kind == "interface", members, types)
+			}
+		case "method":
+			name := decl.Name
+			kind := "function"
+			if owner != "" {
+				name = owner + "#" + decl.Name
+				kind = "method"
+			}
+			sig := s.declSignature(decl, name, kind)
+			if owner != "" {
+				sig.Class = owner
+				sig.Static = static || jvmHasModifier(decl, "static")
+			}
+			s.setParameters(&sig, decl, types, false)
+			*signatures = append(*signatures, sig)
+		case "constructor":
+			if owner == "" || static {
+				continue
+			}
+			// Java constructors are named after the class, Kotlin secondary constructors are not named
+			simple := owner[strings.LastIndex(owner, ".")+1:]
+			sig := s.declSignature(decl, owner+"#"+simple, "constructor")
+			sig.Class = owner
+			s.setParameters(&sig, decl, types, false)
+			*signatures = append(*signatures, sig)
+		}
+	}
+}
+
+// constructible reports whether the constructors of a class can be called
+// from outside: enum, abstract and sealed classes cannot be instantiated
+// directly, and Kotlin primary constructors may be hidden.
+func (s *jvmSource) constructible(decl jvmDecl) bool {
+	return !decl.Hidden && decl.Kind != "enum" && !jvmHasModifier(decl, "enum") &&
+		!jvmHasModifier(decl, "abstract") && !jvmHasModifier(decl, "sealed")
+}
+
+// enumConstants returns where the members of a type body start, after any
+// enum constants, and the names of the constants
+func (s *jvmSource) enumConstants(decl jvmDecl) (int, []string) {
+	start, end := decl.Body[0], decl.Body[1]
+	if decl.Kind != "enum" && !jvmHasModifier(decl, "enum") {
+		return start, nil
+	}
+
+	// The constants run up to the first top-level semicolon
+	membersStart := end
+	depth := 0
+	for i := start; i < end; i++ {
+		switch s.masked[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ';':
+			if depth == 0 {
+				membersStart = i + 1
+				i = end
+			}
+		}
+	}
+
+	var constants []string
+	for _, part := range splitBraceMasked(s.code[start:min(membersStart, end)], s.masked[start:min(membersStart, end)], ',') {
+		part = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(part), ";"))
+		for strings.HasPrefix(part, "@") {
+			end := 1
+			for end < len(part) && (braceIsIdentByte(part[end]) || part[end] == '.') {
+				end++
+			}
+			if end < len(part) && part[end] == '(' {
+				end = braceMatchingBracket(part, end, len(part)) + 1
+			}
+			part = strings.TrimSpace(part[end:])
+		}
+		if name := jvmIdentRE.FindString(part); name != "" {
+			constants = append(constants, name)
+		}
+	}
+	return min(membersStart, end), constants
+}
+
+// declSignature fills the fields shared by every kind of declaration
+func (s *jvmSource) declSignature(decl jvmDecl, name, kind string) FunctionSignature {
+	doc := parseJVMDoc(decl.Doc)
+	return FunctionSignature{
+		Name:        name,
+		Type:        kind,
+		Signature:   decl.Header,
+		Description: doc.String(),
+		Returns:     docReturns(doc),
+		Raises:      docRaises(doc),
+		Deprecated:  doc.Deprecated || jvmAnnotated(decl, "Deprecated"),
+		Examples:    doc.Examples,
+		Line:        decl.Line,
+	}
+}
+
+// setParameters maps the parameters of a method, record or primary
+// constructor. With properties set only Kotlin val/var parameters are kept,
+// as only those are properties of the class.
+func (s *jvmSource) setParameters(sig *FunctionSignature, decl jvmDecl, types map[string]jvmTypeDecl, properties bool) {
+	doc := parseJVMDoc(decl.Doc)
+
+	props := make(map[string]interface{})
+	required := []string{}
+	for _, p := range parseJVMParams(decl.Params, s.kotlin) {
+		if properties && s.kotlin && (!p.Property || p.Private) {
+			continue
+		}
+
+		schema := jvmTypeSchema(p.Type, types, map[string]bool{})
+		if p.Vararg {
+			schema = map[string]interface{}{"type": "array", "items": schema}
+		}
+		if p.Nullable {
+			schema["nullable"] = true
+		}
+		if value, ok := parseJSLiteral(p.Default); ok && p.HasDefault {
+			schema["default"] = value
+		}
+
+		description := fmt.Sprintf("Parameter %s", p.Name)
+		if dp, ok := doc.Param(p.Name); ok && dp.Description != "" {
+			description = dp.Description
+		}
+		schema["description"] = description
+		props[p.Name] = schema
+
+		if !p.Vararg && !p.HasDefault && !p.Nullable {
+			required = append(required, p.Name)
+		}
+	}
+
+	sig.Parameters = map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+	sig.Required = required
+	if sig.Returns == "" && decl.Returns != "" && decl.Returns != "void" && decl.Returns != "Unit" {
+		sig.Returns = decl.Returns
+	}
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJVMDoc(t *testing.T) {
+	doc := parseJVMDoc("Finds users by {@code name}.\n<p>\nSee {@link UserRepo#find the repository}.\n\n@param name the user name\n@param <T> ignored type parameter\n@return the matching {@link User}\n@throws IllegalArgumentException if name is blank")
+
+	assert.Equal(t, "Finds users by name.", doc.Summary)
+	assert.Equal(t, "See the repository.", doc.Description)
+	assert.Equal(t, []docParam{{Name: "name", Description: "the user name"}}, doc.Params)
+	assert.Equal(t, "the matching User", doc.Returns)
+	assert.Equal(t, []docParam{{Name: "IllegalArgumentException", Description: "if name is blank"}}, doc.Raises)
+}
+
+func TestExtractJavaSignatures(t *testing.T) {
+	code := `package com.example;
+
+import java.util.*;
+
+/**
+ * Manages users.
+ */
+@Service
+public class UserService {
+    private final Map<String, User> users = new HashMap<>();
+
+    public UserService(Repo repo) {}
+
+    /**
+     * Finds users.
+     *
+     * @param name the user name
+     * @return the matching users
+     */
+    public List<User> find(@NotNull String name, int limit) throws IOException {
+        String brace = "}";
+        return List.of();
+    }
+
+    @Deprecated
+    public static <K> Optional<K> lookup(K key, String... tags) { return Optional.empty(); }
+
+    void packagePrivate() {}
+
+    private void secret() {}
+
+    public enum Status { ACTIVE, DISABLED("off") { void f() {} }; public String label() { return ""; } }
+
+    public record Point(int x, @Nullable Double y) {}
+
+    public interface Listener {
+        void onChange(Status status, Point p);
+    }
+}
+
+class Hidden {
+    public void nope() {}
+}
+`
+
+	signatures, err := extractJavaSignatures(code)
+	require.NoError(t, err)
+
+	var names []string
+	for _, sig := range signatures {
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{
+		"UserService", "UserService#UserService", "UserService#find", "UserService#lookup", "UserService.Status",
+		"UserService.Status#label", "UserService.Point", "UserService.Point#Point", "UserService.Listener",
+		"UserService.Listener#onChange",
+	}, names)
+
+	service := signatures[0]
+	assert.Equal(t, "class", service.Type)
+	assert.Equal(t, "Manages users.", service.Description)
+	assert.Equal(t, "public class UserService", service.Signature)
+	assert.Equal(t, 8, service.Line)
+
+	ctor := signatures[1]
+	assert.Equal(t, "constructor", ctor.Type)
+	assert.Equal(t, "UserService", ctor.Class)
+	assert.Equal(t, []string{"repo"}, ctor.Required)
+
+	find := signatures[2]
+	assert.Equal(t, "method", find.Type)
+	assert.Equal(t, "UserService", find.Class)
+	assert.False(t, find.Static)
+	assert.Equal(t, "public List<User> find(@NotNull String name, int limit) throws IOException", find.Signature)
+	assert.Equal(t, []string{"name", "limit"}, find.Required)
+	assert.Equal(t, "the matching users", find.Returns)
+	props := find.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "the user name"}, props["name"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "description": "Parameter limit"}, props["limit"])
+
+	lookup := signatures[3]
+	assert.True(t, lookup.Deprecated)
+	assert.True(t, lookup.Static)
+	assert.Equal(t, []string{"key"}, lookup.Required)
+	assert.Equal(t, "array", lookup.Parameters["properties"].(map[string]interface{})["tags"].(map[string]interface{})["type"])
+
+	assert.Equal(t, "enum", signatures[4].Type)
+
+	point := signatures[6]
+	assert.Equal(t, []string{"x"}, point.Required)
+	pointCtor := signatures[7]
+	assert.Equal(t, "constructor", pointCtor.Type)
+	assert.Equal(t, "UserService.Point", pointCtor.Class)
+	assert.Equal(t, []string{"x"}, pointCtor.Required)
+
+	onChange := signatures[9]
+	assert.Equal(t, "UserService.Listener", onChange.Class)
+	onChangeProps := onChange.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"ACTIVE", "DISABLED"}, onChangeProps["status"].(map[string]interface{})["enum"])
+	assert.Equal(t, "object", onChangeProps["p"].(map[string]interface{})["type"])
+}
+
+func TestExtractKotlinSignatures(t *testing.T) {
+	code := `package com.example
+
+/**
+ * A user.
+ *
+ * @property id unique id
+ */
+data class User(val id: Long, val name: String = "anon", private val secret: String)
+
+enum class Role { ADMIN, GUEST }
+
+/** Greets [name]. */
+fun greet(name: String, times: Int = 1): String = "hi $name".repeat(times)
+
+internal fun hidden() {}
+
+class Service(private val repo: Repo) {
+    /**
+     * Loads a user.
+     * @param id the id
+     */
+    suspend fun load(id: Long, role: Role?): User? {
+        return null
+    }
+
+    private fun nope() {}
+
+    fun sum(vararg values: Int) = values.sum()
+
+    companion object {
+        fun create(): Service = Service(Repo())
+    }
+}
+
+interface Store {
+    fun save(user: User)
+    fun count(): Int
+}
+`
+
+	signatures, err := extractKotlinSignatures(code)
+	require.NoError(t, err)
+
+	var names []string
+	for _, sig := range signatures {
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{
+		"User", "User#User", "Role", "greet", "Service", "Service#Service", "Service#load", "Service#sum",
+		"Service#create", "Store", "Store#save", "Store#count",
+	}, names)
+
+	user := signatures[0]
+	assert.Equal(t, "A user.", user.Description)
+	assert.Equal(t, []string{"id"}, user.Required)
+	userProps := user.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "integer", "description": "unique id"}, userProps["id"])
+	assert.Equal(t, "anon", userProps["name"].(map[string]interface{})["default"])
+	assert.NotContains(t, userProps, "secret")
+
+	userCtor := signatures[1]
+	assert.Equal(t, "constructor", userCtor.Type)
+	assert.Equal(t, "User", userCtor.Class)
+	assert.Equal(t, []string{"id", "secret"}, userCtor.Required)
+
+	assert.Equal(t, "enum", signatures[2].Type)
+
+	greet := signatures[3]
+	assert.Equal(t, "function", greet.Type)
+	assert.Equal(t, "fun greet(name: String, times: Int = 1): String", greet.Signature)
+	assert.Equal(t, "Greets name.", greet.Description)
+	assert.Equal(t, []string{"name"}, greet.Required)
+	assert.Equal(t, int64(1), greet.Parameters["properties"].(map[string]interface{})["times"].(map[string]interface{})["default"])
+
+	assert.Nil(t, signatures[4].Parameters)
+	assert.Equal(t, []string{"repo"}, signatures[5].Required)
+
+	load := signatures[6]
+	assert.Equal(t, "User?", load.Returns)
+	role := load.Parameters["properties"].(map[string]interface{})["role"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"ADMIN", "GUEST"}, role["enum"])
+	assert.Equal(t, true, role["nullable"])
+
+	assert.Empty(t, signatures[7].Required)
+	assert.False(t, signatures[7].Static)
+	create := signatures[8]
+	assert.Equal(t, "Service", create.Class)
+	assert.True(t, create.Static)
+	assert.Equal(t, "Int", signatures[11].Returns)
+}
+
+func TestExtractKotlinStringDefaultWithComma(t *testing.T) {
+	signatures, err := extractKotlinSignatures(`fun f(x: Int, y: String = "a,b", z: Char = ',') {}`)
+	require.NoError(t, err)
+	require.Len(t, signatures, 1)
+
+	f := signatures[0]
+	assert.Equal(t, []string{"x"}, f.Required)
+	props := f.Parameters["properties"].(map[string]interface{})
+	assert.Len(t, props, 3)
+	assert.Equal(t, "a,b", props["y"].(map[string]interface{})["default"])
+	assert.Equal(t, ",", props["z"].(map[string]interface{})["default"])
+}
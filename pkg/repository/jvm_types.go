@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"strings"
+)
+
+// jvmTypeDecl is a class, enum or type alias declared in the source
+type jvmTypeDecl struct {
+	Constants []interface{} // enum constants
+	Params    *jvmDecl      // record or primary constructor components
+	Alias     string        // Kotlin typealias
+	Kotlin    bool
+}
+
+// collectJVMTypes indexes the enums, records, classes with primary
+// constructors and type aliases declared in the source by simple name
+func collectJVMTypes(src *jvmSource, decls []jvmDecl) map[string]jvmTypeDecl {
+	types := make(map[string]jvmTypeDecl)
+
+	var collect func(decls []jvmDecl)
+	collect = func(decls []jvmDecl) {
+		for _, decl := range decls {
+			switch decl.Kind {
+			case "class", "interface", "enum", "record", "object":
+				typ := jvmTypeDecl{Kotlin: src.kotlin}
+				start := decl.Body[0]
+				if decl.Body != [2]int{} {
+					var constants []string
+					start, constants = src.enumConstants(decl)
+					for _, c := range constants {
+						typ.Constants = append(typ.Constants, c)
+					}
+				}
+				if decl.HasParams {
+					typ.Params = &decl
+				}
+				types[decl.Name] = typ
+				if decl.Body != [2]int{} {
+					collect(src.decls(start, decl.Body[1]))
+				}
+			case "typealias":
+				types[decl.Name] = jvmTypeDecl{Alias: decl.Returns}
+			}
+		}
+	}
+	collect(decls)
+
+	return types
+}
+
+// jvmGenericArgs splits "List<T>" into its simple name and type arguments
+func jvmGenericArgs(typ string) (string, []string) {
+	name := typ
+	var args []string
+	if open := strings.IndexByte(typ, '<'); open >= 0 && strings.HasSuffix(typ, ">") {
+		name = typ[:open]
+		for _, arg := range splitBraceTopLevel(typ[open+1:len(typ)-1], ',') {
+			if arg = strings.TrimSpace(arg); arg != "" {
+				args = append(args, arg)
+			}
+		}
+	}
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return strings.TrimSpace(name), args
+}
+
+// jvmTypeSchema maps a Java or Kotlin type to a JSON Schema, following
+// Jackson's default representation. seen guards against infinite recursion
+// through self-referencing types.
+func jvmTypeSchema(typ string, types map[string]jvmTypeDecl, seen map[string]bool) map[string]interface{} {
+	typ = strings.TrimSpace(typ)
+
+	// Type annotations, wildcards and declaration-site variance
+	for strings.HasPrefix(typ, "@") {
+		end := 1
+		for end < len(typ) && (braceIsIdentByte(typ[end]) || typ[end] == '.') {
+			end++
+		}
+		if end < len(typ) && typ[end] == '(' {
+			end = braceMatchingBracket(typ, end, len(typ)) + 1
+		}
+		typ = strings.TrimSpace(typ[end:])
+	}
+	for _, prefix := range []string{"? extends ", "? super ", "out ", "in ", "final "} {
+		typ = strings.TrimPrefix(typ, prefix)
+	}
+	if typ == "?" || typ == "*" || typ == "" {
+		return map[string]interface{}{}
+	}
+
+	// Kotlin nullable types
+	if strings.HasSuffix(typ, "?") {
+		schema := jvmTypeSchema(strings.TrimSuffix(typ, "?"), types, seen)
+		schema["nullable"] = true
+		return schema
+	}
+	if strings.HasSuffix(typ, "[]") {
+		return map[string]interface{}{
+			"type":  "array",
+			"items": jvmTypeSchema(strings.TrimSuffix(typ, "[]"), types, seen),
+		}
+	}
+
+	name, args := jvmGenericArgs(typ)
+	switch name {
+	case "int", "long", "short", "byte", "Integer", "Long", "Short", "Byte", "BigInteger",
+		"Int", "UInt", "ULong", "UShort", "UByte", "AtomicInteger", "AtomicLong":
+		return map[string]interface{}{"type": "integer"}
+	case "float", "double", "Float", "Double", "BigDecimal", "Number":
+		return map[string]interface{}{"type": "number"}
+	case "boolean", "Boolean":
+		return map[string]interface{}{"type": "boolean"}
+	case "char", "Character", "Char", "String", "CharSequence", "UUID", "URI", "URL", "Path", "File", "Pattern", "Regex":
+		return map[string]interface{}{"type": "string"}
+	case "LocalDate":
+		return map[string]interface{}{"type": "string", "format": "date"}
+	case "Instant", "LocalDateTime", "OffsetDateTime", "ZonedDateTime", "Date", "Timestamp":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "Object", "Any", "JsonNode", "JsonElement":
+		return map[string]interface{}{}
+	case "void", "Void", "Unit", "Nothing":
+		return map[string]interface{}{"type": "null"}
+	case "IntArray", "LongArray", "ShortArray", "ByteArray":
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}}
+	case "FloatArray", "DoubleArray":
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "number"}}
+	case "BooleanArray":
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "boolean"}}
+	case "CharArray":
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}
+	case "Optional":
+		if len(args) == 1 {
+			schema := jvmTypeSchema(args[0], types, seen)
+			schema["nullable"] = true
+			return schema
+		}
+	case "OptionalInt", "OptionalLong":
+		return map[string]interface{}{"type": "integer", "nullable": true}
+	case "OptionalDouble":
+		return map[string]interface{}{"type": "number", "nullable": true}
+	case "CompletableFuture", "CompletionStage", "Future", "Mono", "Deferred", "Supplier", "Callable":
+		// Asynchronous results are described by the value they produce
+		if len(args) == 1 {
+			return jvmTypeSchema(args[0], types, seen)
+		}
+	case "List", "ArrayList", "LinkedList", "Collection", "Iterable", "Queue", "Deque", "ArrayDeque",
+		"Stream", "Flux", "Array", "MutableList", "MutableCollection", "MutableIterable", "Sequence":
+		schema := map[string]interface{}{"type": "array"}
+		if len(args) == 1 {
+			schema["items"] = jvmTypeSchema(args[0], types, seen)
+		}
+		return schema
+	case "Set", "HashSet", "LinkedHashSet", "TreeSet", "SortedSet", "NavigableSet", "EnumSet", "MutableSet":
+		schema := map[string]interface{}{"type": "array", "uniqueItems": true}
+		if len(args) == 1 {
+			schema["items"] = jvmTypeSchema(args[0], types, seen)
+		}
+		return schema
+	case "Map", "HashMap", "LinkedHashMap", "TreeMap", "SortedMap", "NavigableMap", "ConcurrentHashMap", "MutableMap":
+		schema := map[string]interface{}{"type": "object"}
+		if len(args) == 2 {
+			schema["additionalProperties"] = jvmTypeSchema(args[1], types, seen)
+		}
+		return schema
+	}
+
+	decl, ok := types[name]
+	if !ok || seen[name] {
+		// Type parameters, foreign classes and recursive references
+		if len(name) == 1 && name[0] >= 'A' && name[0] <= 'Z' {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"type": "object"}
+	}
+
+	nested := make(map[string]bool, len(seen)+1)
+	for k, v := range seen {
+		nested[k] = v
+	}
+	nested[name] = true
+
+	switch {
+	case decl.Alias != "":
+		return jvmTypeSchema(decl.Alias, types, nested)
+	case decl.Constants != nil:
+		return enumSchema(decl.Constants)
+	case decl.Params != nil:
+		properties := make(map[string]interface{})
+		required := []string{}
+		for _, p := range parseJVMParams(decl.Params.Params, decl.Kotlin) {
+			if decl.Kotlin && (!p.Property || p.Private) {
+				continue
+			}
+			schema := jvmTypeSchema(p.Type, types, nested)
+			if p.Nullable {
+				schema["nullable"] = true
+			}
+			properties[p.Name] = schema
+			if !p.HasDefault && !p.Nullable {
+				required = append(required, p.Name)
+			}
+		}
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		}
+	}
+	return map[string]interface{}{"type": "object"}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJVMTypeSchema(t *testing.T) {
+	types := map[string]jvmTypeDecl{
+		"Color": {Constants: []interface{}{"RED", "GREEN"}},
+		"Ids":   {Alias: "List<Long>"},
+	}
+
+	tests := []struct {
+		name     string
+		typeText string
+		expected map[string]interface{}
+	}{
+		{"int", "int", map[string]interface{}{"type": "integer"}},
+		{"boxed long", "java.lang.Long", map[string]interface{}{"type": "integer"}},
+		{"double", "double", map[string]interface{}{"type": "number"}},
+		{"boolean", "Boolean", map[string]interface{}{"type": "boolean"}},
+		{"string", "String", map[string]interface{}{"type": "string"}},
+		{"annotated", "@NotNull String", map[string]interface{}{"type": "string"}},
+		{"kotlin nullable", "Int?", map[string]interface{}{"type": "integer", "nullable": true}},
+		{"array", "String[]", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}},
+		{"list", "List<? extends Number>", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "number"},
+		}},
+		{"set", "Set<String>", map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"uniqueItems": true,
+		}},
+		{"map", "Map<String, List<Integer>>", map[string]interface{}{
+			"type": "object",
+			"additionalProperties": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "integer"},
+			},
+		}},
+		{"optional", "Optional<String>", map[string]interface{}{"type": "string", "nullable": true}},
+		{"future", "CompletableFuture<Boolean>", map[string]interface{}{"type": "boolean"}},
+		{"date", "LocalDate", map[string]interface{}{"type": "string", "format": "date"}},
+		{"int array", "IntArray", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "integer"},
+		}},
+		{"enum", "Color", map[string]interface{}{"type": "string", "enum": []interface{}{"RED", "GREEN"}}},
+		{"alias", "Ids", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "integer"},
+		}},
+		{"any", "Any", map[string]interface{}{}},
+		{"type parameter", "T", map[string]interface{}{}},
+		{"foreign class", "HttpClient", map[string]interface{}{"type": "object"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, jvmTypeSchema(tc.typeText, types, map[string]bool{}))
+		})
+	}
+}
@@ -0,0 +1,474 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultLiveTimeout is how long a served function may run per call
+	DefaultLiveTimeout = 30 * time.Second
+	// DefaultLiveMaxOutput is the largest result a served function may
+	// return, in bytes
+	DefaultLiveMaxOutput = 1 << 20
+	// liveStderrLimit is how much of an interpreter's stderr is kept to
+	// explain a failed call
+	liveStderrLimit = 4096
+)
+
+// LiveOptions configures the tools LiveTools builds. Served functions run
+// repository code, so they must only be enabled on request.
+type LiveOptions struct {
+	Python    string        // command running Python, e.g. "python3" or "uv run python"; "python3" when empty
+	Node      string        // command running JavaScript; "node" when empty
+	Timeout   time.Duration // limit of each call; DefaultLiveTimeout when zero
+	MaxOutput int           // largest result accepted, in bytes; DefaultLiveMaxOutput when zero
+	Include   []string      // globs of files to serve; all files when empty
+	Exclude   []string      // globs of files to skip
+	MaxFiles  int           // maximum number of source files to read; defaultRepositoryFiles when zero
+	Reserved  []string      // names of tools already registered, which served functions may not take
+}
+
+// liveCall is a served function and how to call it
+type liveCall struct {
+	Interpreter []string // command and arguments before the harness flag
+	Flag        string   // flag passing the harness script, e.g. "-c"
+	Harness     string   // script reading the request on stdin and writing the response on stdout
+	Request     func(arguments map[string]interface{}) interface{}
+}
+
+// liveResponse is what a harness writes on stdout
+type liveResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// pythonHarness imports a module, calls a function with the positional and
+// keyword arguments of the request and writes its result as JSON. Output of
+// the function itself is sent to stderr.
+const pythonHarness = `import asyncio, importlib, inspect, json, sys
+request = json.load(sys.stdin)
+sys.path[:0] = request["path"]
+stdout, sys.stdout = sys.stdout, sys.stderr
+try:
+    target = importlib.import_module(request["module"])
+    for name in request["target"].split("."):
+        target = getattr(target, name)
+    result = target(*request["args"], **request["kwargs"])
+    if inspect.isawaitable(result):
+        async def wait(awaitable):
+            return await awaitable
+        result = asyncio.run(wait(result))
+    response = json.dumps({"result": result}, default=repr)
+except Exception as error:
+    response = json.dumps({"error": "%s: %s" % (type(error).__name__, error)})
+stdout.write(response)
+stdout.flush()
+`
+
+// nodeHarness imports a module, calls a function with the arguments of the
+// request in parameter order, followed by the elements of the rest argument,
+// and writes its result as JSON. Output of the function itself is sent to
+// stderr.
+const nodeHarness = `const { pathToFileURL } = require("url");
+let input = "";
+process.stdin.setEncoding("utf8");
+process.stdin.on("data", (chunk) => { input += chunk; });
+process.stdin.on("end", async () => {
+  const write = process.stdout.write.bind(process.stdout);
+  console.log = console.info = console.debug = console.error;
+  let response;
+  try {
+    const request = JSON.parse(input);
+    const mod = await import(pathToFileURL(request.file).href);
+    const path = request.target.split(".");
+    let owner, target = !(path[0] in mod) && mod.default ? mod.default : mod;
+    for (const name of path) {
+      owner = target;
+      target = target == null ? undefined : target[name];
+    }
+    if (typeof target !== "function") {
+      throw new Error(request.target + " is not exported by " + request.file);
+    }
+    const args = request.params.map((name) => (name in request.arguments ? request.arguments[name] : undefined));
+    const rest = Array.isArray(request.arguments[request.rest]) ? request.arguments[request.rest] : [];
+    while (rest.length === 0 && args.length > 0 && args[args.length - 1] === undefined) {
+      args.pop();
+    }
+    const result = await target.apply(owner, args.concat(rest));
+    response = JSON.stringify({ result: result === undefined ? null : result });
+  } catch (error) {
+    response = JSON.stringify({ error: String(error) });
+  }
+  write(response, () => process.exit(0));
+});
+`
+
+// jsExportRE matches an ES module export statement
+var jsExportRE = regexp.MustCompile(`(?m)^\s*export\s`)
+
+// jsCall describes how a served JavaScript function is called
+type jsCall struct {
+	File   string   // absolute path of the module
+	Target string   // exported function, or Class.method for a static method
+	Params []string // parameter names in declaration order
+	Rest   string   // name of the rest parameter, whose array argument is spread after the others
+}
+
+// LiveTools builds a tool for each public Python and JavaScript function of
+// a repository on disk that runs the function in a child interpreter:
+// the arguments are sent as JSON on stdin and the result is read as JSON
+// from stdout. Calls are killed after the timeout and when their output
+// exceeds the cap. Functions that cannot be called without an object, and
+// TypeScript files, which need compiling, are reported as skipped.
+func LiveTools(ctx context.Context, repo *RepoFS, opts LiveOptions) ([]server.ServerTool, []SkippedSymbol, error) {
+	root := repo.Dir()
+	if root == "" {
+		return nil, nil, fmt.Errorf("repository %s is not a local directory, so its functions cannot be run", repo.Name())
+	}
+	python := strings.Fields(opts.Python)
+	if len(python) == 0 {
+		python = []string{"python3"}
+	}
+	node := strings.Fields(opts.Node)
+	if len(node) == 0 {
+		node = []string{"node"}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLiveTimeout
+	}
+	maxOutput := opts.MaxOutput
+	if maxOutput <= 0 {
+		maxOutput = DefaultLiveMaxOutput
+	}
+
+	files, err := extractSignatureFiles(ctx, repo, opts.Include, opts.Exclude, opts.MaxFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g := &generator{Repo: repo, Aliases: make(map[string]string), used: make(map[string]bool)}
+	skipped := []SkippedSymbol{}
+	var symbols []servedSymbol
+	var calls []liveCall
+	for _, file := range files {
+		switch file.Language {
+		case "python", "javascript":
+		case "typescript":
+			skipped = append(skipped, SkippedSymbol{Path: file.Path, Reason: "typescript files must be compiled before they can be run"})
+			continue
+		default:
+			continue
+		}
+		if file.Error != "" {
+			skipped = append(skipped, SkippedSymbol{Path: file.Path, Reason: file.Error})
+			continue
+		}
+
+		sigs, skips := callableSignatures(file, true)
+		skipped = append(skipped, skips...)
+		if len(sigs) == 0 {
+			continue
+		}
+
+		var served []servedSymbol
+		if file.Language == "python" {
+			served, skips, err = preparePythonFile(g, file, sigs)
+		} else {
+			served, skips, err = prepareJavaScriptFile(g, file, sigs)
+		}
+		if err != nil {
+			skipped = append(skipped, SkippedSymbol{Path: file.Path, Reason: err.Error()})
+			continue
+		}
+		skipped = append(skipped, skips...)
+
+		for _, symbol := range served {
+			switch call := symbol.Call.(type) {
+			case pythonCall:
+				calls = append(calls, pythonLiveCall(root, python, call))
+			case jsCall:
+				calls = append(calls, nodeLiveCall(node, call))
+			}
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	// Tools are named and validated as for emit_tool_json in the MCP format
+	descriptors := make([]FunctionDescriptor, len(symbols))
+	for i, symbol := range symbols {
+		descriptors[i] = signatureDescriptor(symbol.Sig)
+	}
+	emitted, _, problems := toolFormats["mcp"].emit(descriptors, true)
+	for _, issue := range problems {
+		source := symbols[issue.Index].Source
+		skipped = append(skipped, SkippedSymbol{
+			Symbol: source.Symbol,
+			Path:   source.Path,
+			Line:   source.Line,
+			Reason: fmt.Sprintf("%s: %s", issue.Field, issue.Message),
+		})
+	}
+
+	reserved := make(map[string]bool)
+	for _, name := range opts.Reserved {
+		reserved[name] = true
+	}
+	var tools []server.ServerTool
+	for _, tool := range emitted {
+		source := symbols[tool.Index].Source
+		if reserved[tool.Name] {
+			skipped = append(skipped, SkippedSymbol{
+				Symbol: source.Symbol,
+				Path:   source.Path,
+				Line:   source.Line,
+				Reason: fmt.Sprintf("tool name %s is already used", tool.Name),
+			})
+			continue
+		}
+
+		definition := tool.Tool.(MCPToolDefinition)
+		schema, err := json.Marshal(definition.InputSchema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal schema of %s: %w", tool.Name, err)
+		}
+		mcpTool := mcp.NewToolWithRawSchema(tool.Name, definition.Description, schema)
+		mcpTool.Annotations.Title = source.Symbol
+
+		call := calls[tool.Index]
+		tools = append(tools, server.ServerTool{
+			Tool: mcpTool,
+			Handler: func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				result, err := runLiveCall(ctx, root, call, req.GetArguments(), timeout, maxOutput)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				return mcp.NewToolResultText(string(result)), nil
+			},
+		})
+	}
+	return tools, skipped, nil
+}
+
+// pythonLiveCall calls a Python function through the Python harness.
+// Positional-only arguments are passed in order up to the first one
+// missing, the others by keyword.
+func pythonLiveCall(root string, interpreter []string, call pythonCall) liveCall {
+	path := []string{root}
+	if call.Src {
+		path = append(path, filepath.Join(root, "src"))
+	}
+	return liveCall{
+		Interpreter: interpreter,
+		Flag:        "-c",
+		Harness:     pythonHarness,
+		Request: func(arguments map[string]interface{}) interface{} {
+			args := []interface{}{}
+			kwargs := map[string]interface{}{}
+			positional := true
+			for _, param := range call.Params {
+				value, ok := arguments[param.Name]
+				switch {
+				case !ok:
+					positional = positional && !param.PositionalOnly
+				case param.PositionalOnly && positional:
+					args = append(args, value)
+				case !param.PositionalOnly:
+					kwargs[param.Name] = value
+				}
+			}
+			return map[string]interface{}{
+				"path":   path,
+				"module": call.Module,
+				"target": call.Target,
+				"args":   args,
+				"kwargs": kwargs,
+			}
+		},
+	}
+}
+
+// nodeLiveCall calls a JavaScript function through the Node.js harness
+func nodeLiveCall(interpreter []string, call jsCall) liveCall {
+	return liveCall{
+		Interpreter: interpreter,
+		Flag:        "-e",
+		Harness:     nodeHarness,
+		Request: func(arguments map[string]interface{}) interface{} {
+			if arguments == nil {
+				arguments = map[string]interface{}{}
+			}
+			return map[string]interface{}{
+				"file":      call.File,
+				"target":    call.Target,
+				"params":    call.Params,
+				"rest":      call.Rest,
+				"arguments": arguments,
+			}
+		},
+	}
+}
+
+// runLiveCall runs a harness with the request for the given arguments and
+// returns the JSON result of the function
+func runLiveCall(ctx context.Context, root string, call liveCall, arguments map[string]interface{}, timeout time.Duration, maxOutput int) (json.RawMessage, error) {
+	request, err := json.Marshal(call.Request(arguments))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := append(append([]string{}, call.Interpreter[1:]...), call.Flag, call.Harness)
+	// #nosec G204 -- running repository code is what the operator opted into
+	cmd := exec.CommandContext(ctx, call.Interpreter[0], args...)
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(string(request))
+	stdout := &cappedBuffer{limit: maxOutput, overflow: cancel}
+	stderr := &cappedBuffer{limit: liveStderrLimit}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = time.Second
+
+	runErr := cmd.Run()
+	switch {
+	case stdout.exceeded:
+		return nil, fmt.Errorf("output exceeds %d bytes", maxOutput)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+
+	var response liveResponse
+	if err := json.Unmarshal(stdout.buf, &response); err != nil {
+		if runErr == nil {
+			runErr = err
+		}
+		return nil, fmt.Errorf("interpreter failed: %v: %s", runErr, strings.TrimSpace(string(stderr.buf)))
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return response.Result, nil
+}
+
+// cappedBuffer collects output up to a limit, calling overflow once when
+// more is written
+type cappedBuffer struct {
+	buf      []byte
+	limit    int
+	overflow func()
+	exceeded bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.buf); len(p) > room {
+		b.buf = append(b.buf, p[:max(room, 0)]...)
+		if !b.exceeded && b.overflow != nil {
+			b.overflow()
+		}
+		b.exceeded = true
+		// Keep draining so the child is not blocked on a full pipe
+		return len(p), nil
+	}
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+// prepareJavaScriptFile finds the parameters of the exported functions and
+// static methods of a JavaScript module. In ES modules only declarations
+// marked export are served; CommonJS exports are resolved when called.
+func prepareJavaScriptFile(g *generator, file FileSignatures, sigs []FunctionSignature) ([]servedSymbol, []SkippedSymbol, error) {
+	content, err := g.readSource(file.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	esm := jsExportRE.Match(content)
+	classes := make(map[string]string)
+	for _, sig := range file.Signatures {
+		if sig.Type == "class" {
+			classes[sig.Name] = sig.Signature
+		}
+	}
+
+	var served []servedSymbol
+	var skipped []SkippedSymbol
+	for _, sig := range sigs {
+		skip := func(reason string) {
+			skipped = append(skipped, SkippedSymbol{Symbol: sig.Name, Path: file.Path, Line: sig.Line, Reason: reason})
+		}
+		declaration := sig.Signature
+		if sig.Class != "" {
+			declaration = classes[sig.Class]
+		}
+		switch {
+		case sig.Type == "constructor":
+			skip("constructors return objects that cannot be returned as JSON")
+			continue
+		case sig.Type == "method" && !sig.Static:
+			skip("instance methods need an object to call")
+			continue
+		case esm && !strings.HasPrefix(declaration, "export"):
+			skip("not exported")
+			continue
+		}
+
+		params, rest, ok := jsCallParams(sig)
+		if !ok {
+			skip("parameter list not found")
+			continue
+		}
+		served = append(served, servedSymbol{
+			Sig:    sig,
+			Source: ToolSource{Symbol: sig.Name, Path: file.Path, Line: sig.Line, Language: file.Language},
+			Call: jsCall{
+				File:   filepath.Join(g.Repo.Dir(), filepath.FromSlash(file.Path)),
+				Target: sig.Name,
+				Params: params,
+				Rest:   rest,
+			},
+		})
+	}
+	return served, skipped, nil
+}
+
+// jsCallParams returns the parameter names of a function or method in
+// declaration order, up to a rest parameter, and the name of the rest
+// parameter if there is one
+func jsCallParams(sig FunctionSignature) ([]string, string, bool) {
+	patterns := []*regexp.Regexp{jsFunctionRE, jsArrowRE}
+	if sig.Class != "" {
+		patterns = []*regexp.Regexp{jsMethodRE, jsFieldArrowRE}
+	}
+	for _, pattern := range patterns {
+		m := pattern.FindString(sig.Signature)
+		if m == "" {
+			continue
+		}
+		var params []string
+		for index, param := range splitJSTopLevel(jsParameterList(sig.Signature, len(m)-1), ',') {
+			param = strings.TrimSpace(param)
+			switch {
+			case param == "":
+				continue
+			case strings.HasPrefix(param, "..."):
+				rest, _, _, _, _ := splitJSParameter(param[3:], index)
+				return params, rest, true
+			}
+			name, _, _, _, _ := splitJSParameter(param, index)
+			params = append(params, name)
+		}
+		return params, "", true
+	}
+	return nil, "", false
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireInterpreter skips the test when an interpreter is not installed
+func requireInterpreter(t *testing.T, name string) {
+	t.Helper()
+	if _, err := exec.LookPath(name); err != nil {
+		t.Skipf("%s is not installed", name)
+	}
+}
+
+// callLiveTool calls the served tool with the given name and returns its
+// text and whether it is an error
+func callLiveTool(t *testing.T, tools []server.ServerTool, name string, args map[string]interface{}) (string, bool) {
+	t.Helper()
+	for _, tool := range tools {
+		if tool.Tool.Name == name {
+			result, err := tool.Handler(context.Background(), createMCPRequest(args))
+			require.NoError(t, err)
+			return getTextResult(t, result).Text, result.IsError
+		}
+	}
+	require.Failf(t, "tool not served", "%s", name)
+	return "", false
+}
+
+func TestLiveToolsPython(t *testing.T) {
+	requireInterpreter(t, "python3")
+	root := writeTree(t, map[string]string{
+		"shop/__init__.py": "",
+		"shop/cart.py": `import asyncio
+import time
+
+def checkout(cart_id: str, qty: int = 1):
+    """Checks out."""
+    print("checking out")
+    return {"cart": cart_id, "qty": qty}
+
+def pick(a, b=2, /, *, mode="fast"):
+    return [a, b, mode]
+
+async def refresh(cart_id: str) -> bool:
+    await asyncio.sleep(0)
+    return True
+
+def fail():
+    raise ValueError("no cart")
+
+def slow():
+    time.sleep(5)
+
+def big():
+    return "x" * 1000
+
+class Cart:
+    def total(self) -> int:
+        return 0
+
+    @staticmethod
+    def parse(text: str):
+        return text.upper()
+`,
+	})
+
+	tools, skipped, err := LiveTools(context.Background(), openRepo(t, root), LiveOptions{
+		Timeout:   time.Second,
+		MaxOutput: 100,
+		Reserved:  []string{"fail"},
+	})
+	require.NoError(t, err)
+	require.Len(t, tools, 6)
+
+	reasons := make(map[string]string)
+	for _, skip := range skipped {
+		reasons[skip.Symbol] = skip.Reason
+	}
+	assert.Equal(t, "instance methods need an object to call", reasons["Cart.total"])
+	assert.Equal(t, "tool name fail is already used", reasons["fail"])
+
+	text, isError := callLiveTool(t, tools, "checkout", map[string]interface{}{"cart_id": "c1"})
+	assert.False(t, isError, text)
+	assert.JSONEq(t, `{"cart": "c1", "qty": 1}`, text)
+
+	text, _ = callLiveTool(t, tools, "pick", map[string]interface{}{"a": 1, "mode": "slow"})
+	assert.JSONEq(t, `[1, 2, "slow"]`, text)
+
+	text, _ = callLiveTool(t, tools, "refresh", map[string]interface{}{"cart_id": "c1"})
+	assert.Equal(t, "true", text)
+
+	text, _ = callLiveTool(t, tools, "Cart_parse", map[string]interface{}{"text": "abc"})
+	assert.Equal(t, `"ABC"`, text)
+
+	text, isError = callLiveTool(t, tools, "slow", nil)
+	assert.True(t, isError)
+	assert.Equal(t, "timed out after 1s", text)
+
+	text, isError = callLiveTool(t, tools, "big", nil)
+	assert.True(t, isError)
+	assert.Equal(t, "output exceeds 100 bytes", text)
+}
+
+func TestLiveToolsPythonError(t *testing.T) {
+	requireInterpreter(t, "python3")
+	root := writeTree(t, map[string]string{
+		"cart.py": "def fail():\n    raise ValueError(\"no cart\")\n",
+	})
+
+	tools, _, err := LiveTools(context.Background(), openRepo(t, root), LiveOptions{})
+	require.NoError(t, err)
+
+	text, isError := callLiveTool(t, tools, "fail", nil)
+	assert.True(t, isError)
+	assert.Equal(t, "ValueError: no cart", text)
+}
+
+func TestLiveToolsJavaScript(t *testing.T) {
+	requireInterpreter(t, "node")
+	root := writeTree(t, map[string]string{
+		"lib/math.mjs": `export function add(a, b = 10) {
+  console.log("adding");
+  return a + b;
+}
+
+function hidden(x) {
+  return x;
+}
+
+export const later = async (n) => n * 2;
+
+export class Shape {
+  static unit(size, ...rest) {
+    return { size, extra: rest.length };
+  }
+
+  area() {
+    return 0;
+  }
+}
+`,
+		"lib/legacy.js": `function greet(name) {
+  return "hi " + name;
+}
+
+module.exports = { greet };
+`,
+		"web/app.ts": "export function run(): void {}\n",
+	})
+
+	tools, skipped, err := LiveTools(context.Background(), openRepo(t, root), LiveOptions{})
+	require.NoError(t, err)
+
+	reasons := make(map[string]string)
+	for _, skip := range skipped {
+		name := skip.Symbol
+		if name == "" {
+			name = skip.Path
+		}
+		reasons[name] = skip.Reason
+	}
+	assert.Equal(t, "not exported", reasons["hidden"])
+	assert.Equal(t, "instance methods need an object to call", reasons["Shape.area"])
+	assert.Equal(t, "typescript files must be compiled before they can be run", reasons["web/app.ts"])
+
+	text, isError := callLiveTool(t, tools, "add", map[string]interface{}{"a": 1})
+	assert.False(t, isError, text)
+	assert.Equal(t, "11", text)
+
+	text, _ = callLiveTool(t, tools, "later", map[string]interface{}{"n": 4})
+	assert.Equal(t, "8", text)
+
+	text, _ = callLiveTool(t, tools, "Shape_unit", map[string]interface{}{"size": 3})
+	assert.JSONEq(t, `{"size": 3, "extra": 0}`, text)
+
+	text, _ = callLiveTool(t, tools, "Shape_unit", map[string]interface{}{"rest": []interface{}{"a", "b"}})
+	assert.JSONEq(t, `{"extra": 2}`, text)
+
+	text, _ = callLiveTool(t, tools, "greet", map[string]interface{}{"name": "bo"})
+	assert.Equal(t, `"hi bo"`, text)
+}
+
+func TestJSCallParams(t *testing.T) {
+	params, rest, ok := jsCallParams(FunctionSignature{Signature: "export function format(pattern, { locale } = {}, ...values) {"})
+	require.True(t, ok)
+	assert.Equal(t, []string{"pattern", "options"}, params)
+	assert.Equal(t, "values", rest)
+
+	params, rest, ok = jsCallParams(FunctionSignature{Signature: "static unit(size) {", Class: "Shape"})
+	require.True(t, ok)
+	assert.Equal(t, []string{"size"}, params)
+	assert.Empty(t, rest)
+}
+
+func TestLiveToolsMissingInterpreter(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"cart.py": "def total():\n    return 0\n",
+	})
+
+	tools, _, err := LiveTools(context.Background(), openRepo(t, root), LiveOptions{Python: "mcp-prime-missing-python"})
+	require.NoError(t, err)
+
+	text, isError := callLiveTool(t, tools, "total", nil)
+	assert.True(t, isError)
+	assert.Contains(t, text, "interpreter failed")
+}
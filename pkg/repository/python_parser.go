@@ -0,0 +1,480 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pythonLine is a logical line of Python source: physical lines joined by open
+// brackets, backslash continuations or multi-line strings, with comments removed
+type pythonLine struct {
+	Indent int
+	Text   string
+	Line   int
+}
+
+// pythonDef is a def or class statement found by parsePythonDefinitions
+type pythonDef struct {
+	Kind       string // "def" or "class"
+	Name       string // qualified with enclosing scopes, e.g. "Outer.Inner.method"
+	Params     string
+	Returns    string
+	Bases      string
+	Decorators []string
+	Async      bool
+	Signature  string
+	Docstring  string
+	Line       int
+	Class      string // enclosing class, empty at module level
+	Nested     bool   // declared inside a function body
+	Private    bool   // the name or an enclosing class name starts with "_"
+	Members    []pythonMember
+}
+
+// pythonMember is a simple NAME = value assignment directly inside a class body
+type pythonMember struct {
+	Name  string
+	Value string
+}
+
+var (
+	pythonIdentifierRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+	pythonAssignmentRE = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(?::[^=]*)?=([^=].*)$`)
+)
+
+// pythonLogicalLines tokenizes Python source into logical lines, tracking
+// brackets, string literals and line continuations
+func pythonLogicalLines(code string) []pythonLine {
+	var lines []pythonLine
+	var buf strings.Builder
+
+	depth := 0
+	lineNo := 1
+	start := 1
+	indent := 0
+	atLineStart := true
+
+	flush := func() {
+		if text := strings.TrimSpace(buf.String()); text != "" {
+			lines = append(lines, pythonLine{Indent: indent, Text: text, Line: start})
+		}
+		buf.Reset()
+	}
+
+	for i := 0; i < len(code); {
+		if atLineStart {
+			col := 0
+			for i < len(code) && (code[i] == ' ' || code[i] == '\t' || code[i] == '\f') {
+				if code[i] == '\t' {
+					col = (col/8 + 1) * 8
+				} else {
+					col++
+				}
+				i++
+			}
+			atLineStart = false
+			if buf.Len() == 0 && depth == 0 {
+				indent = col
+				start = lineNo
+			} else {
+				buf.WriteByte(' ')
+			}
+			continue
+		}
+
+		c := code[i]
+		switch {
+		case c == '#':
+			for i < len(code) && code[i] != '\n' {
+				i++
+			}
+		case c == '\\' && i+1 < len(code) && (code[i+1] == '\n' || code[i+1] == '\r'):
+			i++
+			if code[i] == '\r' && i+1 < len(code) && code[i+1] == '\n' {
+				i++
+			}
+			i++
+			lineNo++
+			atLineStart = true
+		case c == '"' || c == '\'':
+			end, newlines := pythonStringEnd(code, i)
+			buf.WriteString(code[i:end])
+			lineNo += newlines
+			i = end
+		case c == '(' || c == '[' || c == '{':
+			depth++
+			buf.WriteByte(c)
+			i++
+		case c == ')' || c == ']' || c == '}':
+			if depth > 0 {
+				depth--
+			}
+			buf.WriteByte(c)
+			i++
+		case c == '\n':
+			lineNo++
+			atLineStart = true
+			if depth == 0 {
+				flush()
+			}
+			i++
+		case c == '\r':
+			i++
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+
+	return lines
+}
+
+// pythonStringEnd returns the index just past the string literal starting at
+// start and the number of newlines it spans. Unterminated single-quoted
+// strings end at the line break, matching how the tokenizer recovers.
+func pythonStringEnd(code string, start int) (int, int) {
+	quote := code[start]
+	triple := strings.HasPrefix(code[start:], strings.Repeat(string(quote), 3))
+	newlines := 0
+
+	i := start + 1
+	if triple {
+		i = start + 3
+	}
+	for i < len(code) {
+		c := code[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(code) && code[i+1] == '\n' {
+				newlines++
+			}
+			i += 2
+			continue
+		case c == '\n':
+			if !triple {
+				return i, newlines
+			}
+			newlines++
+		case c == quote:
+			if !triple {
+				return i + 1, newlines
+			}
+			if strings.HasPrefix(code[i:], strings.Repeat(string(quote), 3)) {
+				return i + 3, newlines
+			}
+		}
+		i++
+	}
+	return len(code), newlines
+}
+
+// pythonScanTo returns the index of the first occurrence of any byte in stops
+// at bracket depth zero and outside string literals, or -1. The parameters
+// of a lambda, up to its colon, are skipped, so the lambda in
+// "b=lambda x, y: x" runs to the next separator after its body.
+func pythonScanTo(text string, stops string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"' || c == '\'':
+			end, _ := pythonStringEnd(text, i)
+			i = end - 1
+		case depth == 0 && pythonIsKeywordAt(text, i, "lambda"):
+			colon := pythonScanTo(text[i+len("lambda"):], ":")
+			if colon < 0 {
+				return -1
+			}
+			i += len("lambda") + colon
+		case depth == 0 && strings.IndexByte(stops, c) >= 0:
+			return i
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		}
+	}
+	return -1
+}
+
+// pythonIsKeywordAt reports whether the keyword starts at offset i of text
+// as a whole word
+func pythonIsKeywordAt(text string, i int, keyword string) bool {
+	if !strings.HasPrefix(text[i:], keyword) {
+		return false
+	}
+	end := i + len(keyword)
+	return (i == 0 || !braceIsIdentByte(text[i-1])) && (end == len(text) || !braceIsIdentByte(text[end]))
+}
+
+// splitPythonTopLevel splits text on sep, ignoring separators nested in
+// brackets or string literals
+func splitPythonTopLevel(text string, sep byte) []string {
+	var parts []string
+	for {
+		idx := pythonScanTo(text, string(sep))
+		if idx < 0 {
+			parts = append(parts, text)
+			return parts
+		}
+		parts = append(parts, text[:idx])
+		text = text[idx+1:]
+	}
+}
+
+// pythonStringLiteral returns the value of text if it consists of a single
+// string literal, as used for docstrings
+func pythonStringLiteral(text string) (string, bool) {
+	prefixLen := 0
+	raw := false
+	for prefixLen < len(text) && prefixLen < 2 && strings.ContainsRune("rRuUbBfF", rune(text[prefixLen])) {
+		if text[prefixLen] == 'r' || text[prefixLen] == 'R' {
+			raw = true
+		}
+		prefixLen++
+	}
+	if prefixLen >= len(text) || (text[prefixLen] != '"' && text[prefixLen] != '\'') {
+		return "", false
+	}
+
+	end, _ := pythonStringEnd(text, prefixLen)
+	if strings.TrimSpace(text[end:]) != "" {
+		return "", false
+	}
+
+	body := text[prefixLen:end]
+	quoteLen := 1
+	if len(body) >= 6 && body[:3] == strings.Repeat(body[:1], 3) {
+		quoteLen = 3
+	}
+	if len(body) < 2*quoteLen {
+		return "", false
+	}
+	body = body[quoteLen : len(body)-quoteLen]
+
+	if !raw {
+		body = strings.NewReplacer(`\"`, `"`, `\'`, `'`, `\\`, `\`, `\n`, "\n", `\t`, "\t").Replace(body)
+	}
+	return body, true
+}
+
+// cleanPythonDocstring normalizes docstring indentation like inspect.cleandoc
+func cleanPythonDocstring(doc string) string {
+	lines := strings.Split(strings.ReplaceAll(doc, "\t", "        "), "\n")
+
+	margin := -1
+	for _, line := range lines[1:] {
+		stripped := strings.TrimLeft(line, " ")
+		if stripped == "" {
+			continue
+		}
+		if indent := len(line) - len(stripped); margin < 0 || indent < margin {
+			margin = indent
+		}
+	}
+
+	lines[0] = strings.TrimSpace(lines[0])
+	for i := 1; i < len(lines); i++ {
+		if margin > 0 && len(lines[i]) >= margin {
+			lines[i] = lines[i][margin:]
+		}
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// normalizePythonSignature collapses whitespace introduced by joining lines
+func normalizePythonSignature(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"' || c == '\'':
+			end, _ := pythonStringEnd(text, i)
+			b.WriteString(text[i:end])
+			i = end - 1
+		case c == ' ' || c == '\t':
+			for i+1 < len(text) && (text[i+1] == ' ' || text[i+1] == '\t') {
+				i++
+			}
+			out := b.String()
+			next := byte(0)
+			if i+1 < len(text) {
+				next = text[i+1]
+			}
+			if strings.HasSuffix(out, "(") || strings.HasSuffix(out, "[") || next == ')' || next == ']' {
+				continue
+			}
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// parsePythonDefinitions finds every def and class statement in the source,
+// tracking the enclosing class and function scopes by indentation
+func parsePythonDefinitions(code string) []pythonDef {
+	type scope struct {
+		indent  int
+		name    string
+		isClass bool
+		private bool
+		def     int // index into defs
+	}
+
+	lines := pythonLogicalLines(code)
+
+	var defs []pythonDef
+	var scopes []scope
+	var decorators []string
+	decoratorLine := 0
+
+	for idx, line := range lines {
+		for len(scopes) > 0 && scopes[len(scopes)-1].indent >= line.Indent {
+			scopes = scopes[:len(scopes)-1]
+		}
+
+		text := line.Text
+		if strings.HasPrefix(text, "@") {
+			if len(decorators) == 0 {
+				decoratorLine = line.Line
+			}
+			decorators = append(decorators, normalizePythonSignature(strings.TrimSpace(text[1:])))
+			continue
+		}
+
+		def, ok := parsePythonDefLine(text)
+		if !ok {
+			decorators = nil
+			if len(scopes) > 0 && scopes[len(scopes)-1].isClass {
+				if m := pythonAssignmentRE.FindStringSubmatch(text); m != nil {
+					owner := &defs[scopes[len(scopes)-1].def]
+					owner.Members = append(owner.Members, pythonMember{Name: m[1], Value: strings.TrimSpace(m[2])})
+				}
+			}
+			continue
+		}
+
+		def.Line = line.Line
+		if len(decorators) > 0 {
+			def.Line = decoratorLine
+			def.Decorators = decorators
+			decorators = nil
+		}
+
+		var classNames []string
+		for _, s := range scopes {
+			if !s.isClass {
+				def.Nested = true
+			}
+			if s.private {
+				def.Private = true
+			}
+			classNames = append(classNames, s.name)
+		}
+		if len(scopes) > 0 && scopes[len(scopes)-1].isClass {
+			def.Class = strings.Join(classNames, ".")
+		}
+		// Constructors are public even though their name starts with "_"
+		if strings.HasPrefix(def.Name, "_") && !(def.Class != "" && def.Name == "__init__") {
+			def.Private = true
+		}
+		localName := def.Name
+		if len(classNames) > 0 {
+			def.Name = strings.Join(classNames, ".") + "." + def.Name
+		}
+
+		// The docstring is the first statement of the body, either inline after
+		// the colon or on the next, more indented logical line
+		if idx+1 < len(lines) && lines[idx+1].Indent > line.Indent {
+			if doc, ok := pythonStringLiteral(lines[idx+1].Text); ok {
+				def.Docstring = cleanPythonDocstring(doc)
+			}
+		}
+
+		defs = append(defs, def)
+		scopes = append(scopes, scope{
+			indent:  line.Indent,
+			name:    localName,
+			isClass: def.Kind == "class",
+			private: def.Private,
+			def:     len(defs) - 1,
+		})
+	}
+
+	return defs
+}
+
+// parsePythonDefLine parses the header of a def or class statement
+func parsePythonDefLine(text string) (pythonDef, bool) {
+	var def pythonDef
+
+	rest := text
+	if strings.HasPrefix(rest, "async ") {
+		def.Async = true
+		rest = strings.TrimSpace(rest[len("async "):])
+	}
+
+	switch {
+	case strings.HasPrefix(rest, "def ") || strings.HasPrefix(rest, "def\t"):
+		def.Kind = "def"
+	case (strings.HasPrefix(rest, "class ") || strings.HasPrefix(rest, "class\t")) && !def.Async:
+		def.Kind = "class"
+	default:
+		return def, false
+	}
+
+	rest = strings.TrimSpace(rest[len(def.Kind):])
+	def.Name = pythonIdentifierRE.FindString(rest)
+	if def.Name == "" {
+		return def, false
+	}
+	rest = strings.TrimSpace(rest[len(def.Name):])
+
+	// Skip PEP 695 type parameters, e.g. def first[T](items: list[T]) -> T
+	if strings.HasPrefix(rest, "[") {
+		end := pythonScanTo(rest[1:], "]")
+		if end < 0 {
+			return def, false
+		}
+		rest = strings.TrimSpace(rest[end+2:])
+	}
+
+	if strings.HasPrefix(rest, "(") {
+		end := pythonScanTo(rest[1:], ")")
+		if end < 0 {
+			return def, false
+		}
+		inner := strings.TrimSpace(rest[1 : end+1])
+		if def.Kind == "def" {
+			def.Params = inner
+		} else {
+			def.Bases = inner
+		}
+		rest = strings.TrimSpace(rest[end+2:])
+	} else if def.Kind == "def" {
+		return def, false
+	}
+
+	colon := pythonScanTo(rest, ":")
+	if colon < 0 {
+		return def, false
+	}
+	if def.Kind == "def" {
+		def.Returns = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest[:colon]), "->"))
+	}
+
+	header := text[:len(text)-len(rest)+colon+1]
+	def.Signature = normalizePythonSignature(header)
+	if body := strings.TrimSpace(rest[colon+1:]); body != "" {
+		if doc, ok := pythonStringLiteral(strings.TrimSpace(splitPythonTopLevel(body, ';')[0])); ok {
+			def.Docstring = cleanPythonDocstring(doc)
+		}
+	}
+
+	return def, true
+}
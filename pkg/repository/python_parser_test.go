@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPythonLogicalLines(t *testing.T) {
+	code := "x = 1  # comment\n" +
+		"def f(a,\n" +
+		"      b=(1, 2)):  # trailing\n" +
+		"    s = '''multi\n" +
+		"line # not a comment'''\n" +
+		"    y = 1 + \\\n" +
+		"        2\n"
+
+	lines := pythonLogicalLines(code)
+	require.Len(t, lines, 4)
+
+	assert.Equal(t, pythonLine{Indent: 0, Text: "x = 1", Line: 1}, lines[0])
+	assert.Equal(t, 2, lines[1].Line)
+	assert.Equal(t, "def f(a, b=(1, 2)):", lines[1].Text)
+	assert.Equal(t, pythonLine{Indent: 4, Text: "s = '''multi\nline # not a comment'''", Line: 4}, lines[2])
+	assert.Equal(t, 6, lines[3].Line)
+	assert.Equal(t, 4, lines[3].Indent)
+}
+
+func TestSplitPythonTopLevel(t *testing.T) {
+	parts := splitPythonTopLevel(`a, b=(1, 2), c: dict[str, int] = {"x": 1, "y": 2}, d="p,q"`, ',')
+	assert.Equal(t, []string{"a", " b=(1, 2)", ` c: dict[str, int] = {"x": 1, "y": 2}`, ` d="p,q"`}, parts)
+}
+
+func TestSplitPythonTopLevelLambda(t *testing.T) {
+	parts := splitPythonTopLevel(`a, b=lambda x, y: x, c=lambda: 0, d=lambda p=lambda q, r: q, s: p, e_lambda, f`, ',')
+	assert.Equal(t, []string{"a", " b=lambda x, y: x", " c=lambda: 0", " d=lambda p=lambda q, r: q, s: p", " e_lambda", " f"}, parts)
+
+	parameters, required := parsePythonParameters("a, b=lambda x, y: x, key: Callable = lambda k, v: k", nil, nil)
+	assert.Equal(t, []string{"a"}, required)
+	props := parameters["properties"].(map[string]interface{})
+	assert.Len(t, props, 3)
+	assert.Contains(t, props, "b")
+	assert.Contains(t, props, "key")
+}
+
+func TestParsePythonDefinitions(t *testing.T) {
+	code := `import functools
+
+
+@functools.lru_cache(
+    maxsize=None,
+)
+async def fetch(
+    url: str,
+    retries: int = 3,
+    backoff: tuple = (1, 2),
+) -> bytes:
+    """Fetch a URL.
+
+        Retries on failure.
+    """
+    def inner():
+        pass
+    return b""
+
+
+class Outer(Base, metaclass=Meta):
+    'Outer docstring.'
+
+    class Inner:
+        def method(self, x): return x
+
+    @staticmethod
+    def build(a, *, b=1):
+        pass
+
+
+def one_liner(x): "Inline doc."; return x
+`
+
+	defs := parsePythonDefinitions(code)
+
+	byName := make(map[string]pythonDef)
+	for _, def := range defs {
+		byName[def.Name] = def
+	}
+
+	fetch := byName["fetch"]
+	assert.True(t, fetch.Async)
+	assert.Equal(t, 4, fetch.Line)
+	assert.Equal(t, []string{"functools.lru_cache(maxsize=None,)"}, fetch.Decorators)
+	assert.Equal(t, "async def fetch(url: str, retries: int = 3, backoff: tuple = (1, 2),) -> bytes:", fetch.Signature)
+	assert.Equal(t, "bytes", fetch.Returns)
+	assert.Equal(t, "Fetch a URL.\n\nRetries on failure.", fetch.Docstring)
+
+	inner := byName["fetch.inner"]
+	assert.True(t, inner.Nested)
+
+	outer := byName["Outer"]
+	assert.Equal(t, "class", outer.Kind)
+	assert.Equal(t, "Base, metaclass=Meta", outer.Bases)
+	assert.Equal(t, "Outer docstring.", outer.Docstring)
+	assert.Equal(t, 21, outer.Line)
+
+	method := byName["Outer.Inner.method"]
+	assert.Equal(t, "Outer.Inner", method.Class)
+	assert.False(t, method.Nested)
+
+	build := byName["Outer.build"]
+	assert.Equal(t, "Outer", build.Class)
+	assert.Equal(t, []string{"staticmethod"}, build.Decorators)
+	assert.Equal(t, "a, *, b=1", build.Params)
+
+	oneLiner := byName["one_liner"]
+	assert.Equal(t, "Inline doc.", oneLiner.Docstring)
+	assert.Equal(t, "def one_liner(x):", oneLiner.Signature)
+}
+
+func TestExtractPythonSignaturesScopes(t *testing.T) {
+	code := `class Repo:
+    """A repository."""
+
+    def clone(self, url, depth=(1, 2)):
+        """Clone it."""
+
+    def _hidden(self):
+        pass
+
+
+class _Private:
+    def visible(self):
+        pass
+
+
+def top(a, b):
+    def helper():
+        pass
+`
+
+	signatures, err := extractPythonSignatures(code)
+	require.NoError(t, err)
+
+	var names []string
+	for _, sig := range signatures {
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{"Repo", "Repo.clone", "top"}, names)
+
+	clone := signatures[1]
+	assert.Equal(t, "method", clone.Type)
+	assert.Equal(t, 4, clone.Line)
+	assert.Equal(t, "Clone it.", clone.Description)
+	assert.Equal(t, []string{"url"}, clone.Required)
+	assert.Contains(t, clone.Parameters["properties"], "depth")
+	assert.Equal(t, 16, signatures[2].Line)
+}
+
+func TestExtractPythonSignaturesMethods(t *testing.T) {
+	code := `class Point:
+    """A point.
+
+    Args:
+        x: horizontal position
+    """
+
+    def __init__(self, x: int, y: int = 0):
+        self.x, self.y = x, y
+
+    @staticmethod
+    def origin_distance(x: float, y: float) -> float:
+        """Distance from the origin."""
+
+    @classmethod
+    def parse(cls, text: str) -> "Point":
+        """Parses "x,y"."""
+
+    def move(self, dx: int):
+        pass
+`
+
+	signatures, err := extractPythonSignatures(code)
+	require.NoError(t, err)
+
+	byName := make(map[string]FunctionSignature)
+	var names []string
+	for _, sig := range signatures {
+		byName[sig.Name] = sig
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{"Point", "Point.__init__", "Point.origin_distance", "Point.parse", "Point.move"}, names)
+
+	ctor := byName["Point.__init__"]
+	assert.Equal(t, "constructor", ctor.Type)
+	assert.Equal(t, "Point", ctor.Class)
+	assert.False(t, ctor.Static)
+	assert.Equal(t, "A point.", ctor.Description)
+	assert.Equal(t, []string{"x"}, ctor.Required)
+	assert.Equal(t, "horizontal position", ctor.Parameters["properties"].(map[string]interface{})["x"].(map[string]interface{})["description"])
+
+	distance := byName["Point.origin_distance"]
+	assert.Equal(t, "method", distance.Type)
+	assert.True(t, distance.Static)
+	assert.Equal(t, []string{"x", "y"}, distance.Required)
+
+	parse := byName["Point.parse"]
+	assert.True(t, parse.Static)
+	assert.Equal(t, []string{"text"}, parse.Required)
+
+	move := byName["Point.move"]
+	assert.False(t, move.Static)
+	assert.Equal(t, "Point", move.Class)
+	assert.Equal(t, []string{"dx"}, move.Required)
+}
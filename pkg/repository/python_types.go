@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pythonEnumBases are the base classes that mark a class as an enumeration
+var pythonEnumBases = map[string]bool{
+	"Enum":    true,
+	"IntEnum": true,
+	"StrEnum": true,
+	"Flag":    true,
+	"IntFlag": true,
+}
+
+// collectPythonEnums returns the allowed values of every Enum subclass declared in the module
+func collectPythonEnums(defs []pythonDef) map[string][]interface{} {
+	enums := make(map[string][]interface{})
+
+	for _, def := range defs {
+		if def.Kind != "class" || def.Bases == "" {
+			continue
+		}
+
+		isEnum := false
+		for _, base := range splitPythonTopLevel(def.Bases, ',') {
+			base = strings.TrimSpace(base)
+			if idx := strings.LastIndex(base, "."); idx >= 0 {
+				base = base[idx+1:]
+			}
+			if pythonEnumBases[base] {
+				isEnum = true
+				break
+			}
+		}
+		if !isEnum || len(def.Members) == 0 {
+			continue
+		}
+
+		// Prefer member values; fall back to member names when any value is
+		// computed (e.g. auto()) and cannot be known statically
+		values := make([]interface{}, 0, len(def.Members))
+		names := make([]interface{}, 0, len(def.Members))
+		literal := true
+		for _, member := range def.Members {
+			names = append(names, member.Name)
+			value, ok := parsePythonLiteral(member.Value)
+			if !ok || value == nil {
+				literal = false
+			}
+			values = append(values, value)
+		}
+		if literal {
+			enums[def.Name] = values
+		} else {
+			enums[def.Name] = names
+		}
+	}
+
+	return enums
+}
+
+// pythonTypeSchema maps a Python type annotation to a JSON Schema
+func pythonTypeSchema(annotation string, enums map[string][]interface{}) map[string]interface{} {
+	annotation = strings.TrimSpace(annotation)
+
+	// Forward references are written as string literals
+	if value, ok := pythonStringLiteral(annotation); ok {
+		annotation = strings.TrimSpace(value)
+	}
+
+	if parts := splitPythonTopLevel(annotation, '|'); len(parts) > 1 {
+		return pythonUnionSchema(parts, enums)
+	}
+
+	name, args := annotation, []string(nil)
+	if open := pythonScanTo(annotation, "["); open >= 0 && strings.HasSuffix(annotation, "]") {
+		name = strings.TrimSpace(annotation[:open])
+		for _, arg := range splitPythonTopLevel(annotation[open+1:len(annotation)-1], ',') {
+			if arg = strings.TrimSpace(arg); arg != "" {
+				args = append(args, arg)
+			}
+		}
+	}
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "typing."), "typing_extensions.")
+	name = strings.TrimPrefix(name, "collections.abc.")
+
+	switch name {
+	case "int":
+		return map[string]interface{}{"type": "integer"}
+	case "float", "Decimal", "decimal.Decimal":
+		return map[string]interface{}{"type": "number"}
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "str", "bytes", "Path", "pathlib.Path", "UUID", "uuid.UUID":
+		return map[string]interface{}{"type": "string"}
+	case "datetime", "datetime.datetime":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "date", "datetime.date":
+		return map[string]interface{}{"type": "string", "format": "date"}
+	case "None", "NoneType":
+		return map[string]interface{}{"type": "null"}
+	case "Any", "object", "":
+		return map[string]interface{}{}
+	case "Optional":
+		if len(args) == 1 {
+			schema := pythonTypeSchema(args[0], enums)
+			schema["nullable"] = true
+			return schema
+		}
+	case "Union":
+		return pythonUnionSchema(args, enums)
+	case "Annotated", "Required", "NotRequired", "Final", "ClassVar":
+		if len(args) > 0 {
+			return pythonTypeSchema(args[0], enums)
+		}
+	case "Literal":
+		return pythonLiteralSchema(args)
+	case "list", "List", "set", "Set", "frozenset", "FrozenSet", "Sequence", "MutableSequence",
+		"Iterable", "Iterator", "Collection", "AbstractSet", "MutableSet", "deque", "Deque":
+		schema := map[string]interface{}{"type": "array"}
+		if len(args) == 1 {
+			schema["items"] = pythonTypeSchema(args[0], enums)
+		}
+		if strings.Contains(strings.ToLower(name), "set") {
+			schema["uniqueItems"] = true
+		}
+		return schema
+	case "tuple", "Tuple":
+		schema := map[string]interface{}{"type": "array"}
+		switch {
+		case len(args) == 2 && args[1] == "...":
+			schema["items"] = pythonTypeSchema(args[0], enums)
+		case len(args) > 0:
+			items := make([]interface{}, 0, len(args))
+			for _, arg := range args {
+				items = append(items, pythonTypeSchema(arg, enums))
+			}
+			schema["prefixItems"] = items
+			schema["minItems"] = len(args)
+			schema["maxItems"] = len(args)
+		}
+		return schema
+	case "dict", "Dict", "Mapping", "MutableMapping", "OrderedDict", "defaultdict", "DefaultDict", "TypedDict":
+		schema := map[string]interface{}{"type": "object"}
+		if len(args) == 2 {
+			schema["additionalProperties"] = pythonTypeSchema(args[1], enums)
+		}
+		return schema
+	}
+
+	if values, ok := enums[name]; ok {
+		return enumSchema(values)
+	}
+
+	// Any other class is passed as a JSON object
+	return map[string]interface{}{"type": "object"}
+}
+
+// pythonUnionSchema maps the members of a Union or X | Y annotation
+func pythonUnionSchema(parts []string, enums map[string][]interface{}) map[string]interface{} {
+	nullable := false
+	var schemas []interface{}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "None" || part == "NoneType" {
+			nullable = true
+			continue
+		}
+		schemas = append(schemas, pythonTypeSchema(part, enums))
+	}
+
+	var schema map[string]interface{}
+	switch len(schemas) {
+	case 0:
+		schema = map[string]interface{}{"type": "null"}
+	case 1:
+		schema = schemas[0].(map[string]interface{})
+	default:
+		schema = map[string]interface{}{"anyOf": schemas}
+	}
+	if nullable && len(schemas) > 0 {
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+// pythonLiteralSchema maps Literal["a", "b"] to an enum
+func pythonLiteralSchema(args []string) map[string]interface{} {
+	values := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		value, ok := parsePythonLiteral(arg)
+		if !ok {
+			return map[string]interface{}{}
+		}
+		values = append(values, value)
+	}
+	return enumSchema(values)
+}
+
+// enumSchema builds an enum schema, naming the type when all values share one
+func enumSchema(values []interface{}) map[string]interface{} {
+	schema := map[string]interface{}{"enum": values}
+
+	kind := ""
+	for _, value := range values {
+		valueKind := jsonSchemaTypeOf(value)
+		if kind != "" && valueKind != kind {
+			return schema
+		}
+		kind = valueKind
+	}
+	if kind != "" {
+		schema["type"] = kind
+	}
+	return schema
+}
+
+// jsonSchemaTypeOf names the JSON Schema type of a decoded literal value
+func jsonSchemaTypeOf(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case nil:
+		return "null"
+	}
+	return ""
+}
+
+// parsePythonLiteral evaluates a Python literal expression such as a default value.
+// It supports numbers, strings, booleans, None and lists, tuples, sets and dicts of those.
+func parsePythonLiteral(text string) (interface{}, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, false
+	}
+
+	switch text {
+	case "None":
+		return nil, true
+	case "True":
+		return true, true
+	case "False":
+		return false, true
+	}
+
+	if value, ok := pythonStringLiteral(text); ok {
+		return value, true
+	}
+
+	number := strings.ReplaceAll(text, "_", "")
+	if i, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return i, true
+	}
+	if f, err := strconv.ParseFloat(number, 64); err == nil {
+		return f, true
+	}
+
+	open, closing := text[0], text[len(text)-1]
+	if !((open == '[' && closing == ']') || (open == '(' && closing == ')') || (open == '{' && closing == '}')) {
+		return nil, false
+	}
+
+	var elements []string
+	for _, element := range splitPythonTopLevel(text[1:len(text)-1], ',') {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+
+	// {} and {"key": value} are dicts; {1, 2} is a set
+	if open == '{' && (len(elements) == 0 || pythonScanTo(elements[0], ":") >= 0) {
+		dict := make(map[string]interface{}, len(elements))
+		for _, element := range elements {
+			colon := pythonScanTo(element, ":")
+			if colon < 0 {
+				return nil, false
+			}
+			key, ok := parsePythonLiteral(element[:colon])
+			if !ok {
+				return nil, false
+			}
+			keyString, ok := key.(string)
+			if !ok {
+				keyString = strings.TrimSpace(element[:colon])
+			}
+			value, ok := parsePythonLiteral(element[colon+1:])
+			if !ok {
+				return nil, false
+			}
+			dict[keyString] = value
+		}
+		return dict, true
+	}
+
+	list := make([]interface{}, 0, len(elements))
+	for _, element := range elements {
+		value, ok := parsePythonLiteral(element)
+		if !ok {
+			return nil, false
+		}
+		list = append(list, value)
+	}
+	return list, true
+}
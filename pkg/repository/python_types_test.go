@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPythonTypeSchema(t *testing.T) {
+	enums := map[string][]interface{}{"Color": {"red", "green"}}
+
+	tests := []struct {
+		name       string
+		annotation string
+		expected   map[string]interface{}
+	}{
+		{"int", "int", map[string]interface{}{"type": "integer"}},
+		{"float", "float", map[string]interface{}{"type": "number"}},
+		{"bool", "bool", map[string]interface{}{"type": "boolean"}},
+		{"str", "str", map[string]interface{}{"type": "string"}},
+		{"list of str", "list[str]", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}},
+		{"typing List", "typing.List[int]", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "integer"},
+		}},
+		{"dict", "dict[str, int]", map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "integer"},
+		}},
+		{"optional", "Optional[int]", map[string]interface{}{"type": "integer", "nullable": true}},
+		{"pipe none", "str | None", map[string]interface{}{"type": "string", "nullable": true}},
+		{"union", "Union[int, str]", map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "string"},
+			},
+		}},
+		{"literal", `Literal["a", "b"]`, map[string]interface{}{"type": "string", "enum": []interface{}{"a", "b"}}},
+		{"mixed literal", `Literal["a", 1]`, map[string]interface{}{"enum": []interface{}{"a", int64(1)}}},
+		{"enum class", "Color", map[string]interface{}{"type": "string", "enum": []interface{}{"red", "green"}}},
+		{"forward reference", `"list[int]"`, map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "integer"},
+		}},
+		{"tuple", "tuple[int, ...]", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "integer"},
+		}},
+		{"set", "set[str]", map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"uniqueItems": true,
+		}},
+		{"annotated", "Annotated[int, Field(gt=0)]", map[string]interface{}{"type": "integer"}},
+		{"any", "Any", map[string]interface{}{}},
+		{"unknown class", "Request", map[string]interface{}{"type": "object"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, pythonTypeSchema(tc.annotation, enums))
+		})
+	}
+}
+
+func TestParsePythonLiteral(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected interface{}
+		ok       bool
+	}{
+		{"42", int64(42), true},
+		{"-1.5", -1.5, true},
+		{"1_000", int64(1000), true},
+		{"True", true, true},
+		{"None", nil, true},
+		{`'hi'`, "hi", true},
+		{"(1, 2)", []interface{}{int64(1), int64(2)}, true},
+		{`{"a": [1]}`, map[string]interface{}{"a": []interface{}{int64(1)}}, true},
+		{"[]", []interface{}{}, true},
+		{"os.getcwd()", nil, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			value, ok := parsePythonLiteral(tc.text)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestExtractPythonSignaturesTypeHints(t *testing.T) {
+	code := `from enum import Enum, auto
+
+
+class Mode(Enum):
+    FAST = "fast"
+    SAFE = "safe"
+
+
+class Level(Enum):
+    LOW = auto()
+    HIGH = auto()
+
+
+def run(count: int, mode: Mode, level: Level, ratio: float = 0.5, tags: list[str] | None = None, verbose=False):
+    pass
+`
+
+	signatures, err := extractPythonSignatures(code)
+	require.NoError(t, err)
+	require.Len(t, signatures, 3)
+
+	run := signatures[2]
+	assert.Equal(t, []string{"count", "mode", "level"}, run.Required)
+
+	props := run.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, "integer", props["count"].(map[string]interface{})["type"])
+	assert.Equal(t, []interface{}{"fast", "safe"}, props["mode"].(map[string]interface{})["enum"])
+	assert.Equal(t, []interface{}{"LOW", "HIGH"}, props["level"].(map[string]interface{})["enum"])
+
+	ratio := props["ratio"].(map[string]interface{})
+	assert.Equal(t, "number", ratio["type"])
+	assert.Equal(t, 0.5, ratio["default"])
+
+	tags := props["tags"].(map[string]interface{})
+	assert.Equal(t, "array", tags["type"])
+	assert.Equal(t, true, tags["nullable"])
+	assert.Nil(t, tags["default"])
+	assert.Contains(t, tags, "default")
+
+	verbose := props["verbose"].(map[string]interface{})
+	assert.Equal(t, "boolean", verbose["type"])
+	assert.Equal(t, false, verbose["default"])
+}
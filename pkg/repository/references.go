@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultReferences and maxReferences bound how many references
+	// find_references collects
+	defaultReferences = 500
+	maxReferences     = 5000
+)
+
+// FindReferences finds where a name is used in the repository
+func FindReferences(roots *Roots) server.ServerTool {
+	tool, handler := findReferencesImpl(roots)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: handler,
+	}
+}
+
+func findReferencesImpl(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return mcp.NewTool("find_references",
+			mcp.WithDescription("Find the references to a function, class, method or other name in the source files of the current repo: every occurrence of the identifier outside comments and string literals, with its path, line and column (paginated). Matching is lexical, so unrelated symbols sharing the name are found too; lines declaring a symbol of that name are flagged as definitions."),
+			withRoot(roots),
+			withRef(),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("Identifier to find. For a name qualified by its class, e.g. 'Cart.add', the last part is looked for"),
+			),
+			mcp.WithString("language",
+				mcp.Description("Only search files written in this language"),
+				mcp.Enum(Languages()...),
+			),
+			mcp.WithArray("include",
+				mcp.Description("Only search files matching one of these globs, e.g. 'src/**/*.py'. A glob without a slash matches a file or directory name at any depth"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithArray("exclude",
+				mcp.Description("Skip files matching any of these globs, e.g. 'tests' or '**/*_test.go'"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithNumber("max_references",
+				mcp.Description(fmt.Sprintf("Maximum number of references to collect across all pages (max %d)", maxReferences)),
+				mcp.DefaultNumber(defaultReferences),
+			),
+			mcp.WithNumber("per_page",
+				mcp.Description("References per page (max 100)"),
+				mcp.DefaultNumber(50),
+			),
+			mcp.WithNumber("page",
+				mcp.Description("Page number"),
+				mcp.DefaultNumber(1),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handleFindReferences(ctx, request, roots)
+		}
+}
+
+// FindReferencesTool returns the tool and handler separately for direct MCP server registration
+func FindReferencesTool(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return findReferencesImpl(roots)
+}
+
+func handleFindReferences(ctx context.Context, req mcp.CallToolRequest, roots *Roots) (*mcp.CallToolResult, error) {
+	name, err := RequiredParam[string](req, "name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if i := strings.LastIndexAny(name, ".:#"); i >= 0 {
+		name = name[i+1:]
+	}
+	if !isIdentifier(name) {
+		return mcp.NewToolResultError(fmt.Sprintf("name must be an identifier, got %q", name)), nil
+	}
+
+	language, err := OptionalParam[string](req, "language")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	include, err := OptionalStringArrayParam(req, "include")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	exclude, err := OptionalStringArrayParam(req, "exclude")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	limit, err := OptionalParam[float64](req, "max_references")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if limit <= 0 {
+		limit = defaultReferences
+	}
+	limit = min(limit, maxReferences)
+
+	perPage, err := OptionalParam[float64](req, "per_page")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 50
+	}
+
+	page, err := OptionalParam[float64](req, "page")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	repo, release, err := requestRepository(req, roots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	index, err := refreshedIndex(ctx, repo)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to index repository: %v", err)), nil
+	}
+
+	search, err := findReferences(ctx, repo, index, name, language, include, exclude, int(limit))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to search repository: %v", err)), nil
+	}
+
+	definitions := make(map[string]bool)
+	for _, symbol := range index.Definitions(name) {
+		definitions[fmt.Sprintf("%s:%d", symbol.Path, symbol.Line)] = true
+	}
+
+	result := ReferenceResults{
+		References:      []Reference{},
+		Page:            int(page),
+		PerPage:         int(perPage),
+		TotalReferences: len(search.References),
+		FilesSearched:   search.FilesSearched,
+		Truncated:       search.Truncated,
+	}
+	start := min((int(page)-1)*int(perPage), len(search.References))
+	end := min(start+int(perPage), len(search.References))
+	if end < len(search.References) {
+		result.NextPage = int(page) + 1
+	}
+	for _, ref := range search.References[start:end] {
+		ref.Definition = definitions[fmt.Sprintf("%s:%d", ref.Path, ref.Line)]
+		result.References = append(result.References, ref)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal references: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// referenceSearch is the outcome of a reference search over a repository
+type referenceSearch struct {
+	References    []Reference
+	FilesSearched int
+	Truncated     bool // the search stopped at the limit
+}
+
+// findReferences searches the source files of a repository matching the
+// language, include and exclude filters, in path order, for up to limit
+// occurrences of an identifier. The files are listed from the up to date
+// symbol index of the repository rather than by walking it again.
+func findReferences(ctx context.Context, repo *RepoFS, index *SymbolIndex, name, language string, include, exclude []string, limit int) (*referenceSearch, error) {
+	result := &referenceSearch{References: []Reference{}}
+	for _, source := range index.sources() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if language != "" && source.Language != language || !matchFilters(source.Path, include, exclude) {
+			continue
+		}
+		result.FilesSearched++
+
+		refs := fileReferences(repo, source, name)
+		room := limit - len(result.References)
+		if len(refs) > room {
+			result.References = append(result.References, refs[:room]...)
+			result.Truncated = true
+			return result, nil
+		}
+		result.References = append(result.References, refs...)
+	}
+	return result, nil
+}
+
+// fileReferences returns the occurrences of an identifier in a source file
+// outside comments and string literals. Files larger than maxSearchFileSize
+// and files that cannot be read have none.
+func fileReferences(repo *RepoFS, source sourceFile, name string) []Reference {
+	info, err := repo.Stat(source.Path)
+	if err != nil || info.Size() > maxSearchFileSize {
+		return nil
+	}
+	content, err := repo.ReadFile(source.Path)
+	if err != nil || !bytes.Contains(content, []byte(name)) {
+		return nil
+	}
+
+	text := string(content)
+	masked := maskSource(source.Language, text)
+	isIdent := braceIsIdentByte
+	if source.Language == "javascript" || source.Language == "typescript" {
+		isIdent = func(c byte) bool { return c == '$' || braceIsIdentByte(c) }
+	}
+
+	var refs []Reference
+	line, lineStart := 1, 0
+	for offset := 0; ; {
+		i := strings.Index(masked[offset:], name)
+		if i < 0 {
+			break
+		}
+		i += offset
+		offset = i + len(name)
+		if i > 0 && isIdent(masked[i-1]) || offset < len(masked) && isIdent(masked[offset]) {
+			continue
+		}
+
+		line += strings.Count(text[lineStart:i], "\n")
+		lineStart = strings.LastIndexByte(text[:i], '\n') + 1
+		lineEnd := strings.IndexByte(text[i:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(text)
+		} else {
+			lineEnd += i
+		}
+		refs = append(refs, Reference{
+			Path:   source.Path,
+			Line:   line,
+			Column: utf8.RuneCountInString(text[lineStart:i]) + 1,
+			Text:   cutLine(text[lineStart:lineEnd]),
+		})
+	}
+	return refs
+}
+
+// isIdentifier reports whether name is a non-empty identifier
+func isIdentifier(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !braceIsIdentByte(name[i]) && name[i] != '$' {
+			return false
+		}
+	}
+	return true
+}
+
+// maskSource replaces the comments and string literals of source code by
+// spaces, keeping line breaks, so only code is left where it was
+func maskSource(language, text string) string {
+	switch language {
+	case "python":
+		return maskPythonSource(text)
+	case "go":
+		return newBraceSource(text, goLiteralEnd, false).masked
+	case "javascript", "typescript":
+		return newBraceSource(text, jsLiteralEnd, false).masked
+	case "rust":
+		return newBraceSource(text, rustLiteralEnd, true).masked
+	case "kotlin":
+		return newBraceSource(text, quotedLiteralEnd, true).masked
+	default:
+		return newBraceSource(text, quotedLiteralEnd, false).masked
+	}
+}
+
+// goLiteralEnd returns the end of a Go string, raw string or rune literal
+func goLiteralEnd(text string, i int) (int, bool) {
+	if text[i] != '`' {
+		return quotedLiteralEnd(text, i)
+	}
+	end := strings.IndexByte(text[i+1:], '`')
+	if end < 0 {
+		return len(text), true
+	}
+	return i + 1 + end + 1, true
+}
+
+// jsLiteralEnd returns the end of a JavaScript string or template literal
+func jsLiteralEnd(text string, i int) (int, bool) {
+	if text[i] != '"' && text[i] != '\'' && text[i] != '`' {
+		return 0, false
+	}
+	return jsStringEnd(text, i), true
+}
+
+// maskPythonSource blanks the comments and string literals of Python code
+func maskPythonSource(text string) string {
+	masked := []byte(text)
+	blank := func(from, to int) {
+		for k := from; k < to; k++ {
+			if masked[k] != '\n' {
+				masked[k] = ' '
+			}
+		}
+	}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '#':
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text) - i
+			}
+			blank(i, i+end)
+			i += end - 1
+		case '"', '\'':
+			end, _ := pythonStringEnd(text, i)
+			blank(i, end)
+			i = end - 1
+		}
+	}
+	return string(masked)
+}
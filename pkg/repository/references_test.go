@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleFindReferences(t *testing.T) {
+	writeTree(t, map[string]string{
+		"shop/cart.py": "# add is documented here\n" +
+			"def add(items, item):\n" +
+			"    \"\"\"Call add(x) to add.\"\"\"\n" +
+			"    items.add(item)  # add it\n" +
+			"    return f'add {item}', add_all, items\n",
+		"shop/main.go": "package shop\n\n" +
+			"// add is unused\n" +
+			"func run() { add(\"add\", `add\n" +
+			"add`, 'a'); add(nil, nil) }\n",
+		"web/app.js":   "const $add = add(1); /* add */ add.call(`${add}`);\n",
+		"lib.rs":       "/* outer /* add */ add */ fn é() { add(r#\"add\"#); }\n",
+		"Main.kt":      "fun main() = add(\"\"\"add\"\"\")\n",
+		"notes/add.md": "add\n",
+	})
+
+	find := func(args map[string]interface{}) ReferenceResults {
+		t.Helper()
+		result, err := handleFindReferences(context.Background(), createMCPRequest(args), nil)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+		var results ReferenceResults
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &results))
+		return results
+	}
+	locations := func(results ReferenceResults) []string {
+		var locs []string
+		for _, ref := range results.References {
+			loc := fmt.Sprintf("%s:%d:%d", ref.Path, ref.Line, ref.Column)
+			if ref.Definition {
+				loc += " definition"
+			}
+			locs = append(locs, loc)
+		}
+		return locs
+	}
+
+	results := find(map[string]interface{}{"name": "add"})
+	assert.Equal(t, []string{
+		"Main.kt:1:14",
+		"lib.rs:1:36",
+		"shop/cart.py:2:5 definition",
+		"shop/cart.py:4:11",
+		"shop/main.go:4:14",
+		"shop/main.go:5:13",
+		"web/app.js:1:14",
+		"web/app.js:1:32",
+	}, locations(results))
+	assert.Equal(t, 5, results.FilesSearched)
+	assert.Equal(t, "    items.add(item)  # add it", results.References[3].Text)
+
+	results = find(map[string]interface{}{"name": "Cart.add", "language": "python"})
+	assert.Equal(t, []string{"shop/cart.py:2:5 definition", "shop/cart.py:4:11"}, locations(results))
+	assert.Equal(t, 1, results.FilesSearched)
+
+	results = find(map[string]interface{}{"name": "add", "exclude": []interface{}{"shop"}, "per_page": float64(2), "page": float64(2)})
+	assert.Equal(t, []string{"web/app.js:1:14", "web/app.js:1:32"}, locations(results))
+	assert.Zero(t, results.NextPage)
+	assert.Equal(t, 4, results.TotalReferences)
+
+	results = find(map[string]interface{}{"name": "add", "max_references": float64(3)})
+	assert.Len(t, results.References, 3)
+	assert.True(t, results.Truncated)
+
+	result, err := handleFindReferences(context.Background(), createMCPRequest(map[string]interface{}{"name": "add()"}), nil)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Equal(t, `name must be an identifier, got "add()"`, getTextResult(t, result).Text)
+}
+
+func TestMaskSource(t *testing.T) {
+	for language, tc := range map[string]struct{ code, want string }{
+		"python":     {"x = '#' + \"\"\"a\nb\"\"\"  # c\n", "x =     +     \n         \n"},
+		"go":         {"s := `a\n\\` // c\n", "s :=   \n       \n"},
+		"javascript": {"f(`a${b}\nc`, /* d */ 'e')\n", "f(      \n  ,            )\n"},
+		"rust":       {"/* a /* b */ c */ x(b'\\'')\n", "                  x(     )\n"},
+		"java":       {"a(\"\\\"\", 'b') /* c\nd */ e\n", "a(    ,    )     \n     e\n"},
+	} {
+		assert.Equal(t, tc.want, maskSource(language, tc.code), language)
+	}
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Extractor extracts public signatures from the source code of one language
+type Extractor interface {
+	// Name is the language identifier accepted by extract_signatures, e.g. "python"
+	Name() string
+	// Extensions lists the file extensions of the language, without the leading dot
+	Extensions() []string
+	// Extract returns the signatures of the public symbols declared in code
+	Extract(code string) ([]FunctionSignature, error)
+}
+
+// ShebangExtractor is implemented by extractors of scripting languages whose
+// files can be recognised by the interpreter named in a "#!" line
+type ShebangExtractor interface {
+	Extractor
+	// Interpreters lists interpreter names without version suffixes, e.g. "python"
+	Interpreters() []string
+}
+
+// ExtractFunc extracts signatures from source code
+type ExtractFunc func(code string) ([]FunctionSignature, error)
+
+type funcExtractor struct {
+	name         string
+	extensions   []string
+	interpreters []string
+	extract      ExtractFunc
+}
+
+// NewExtractor creates an Extractor from a language name, its file extensions,
+// an extract function and, optionally, the interpreters used in shebang lines
+func NewExtractor(name string, extensions []string, extract ExtractFunc, interpreters ...string) Extractor {
+	return &funcExtractor{
+		name:         name,
+		extensions:   extensions,
+		interpreters: interpreters,
+		extract:      extract,
+	}
+}
+
+func (e *funcExtractor) Name() string                                     { return e.name }
+func (e *funcExtractor) Extensions() []string                             { return e.extensions }
+func (e *funcExtractor) Interpreters() []string                           { return e.interpreters }
+func (e *funcExtractor) Extract(code string) ([]FunctionSignature, error) { return e.extract(code) }
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []Extractor // in registration order
+)
+
+func init() {
+	RegisterExtractor(NewExtractor("python", []string{"py", "pyi", "pyw"}, extractPythonSignatures, "python"))
+	RegisterExtractor(NewExtractor("javascript", []string{"js", "jsx", "mjs", "cjs"}, extractJavaScriptSignatures, "node", "nodejs"))
+	RegisterExtractor(NewExtractor("typescript", []string{"ts", "tsx", "mts", "cts"}, extractJavaScriptSignatures, "ts-node", "deno", "bun"))
+	RegisterExtractor(NewExtractor("go", []string{"go"}, extractGoSignatures))
+	RegisterExtractor(NewExtractor("rust", []string{"rs"}, extractRustSignatures, "rust-script"))
+	RegisterExtractor(NewExtractor("java", []string{"java"}, extractJavaSignatures))
+	RegisterExtractor(NewExtractor("kotlin", []string{"kt", "kts"}, extractKotlinSignatures, "kotlin"))
+	RegisterExtractor(NewExtractor("c", []string{"h", "c"}, extractCSignatures))
+	RegisterExtractor(NewExtractor("cpp", []string{"hpp", "hh", "hxx", "h++", "cpp", "cc", "cxx"}, extractCSignatures))
+}
+
+// RegisterExtractor makes an extractor available to extract_signatures and the
+// other repository tools. Registering a language name again replaces the
+// previous extractor, and when several extractors claim the same extension or
+// interpreter the most recently registered one wins. Extractors must be
+// registered before the tools are created, as the tools' language enum is
+// generated from the registry.
+func RegisterExtractor(e Extractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	for i, existing := range extractors {
+		if existing.Name() == e.Name() {
+			extractors = append(extractors[:i], extractors[i+1:]...)
+			break
+		}
+	}
+	extractors = append(extractors, e)
+}
+
+// LookupExtractor returns the extractor registered for a language name
+func LookupExtractor(language string) (Extractor, bool) {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	for _, e := range extractors {
+		if e.Name() == language {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// Languages returns the sorted names of all registered languages
+func Languages() []string {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	names := make([]string, 0, len(extractors))
+	for _, e := range extractors {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectLanguage guesses the language of a file from its extension or, for
+// files without a known extension, from the interpreter named in its shebang
+// line. Either argument may be empty.
+func DetectLanguage(path, code string) (string, bool) {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+
+	if ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext != "" {
+		for i := len(extractors) - 1; i >= 0; i-- {
+			for _, candidate := range extractors[i].Extensions() {
+				if strings.EqualFold(strings.TrimPrefix(candidate, "."), ext) {
+					return extractors[i].Name(), true
+				}
+			}
+		}
+	}
+
+	interpreter := shebangInterpreter(code)
+	if interpreter == "" {
+		return "", false
+	}
+	for i := len(extractors) - 1; i >= 0; i-- {
+		e, ok := extractors[i].(ShebangExtractor)
+		if !ok {
+			continue
+		}
+		for _, candidate := range e.Interpreters() {
+			if candidate == interpreter {
+				return e.Name(), true
+			}
+		}
+	}
+	return "", false
+}
+
+// shebangInterpreter returns the interpreter named in a "#!" first line without
+// its directory or version suffix: "#!/usr/bin/env python3" yields "python"
+func shebangInterpreter(code string) string {
+	if !strings.HasPrefix(code, "#!") {
+		return ""
+	}
+	line := code[2:]
+	if idx := strings.IndexByte(line, '\n'); idx >= 0 {
+		line = line[:idx]
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := fields[0]
+
+	// "#!/usr/bin/env [-S] [VAR=value...] interpreter"
+	if filepath.Base(interpreter) == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+				continue
+			}
+			interpreter = field
+			break
+		}
+	}
+
+	return strings.TrimRight(filepath.Base(interpreter), "0123456789.")
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		code     string
+		expected string
+		ok       bool
+	}{
+		{"python extension", "src/utils.py", "", "python", true},
+		{"upper case extension", "MAIN.GO", "", "go", true},
+		{"tsx", "app/page.tsx", "", "typescript", true},
+		{"mjs", "index.mjs", "", "javascript", true},
+		{"c header", "include/mylib.h", "", "c", true},
+		{"c++ header", "include/canvas.hpp", "", "cpp", true},
+		{"env shebang", "bin/tool", "#!/usr/bin/env python3\nprint(1)\n", "python", true},
+		{"env -S shebang", "", "#!/usr/bin/env -S node --no-warnings\n", "javascript", true},
+		{"versioned interpreter", "", "#!/usr/local/bin/python3.11\n", "python", true},
+		{"extension wins over shebang", "tool.ts", "#!/usr/bin/env node\n", "typescript", true},
+		{"unknown shebang", "run", "#!/bin/sh\necho hi\n", "", false},
+		{"nothing to go on", "", "x = 1", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			language, ok := DetectLanguage(tc.path, tc.code)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, language)
+		})
+	}
+}
+
+// unregisterExtractor removes a language registered by a test
+func unregisterExtractor(t *testing.T, name string) {
+	t.Helper()
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	for i, e := range extractors {
+		if e.Name() == name {
+			extractors = append(extractors[:i], extractors[i+1:]...)
+			return
+		}
+	}
+}
+
+func TestRegisterExtractor(t *testing.T) {
+	RegisterExtractor(NewExtractor("shell", []string{"sh", "bash"}, func(code string) ([]FunctionSignature, error) {
+		return []FunctionSignature{{Name: "deploy", Type: "function", Signature: "deploy()"}}, nil
+	}, "sh", "bash"))
+	t.Cleanup(func() { unregisterExtractor(t, "shell") })
+
+	assert.Contains(t, Languages(), "shell")
+
+	extractor, ok := LookupExtractor("shell")
+	require.True(t, ok)
+	assert.Equal(t, []string{"sh", "bash"}, extractor.Extensions())
+
+	language, ok := DetectLanguage("", "#!/bin/bash\n")
+	require.True(t, ok)
+	assert.Equal(t, "shell", language)
+
+	tool, handler := ExtractSignaturesTool()
+	assert.Contains(t, tool.InputSchema.Properties["language"].(map[string]interface{})["enum"], "shell")
+
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"code": "deploy() { :; }",
+		"path": "scripts/deploy.sh",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var signatures []FunctionSignature
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &signatures))
+	require.Len(t, signatures, 1)
+	assert.Equal(t, "deploy", signatures[0].Name)
+}
+
+func TestHandleExtractSignaturesLanguage(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expectError string
+	}{
+		{
+			name: "explicit language",
+			args: map[string]interface{}{"code": "def f():\n    pass\n", "language": "python"},
+		},
+		{
+			name: "detected from path",
+			args: map[string]interface{}{"code": "def f():\n    pass\n", "path": "f.py"},
+		},
+		{
+			name:        "undetectable",
+			args:        map[string]interface{}{"code": "def f():\n    pass\n"},
+			expectError: "could not detect the language",
+		},
+		{
+			name:        "unknown language",
+			args:        map[string]interface{}{"code": "x", "language": "cobol"},
+			expectError: "unsupported language: cobol",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := handleExtractSignatures(context.Background(), createMCPRequest(tc.args))
+			require.NoError(t, err)
+
+			text := getTextResult(t, result).Text
+			if tc.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tc.expectError)
+				return
+			}
+
+			assert.False(t, result.IsError)
+			var signatures []FunctionSignature
+			require.NoError(t, json.Unmarshal([]byte(text), &signatures))
+			require.Len(t, signatures, 1)
+			assert.Equal(t, "f", signatures[0].Name)
+		})
+	}
+}
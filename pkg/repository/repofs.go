@@ -0,0 +1,414 @@
+package repository
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxArchiveSize bounds the uncompressed size of a .tar.gz archive, which is
+// read into memory
+const maxArchiveSize = 512 << 20
+
+// ErrOutsideRepository is returned for paths, or symlinks, that lead out of
+// the repository
+var ErrOutsideRepository = errors.New("path is outside repository bounds")
+
+// RepoFS is a read-only view of a repository tree that the repository tools
+// read through. Paths are slash-separated and relative to the repository
+// root, as in io/fs. A RepoFS is backed by a local directory, whose symlinks
+// are resolved and checked to stay inside it, a .zip or .tar.gz archive, or
+// any fs.FS.
+type RepoFS struct {
+	fsys   fs.FS
+	dir    string // absolute local directory, "" for archives and other trees
+	name   string
+	rev    string // git revision the tree was read at, "" for the work tree
+	closer io.Closer
+
+	mu      sync.Mutex
+	symbols *SymbolIndex // created on first use by symbolIndex
+	watcher *repoWatcher // set while the repository is watched
+}
+
+// OpenRepo opens the repository at path: a directory, or a .zip, .tar.gz or
+// .tgz archive
+func OpenRepo(path string) (*RepoFS, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return OpenDir(path)
+	}
+	return OpenArchive(path)
+}
+
+// OpenDir opens the repository in a local directory
+func OpenDir(dir string) (*RepoFS, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Symlinks are resolved before checking bounds, so the root is resolved too
+	abs, err = filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &RepoFS{fsys: dirFS(abs), dir: abs, name: filepath.Base(abs)}, nil
+}
+
+// OpenArchive opens the repository in a .zip, .tar.gz or .tgz archive. When
+// every entry is below one top-level directory, as in source archives
+// downloaded from a forge, that directory is the repository root. Symlinks
+// in the archive are not followed.
+func OpenArchive(archive string) (*RepoFS, error) {
+	base := filepath.Base(archive)
+	lower := strings.ToLower(base)
+
+	var repo *RepoFS
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		r, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		repo = &RepoFS{fsys: r, name: base[:len(base)-len(".zip")], closer: r}
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		fsys, err := readTarGz(archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		name := base[:len(base)-len(".tgz")]
+		if strings.HasSuffix(lower, ".tar.gz") {
+			name = base[:len(base)-len(".tar.gz")]
+		}
+		repo = &RepoFS{fsys: fsys, name: name}
+	default:
+		return nil, fmt.Errorf("unsupported repository %s: not a directory, .zip, .tar.gz or .tgz archive", archive)
+	}
+
+	if top, ok := soleDirectory(repo.fsys); ok {
+		sub, err := fs.Sub(repo.fsys, top)
+		if err != nil {
+			return nil, err
+		}
+		repo.fsys = sub
+		repo.name = top
+	}
+	return repo, nil
+}
+
+// NewRepoFS returns a repository backed by any file system, such as an
+// embed.FS or an in-memory tree, named name
+func NewRepoFS(fsys fs.FS, name string) *RepoFS {
+	return &RepoFS{fsys: fsys, name: name}
+}
+
+// Open opens the named file
+func (r *RepoFS) Open(name string) (fs.File, error) {
+	return r.fsys.Open(name)
+}
+
+// Stat returns the file info of the named file
+func (r *RepoFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(r.fsys, name)
+}
+
+// ReadFile reads the named file
+func (r *RepoFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(r.fsys, name)
+}
+
+// ReadDir reads the named directory, sorted by file name
+func (r *RepoFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(r.fsys, name)
+}
+
+// Dir returns the local directory of the repository, or "" when it is not
+// on disk
+func (r *RepoFS) Dir() string {
+	return r.dir
+}
+
+// Name returns the name of the repository: the base name of its directory,
+// or of its archive without the extension
+func (r *RepoFS) Name() string {
+	return r.name
+}
+
+// Revision returns the git revision the repository was read at, or "" when
+// it is not read from git history
+func (r *RepoFS) Revision() string {
+	return r.rev
+}
+
+// Close releases the archive the repository is read from
+func (r *RepoFS) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// localPath returns the local path of a file or directory of the repository,
+// which need not exist yet, for writing it. The part of the path that exists
+// must not lead out of the repository.
+func (r *RepoFS) localPath(name string) (string, error) {
+	if r.dir == "" {
+		return "", fmt.Errorf("repository %s is not a local directory", r.name)
+	}
+	for existing := name; existing != "."; existing = path.Dir(existing) {
+		_, err := dirFS(r.dir).resolve("stat", existing)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		// A dangling symlink could still point anywhere
+		if _, err := os.Lstat(filepath.Join(r.dir, filepath.FromSlash(existing))); err == nil {
+			return "", &fs.PathError{Op: "stat", Path: existing, Err: ErrOutsideRepository}
+		}
+	}
+	return filepath.Join(r.dir, filepath.FromSlash(name)), nil
+}
+
+// cleanRepoPath turns a repository-relative path given by a caller, with an
+// optional leading slash, into an fs.FS path, refusing paths that leave the
+// repository
+func cleanRepoPath(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimLeft(filepath.ToSlash(name), "/"))
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrOutsideRepository
+	}
+	return cleaned, nil
+}
+
+// dirFS is a local directory whose symlinks are resolved before each access
+// and must lead to a path inside it. Unlike os.DirFS, a symlink pointing out
+// of the directory cannot be used to read other files.
+type dirFS string
+
+// resolve returns the local path of name after resolving its symlinks
+func (d dirFS) resolve(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(string(d), filepath.FromSlash(name)))
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	rel, err := filepath.Rel(string(d), resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &fs.PathError{Op: op, Path: name, Err: ErrOutsideRepository}
+	}
+	return resolved, nil
+}
+
+func (d dirFS) Open(name string) (fs.File, error) {
+	resolved, err := d.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(resolved)
+}
+
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	resolved, err := d.resolve("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(resolved)
+}
+
+// soleDirectory returns the top-level directory of a tree holding nothing else
+func soleDirectory(fsys fs.FS) (string, bool) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return "", false
+	}
+	return entries[0].Name(), true
+}
+
+// readTarGz reads the regular files and directories of a .tar.gz archive
+// into memory. Entries with invalid paths, links and special files are
+// skipped.
+func readTarGz(archive string) (fs.FS, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tree := newMemFS()
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return tree, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if name == "" || !fs.ValidPath(name) {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			tree.addDir(name, header.ModTime)
+		case tar.TypeReg:
+			total += header.Size
+			if total > maxArchiveSize {
+				return nil, fmt.Errorf("archive holds more than %d bytes", maxArchiveSize)
+			}
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			tree.addFile(name, content, header.FileInfo().Mode().Perm(), header.ModTime)
+		}
+	}
+}
+
+// memFS is an in-memory tree of files and directories
+type memFS struct {
+	nodes map[string]*memNode // by path, "." is the root
+}
+
+type memNode struct {
+	name     string
+	content  []byte
+	mode     fs.FileMode
+	modTime  time.Time
+	children map[string]*memNode // nil for files
+}
+
+func newMemFS() *memFS {
+	root := &memNode{name: ".", mode: fs.ModeDir | 0o755, children: make(map[string]*memNode)}
+	return &memFS{nodes: map[string]*memNode{".": root}}
+}
+
+// addDir adds a directory and its missing parents
+func (m *memFS) addDir(name string, modTime time.Time) *memNode {
+	if node, ok := m.nodes[name]; ok {
+		if node.children != nil && !modTime.IsZero() {
+			node.modTime = modTime
+		}
+		return node
+	}
+	parent := m.addDir(path.Dir(name), time.Time{})
+	node := &memNode{name: path.Base(name), mode: fs.ModeDir | 0o755, modTime: modTime, children: make(map[string]*memNode)}
+	if parent.children != nil {
+		parent.children[node.name] = node
+	}
+	m.nodes[name] = node
+	return node
+}
+
+// addFile adds a file and its missing parent directories
+func (m *memFS) addFile(name string, content []byte, perm fs.FileMode, modTime time.Time) {
+	parent := m.addDir(path.Dir(name), time.Time{})
+	if parent.children == nil {
+		return
+	}
+	node := &memNode{name: path.Base(name), content: content, mode: perm, modTime: modTime}
+	parent.children[node.name] = node
+	m.nodes[name] = node
+}
+
+func (m *memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	node, ok := m.nodes[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if node.children == nil {
+		return &memFile{node: node, Reader: strings.NewReader(string(node.content))}, nil
+	}
+
+	entries := make([]fs.DirEntry, 0, len(node.children))
+	for _, child := range node.children {
+		entries = append(entries, fs.FileInfoToDirEntry(memInfo{child}))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return &memDir{node: node, entries: entries}, nil
+}
+
+// memInfo is the fs.FileInfo of a memNode
+type memInfo struct{ node *memNode }
+
+func (i memInfo) Name() string       { return i.node.name }
+func (i memInfo) Size() int64        { return int64(len(i.node.content)) }
+func (i memInfo) Mode() fs.FileMode  { return i.node.mode }
+func (i memInfo) ModTime() time.Time { return i.node.modTime }
+func (i memInfo) IsDir() bool        { return i.node.children != nil }
+func (i memInfo) Sys() any           { return nil }
+
+type memFile struct {
+	node *memNode
+	*strings.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return memInfo{f.node}, nil }
+func (f *memFile) Close() error               { return nil }
+
+type memDir struct {
+	node    *memNode
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return memInfo{d.node}, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.node.name, Err: errors.New("is a directory")}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoFSSymlinks(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"real.txt":       "inside\n",
+		"src/app.py":     "def run():\n    pass\n",
+		"docs/guide.txt": "guide\n",
+	})
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret\n"), 0o600))
+
+	// A sibling directory sharing the repository path as a prefix
+	sibling := root + "-evil"
+	require.NoError(t, os.Mkdir(sibling, 0o755))
+	t.Cleanup(func() { _ = os.RemoveAll(sibling) })
+	require.NoError(t, os.WriteFile(filepath.Join(sibling, "secret.txt"), []byte("secret\n"), 0o600))
+
+	require.NoError(t, os.Symlink("real.txt", filepath.Join(root, "link.txt")))
+	require.NoError(t, os.Symlink("../src", filepath.Join(root, "docs", "src")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "escape.txt")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+	require.NoError(t, os.Symlink("missing.txt", filepath.Join(root, "dangling.txt")))
+
+	content := func(path string) *mcp.CallToolResult {
+		t.Helper()
+		result, err := handleGetFileContent(context.Background(), createMCPRequest(map[string]interface{}{"path": path}), nil, defaultContentWindowSize)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("symlinks inside the repository are read", func(t *testing.T) {
+		result := content("link.txt")
+		require.False(t, result.IsError)
+		assert.Equal(t, "inside\n", getTextResult(t, result).Text)
+
+		result = content("docs/src/app.py")
+		require.False(t, result.IsError)
+		assert.Equal(t, "def run():\n    pass\n", getTextResult(t, result).Text)
+	})
+
+	t.Run("paths leading out of the repository are refused", func(t *testing.T) {
+		for _, path := range []string{
+			"escape.txt",
+			"escape/secret.txt",
+			"../" + filepath.Base(sibling) + "/secret.txt",
+			"src/../../" + filepath.Base(sibling) + "/secret.txt",
+		} {
+			result := content(path)
+			require.True(t, result.IsError, path)
+			assert.Contains(t, getTextResult(t, result).Text, "path is outside repository bounds", path)
+		}
+	})
+
+	t.Run("walks skip links out of the repository", func(t *testing.T) {
+		var files []string
+		require.NoError(t, walkRepository(openRepo(t, root), func(relPath string) error {
+			files = append(files, relPath)
+			return nil
+		}))
+		assert.Equal(t, []string{"docs/guide.txt", "link.txt", "real.txt", "src/app.py"}, files)
+	})
+
+	t.Run("writes refuse links out of the repository", func(t *testing.T) {
+		repo := openRepo(t, root)
+		dir, err := repo.localPath("out/server")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(repo.Dir(), "out", "server"), dir)
+
+		_, err = repo.localPath("escape/server")
+		assert.ErrorIs(t, err, ErrOutsideRepository)
+		_, err = repo.localPath("dangling.txt/server")
+		assert.ErrorIs(t, err, ErrOutsideRepository)
+	})
+}
+
+var archiveFiles = []struct{ name, content string }{
+	{"shop-1.0/README.md", "# Shop\n"},
+	{"shop-1.0/shop/cart.py", "def add_item(name: str, quantity: int = 1) -> None:\n    \"\"\"Add an item to the cart\"\"\"\n"},
+	{"shop-1.0/logo.png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"},
+}
+
+func writeZip(t *testing.T, path string) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, file := range archiveFiles {
+		f, err := w.Create(file.name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+}
+
+func writeTarGz(t *testing.T, path string) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	require.NoError(t, w.WriteHeader(&tar.Header{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header", PAXRecords: map[string]string{"comment": "abc"}}))
+	require.NoError(t, w.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "shop-1.0/", Mode: 0o755}))
+	for _, file := range archiveFiles {
+		require.NoError(t, w.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: file.name, Mode: 0o644, Size: int64(len(file.content))}))
+		_, err := w.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	// Links are not followed
+	require.NoError(t, w.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "shop-1.0/passwd", Linkname: "/etc/passwd"}))
+	require.NoError(t, w.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+}
+
+func TestRepoFSArchives(t *testing.T) {
+	dir := t.TempDir()
+	writeZip(t, filepath.Join(dir, "shop.zip"))
+	writeTarGz(t, filepath.Join(dir, "shop.tar.gz"))
+
+	for _, archive := range []string{"shop.zip", "shop.tar.gz"} {
+		t.Run(archive, func(t *testing.T) {
+			roots, err := ParseRoots([]string{filepath.Join(dir, archive)})
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = roots.Close() })
+
+			// The top-level directory of the archive is the repository root
+			assert.Equal(t, []string{"shop-1.0"}, roots.Names())
+			repo, err := roots.Resolve("")
+			require.NoError(t, err)
+			assert.Equal(t, "", repo.Dir())
+
+			result, err := handleGetFileList(context.Background(), createMCPRequest(map[string]interface{}{}), roots)
+			require.NoError(t, err)
+			var files []FileEntry
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &files))
+			assert.Equal(t, []FileEntry{
+				{Path: "README.md", Size: 7, Language: "markdown"},
+				{Path: "logo.png", Size: 16},
+				{Path: "shop/cart.py", Size: 86, Language: "python"},
+			}, files)
+
+			result, err = handleGetFileContent(context.Background(), createMCPRequest(map[string]interface{}{"path": "/README.md"}), roots, defaultContentWindowSize)
+			require.NoError(t, err)
+			assert.Equal(t, "# Shop\n", getTextResult(t, result).Text)
+
+			result, err = handleGetFileContent(context.Background(), createMCPRequest(map[string]interface{}{"path": "logo.png"}), roots, defaultContentWindowSize)
+			require.NoError(t, err)
+			require.Len(t, result.Content, 2)
+			resource := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+			assert.Equal(t, "repo://shop-1.0/logo.png", resource.URI)
+
+			result, err = handleGetFileContent(context.Background(), createMCPRequest(map[string]interface{}{"path": "../shop.zip"}), roots, defaultContentWindowSize)
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Equal(t, "path is outside repository bounds", getTextResult(t, result).Text)
+
+			bundle, err := Convert(context.Background(), repo, ConvertOptions{})
+			require.NoError(t, err)
+			require.Len(t, bundle.Tools, 1)
+			assert.Equal(t, "add_item", bundle.Tools[0].Function.Name)
+		})
+	}
+}
+
+func TestNewRepoFS(t *testing.T) {
+	repo := NewRepoFS(fstest.MapFS{
+		"go.mod":          {Data: []byte("module example.com/calc\n\ngo 1.23\n")},
+		"calc/calc.go":    {Data: []byte("package calc\n\n// Add adds two numbers\nfunc Add(a, b int) int { return a + b }\n")},
+		"node_modules/x":  {Data: []byte("module.exports = {}\n")},
+		"calc/.hidden.go": {Data: []byte("package calc\n")},
+	}, "calc")
+	assert.Equal(t, "calc", repo.Name())
+
+	var files []string
+	require.NoError(t, walkRepository(repo, func(relPath string) error {
+		files = append(files, relPath)
+		return nil
+	}))
+	assert.Equal(t, []string{"calc/calc.go", "go.mod"}, files)
+
+	project, err := Generate(context.Background(), repo, GenerateOptions{Language: "go"})
+	require.NoError(t, err)
+	assert.Equal(t, "calc", project.Name)
+	require.Len(t, project.Tools, 1)
+	assert.Equal(t, "Add", project.Tools[0].Symbol)
+
+	_, err = Generate(context.Background(), repo, GenerateOptions{Language: "go", OutputDir: "/tmp/server"})
+	assert.ErrorContains(t, err, "is not a local directory")
+
+	_, _, err = LiveTools(context.Background(), repo, LiveOptions{})
+	assert.ErrorContains(t, err, "is not a local directory")
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Root is a named repository the repository tools operate on: a directory or
+// a source archive
+type Root struct {
+	Name string `json:"name"`
+	Path string `json:"path"` // absolute
+
+	repo *RepoFS
+}
+
+// Roots are the repository directories the repository tools may operate on,
+// selected by name with the root argument of each tool. The first root is
+// used when a call names none. Without any roots, and for a nil *Roots, the
+// tools operate on the working directory. Its repository, and so its symbol
+// index, is kept for the calls that follow as long as the working directory
+// stays the same, except for a nil *Roots, which opens it on every call.
+type Roots struct {
+	roots []Root
+
+	mu     sync.Mutex
+	cwd    *RepoFS // the working directory, pinned while it is watched
+	cwdDir string  // the path cwd was opened at
+}
+
+// ParseRoots parses root specs, each either a path or name=path, where the
+// path is a directory or a .zip, .tar.gz or .tgz archive. A spec may hold
+// several roots separated by the path list separator, as in PATH, so that
+// they fit in one environment variable. Roots without a name are named after
+// their repository. Every path must exist.
+func ParseRoots(specs []string) (*Roots, error) {
+	roots := &Roots{}
+	for _, spec := range specs {
+		for _, entry := range filepath.SplitList(spec) {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			name, dir, found := strings.Cut(entry, "=")
+			if !found {
+				name, dir = "", entry
+			}
+
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve repository root %s: %w", dir, err)
+			}
+			if _, err := os.Stat(abs); err != nil {
+				return nil, fmt.Errorf("invalid repository root: %w", err)
+			}
+			repo, err := OpenRepo(abs)
+			if err != nil {
+				return nil, fmt.Errorf("invalid repository root %s: %w", dir, err)
+			}
+
+			if name == "" {
+				name = repo.Name()
+			}
+			if _, ok := roots.lookup(name); ok {
+				repo.Close()
+				return nil, fmt.Errorf("repository root %s is defined twice", name)
+			}
+			roots.roots = append(roots.roots, Root{Name: name, Path: abs, repo: repo})
+		}
+	}
+	return roots, nil
+}
+
+// List returns the configured roots, the default first
+func (r *Roots) List() []Root {
+	if r == nil {
+		return nil
+	}
+	return append([]Root(nil), r.roots...)
+}
+
+// Names returns the names of the configured roots, the default first
+func (r *Roots) Names() []string {
+	var names []string
+	for _, root := range r.List() {
+		names = append(names, root.Name)
+	}
+	return names
+}
+
+// Resolve returns the repository of the named root, or of the default root
+// when name is empty
+func (r *Roots) Resolve(name string) (*RepoFS, error) {
+	if len(r.List()) == 0 {
+		if name != "" {
+			return nil, fmt.Errorf("unknown root: %s, no named roots are configured", name)
+		}
+		return r.workingDir()
+	}
+
+	if name == "" {
+		return r.roots[0].repo, nil
+	}
+	if root, ok := r.lookup(name); ok {
+		return root.repo, nil
+	}
+	return nil, fmt.Errorf("unknown root: %s, pass one of: %s", name, strings.Join(r.Names(), ", "))
+}
+
+// Watch keeps the file lists and symbol indexes of the roots that are local
+// directories up to date until ctx is done, as RepoFS.Watch does; archives
+// do not change. Without roots, the working directory is watched and used
+// from then on, even if the process changes directory. onChange, if not
+// nil, is called with the repository whose files changed and their paths.
+func (r *Roots) Watch(ctx context.Context, onChange func(repo *RepoFS, changed []string)) error {
+	repos := make([]*RepoFS, 0, len(r.roots))
+	for _, root := range r.roots {
+		if root.repo.Dir() != "" {
+			repos = append(repos, root.repo)
+		}
+	}
+	if len(r.roots) == 0 {
+		repo, err := r.workingDir()
+		if err != nil {
+			return err
+		}
+		repos = append(repos, repo)
+	}
+
+	for _, repo := range repos {
+		err := repo.Watch(ctx, func(changed []string) {
+			if onChange != nil {
+				onChange(repo, changed)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases the archives of the roots
+func (r *Roots) Close() error {
+	var errs []error
+	for _, root := range r.List() {
+		errs = append(errs, root.repo.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// workingDir returns the repository of the working directory, reusing the
+// one opened before unless the process changed directory since. A watched
+// working directory is used even then.
+func (r *Roots) workingDir() (*RepoFS, error) {
+	if r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.cwd != nil && r.cwd.watched() {
+			return r.cwd, nil
+		}
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if r == nil {
+		return OpenDir(dir)
+	}
+	if r.cwd != nil && r.cwdDir == dir {
+		return r.cwd, nil
+	}
+	repo, err := OpenDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	r.cwd, r.cwdDir = repo, dir
+	return repo, nil
+}
+
+func (r *Roots) lookup(name string) (Root, bool) {
+	for _, root := range r.roots {
+		if root.Name == name {
+			return root, true
+		}
+	}
+	return Root{}, false
+}
+
+// withRoot adds the root argument selecting the repository a tool operates on
+func withRoot(roots *Roots) mcp.ToolOption {
+	names := roots.Names()
+	if len(names) == 0 {
+		return mcp.WithString("root",
+			mcp.Description("Name of the repository root to operate on; defaults to the working directory of the server"),
+		)
+	}
+	return mcp.WithString("root",
+		mcp.Description(fmt.Sprintf("Name of the repository root to operate on; defaults to %s", names[0])),
+		mcp.Enum(names...),
+	)
+}
+
+// requestRoot resolves the repository selected by the root argument of a request
+func requestRoot(req mcp.CallToolRequest, roots *Roots) (*RepoFS, error) {
+	name, err := OptionalParam[string](req, "root")
+	if err != nil {
+		return nil, err
+	}
+	return roots.Resolve(name)
+}
+
+// withRef adds the ref argument selecting the git revision a tool reads
+func withRef() mcp.ToolOption {
+	return mcp.WithString("ref",
+		mcp.Description("Git revision to read instead of the working tree, e.g. a tag, a branch, a commit or HEAD~3; read from the local .git directory"),
+	)
+}
+
+// requestRepository resolves the repository selected by the root argument
+// of a request, at the git revision of its ref argument when one is given.
+// The returned function releases the revision once the request is done.
+func requestRepository(req mcp.CallToolRequest, roots *Roots) (*RepoFS, func(), error) {
+	repo, err := requestRoot(req, roots)
+	if err != nil {
+		return nil, nil, err
+	}
+	ref, err := OptionalParam[string](req, "ref")
+	if err != nil {
+		return nil, nil, err
+	}
+	if ref == "" {
+		return repo, func() {}, nil
+	}
+	at, err := repo.AtRevision(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	return at, func() { at.Close() }, nil
+}
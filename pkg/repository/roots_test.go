@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoots(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"api", "web"} {
+		require.NoError(t, os.Mkdir(filepath.Join(base, dir), 0o755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(base, "notes.txt"), nil, 0o600))
+
+	api, web := filepath.Join(base, "api"), filepath.Join(base, "web")
+
+	t.Run("names and defaults", func(t *testing.T) {
+		roots, err := ParseRoots([]string{api, "frontend=" + web})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api", "frontend"}, roots.Names())
+		assert.Equal(t, web, roots.List()[1].Path)
+
+		repo, err := roots.Resolve("")
+		require.NoError(t, err)
+		assert.Equal(t, api, repo.Dir())
+
+		repo, err = roots.Resolve("frontend")
+		require.NoError(t, err)
+		assert.Equal(t, web, repo.Dir())
+
+		_, err = roots.Resolve("docs")
+		assert.EqualError(t, err, "unknown root: docs, pass one of: api, frontend")
+	})
+
+	t.Run("path list", func(t *testing.T) {
+		roots, err := ParseRoots([]string{api + string(filepath.ListSeparator) + "ui=" + web})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api", "ui"}, roots.Names())
+	})
+
+	t.Run("invalid roots", func(t *testing.T) {
+		_, err := ParseRoots([]string{api, "api=" + web})
+		assert.EqualError(t, err, "repository root api is defined twice")
+
+		_, err = ParseRoots([]string{filepath.Join(base, "notes.txt")})
+		assert.ErrorContains(t, err, "not a directory, .zip, .tar.gz or .tgz archive")
+
+		_, err = ParseRoots([]string{filepath.Join(base, "missing")})
+		assert.ErrorContains(t, err, "invalid repository root")
+	})
+
+	t.Run("working directory", func(t *testing.T) {
+		root := writeTree(t, map[string]string{"main.go": "package main\n"})
+		wd, err := filepath.EvalSymlinks(root)
+		require.NoError(t, err)
+
+		for _, roots := range []*Roots{nil, {}} {
+			repo, err := roots.Resolve("")
+			require.NoError(t, err)
+			assert.Equal(t, wd, repo.Dir())
+
+			_, err = roots.Resolve("api")
+			assert.EqualError(t, err, "unknown root: api, no named roots are configured")
+		}
+	})
+
+	t.Run("working directory is reused", func(t *testing.T) {
+		writeTree(t, map[string]string{"main.go": "package main\n"})
+		roots := &Roots{}
+		first, err := roots.Resolve("")
+		require.NoError(t, err)
+		second, err := roots.Resolve("")
+		require.NoError(t, err)
+		assert.Same(t, first, second)
+
+		// Changing directory opens the new working directory
+		other := writeTree(t, map[string]string{"app.py": "pass\n"})
+		wd, err := filepath.EvalSymlinks(other)
+		require.NoError(t, err)
+		third, err := roots.Resolve("")
+		require.NoError(t, err)
+		assert.NotSame(t, first, third)
+		assert.Equal(t, wd, third.Dir())
+
+		// A nil *Roots cannot keep it
+		var none *Roots
+		fourth, err := none.Resolve("")
+		require.NoError(t, err)
+		assert.NotSame(t, third, fourth)
+	})
+}
+
+func TestToolsUseRoot(t *testing.T) {
+	base := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(base, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	write("api/server.py", "def serve(port: int):\n    pass\n")
+	write("web/app.js", "function render(view) {}\n")
+
+	roots, err := ParseRoots([]string{filepath.Join(base, "api"), "ui=" + filepath.Join(base, "web")})
+	require.NoError(t, err)
+
+	tool, handler := GetFileListTool(roots)
+	assert.Equal(t, []string{"api", "ui"}, tool.InputSchema.Properties["root"].(map[string]interface{})["enum"])
+
+	t.Run("default root", func(t *testing.T) {
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{}))
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"path": "server.py", "size": 31, "language": "python"}]`, getTextResult(t, result).Text)
+	})
+
+	t.Run("named root", func(t *testing.T) {
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{"root": "ui"}))
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"path": "app.js", "size": 25, "language": "javascript"}]`, getTextResult(t, result).Text)
+
+		_, contentHandler := GetFileContentTool(roots, 0)
+		result, err = contentHandler(context.Background(), createMCPRequest(map[string]interface{}{"root": "ui", "path": "app.js"}))
+		require.NoError(t, err)
+		assert.Equal(t, "function render(view) {}\n", getTextResult(t, result).Text)
+
+		_, convertHandler := ConvertRepositoryTool(roots)
+		result, err = convertHandler(context.Background(), createMCPRequest(map[string]interface{}{"root": "ui"}))
+		require.NoError(t, err)
+		assert.Contains(t, getTextResult(t, result).Text, `"render"`)
+	})
+
+	t.Run("unknown root", func(t *testing.T) {
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{"root": "docs"}))
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		assert.Equal(t, "unknown root: docs, pass one of: api, ui", getTextResult(t, result).Text)
+	})
+}
@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// rustSource is Rust code prepared for scanning
+type rustSource struct {
+	*braceSource
+}
+
+// rustItem is a declaration found at module, impl or trait level
+type rustItem struct {
+	Kind   string // "fn", "struct", "enum", "trait", "impl", "mod", "type", ...
+	Name   string // for impl blocks, the implementing type
+	Trait  string // for impl blocks, the implemented trait if any
+	Public bool
+	Header string   // declaration without attributes or body, whitespace collapsed
+	Attrs  []string // attribute contents, e.g. "derive(Debug)"
+	Doc    string
+	Line   int
+	Body   [2]int // offsets of the block contents; both zero when there is no block
+}
+
+var (
+	rustItemRE  = regexp.MustCompile(`^(pub(?:\s*\([^)]*\))?\s+)?((?:(?:default|const|async|unsafe|auto|extern\s*(?:"[^"]*")?)\s+)*)(fn|struct|enum|union|trait|impl|mod|type|const|static|use|macro_rules!)(?:\s+|\b|$)(.*)$`)
+	rustIdentRE = regexp.MustCompile(`^(?:r#)?([A-Za-z_][A-Za-z0-9_]*)`)
+
+	// self, mut self, &self, &'a mut self and self: Box<Self>
+	rustReceiverRE = regexp.MustCompile(`^&?\s*(?:'\w+\s+)?(?:mut\s+)?self\b`)
+)
+
+// newRustSource blanks out comments, which nest in Rust, and literals
+func newRustSource(text string) *rustSource {
+	return &rustSource{newBraceSource(text, rustLiteralEnd, true)}
+}
+
+// rustLiteralEnd returns the end of the string, byte string, raw string or
+// char literal starting at i. Lifetimes such as 'a are not literals.
+func rustLiteralEnd(text string, i int) (int, bool) {
+	j := i
+	if text[j] == 'b' || text[j] == 'c' {
+		j++
+	}
+	if j < len(text) && text[j] == '\'' && (j == i || text[i] == 'b') {
+		return rustCharEnd(text, j)
+	}
+
+	raw := false
+	if j < len(text) && text[j] == 'r' {
+		raw = true
+		j++
+	}
+	hashes := 0
+	for raw && j < len(text) && text[j] == '#' {
+		hashes++
+		j++
+	}
+	if j >= len(text) || text[j] != '"' {
+		return 0, false
+	}
+
+	if raw {
+		closing := "\"" + strings.Repeat("#", hashes)
+		idx := strings.Index(text[j+1:], closing)
+		if idx < 0 {
+			return len(text), true
+		}
+		return j + 1 + idx + len(closing), true
+	}
+	for k := j + 1; k < len(text); k++ {
+		switch text[k] {
+		case '\\':
+			k++
+		case '"':
+			return k + 1, true
+		}
+	}
+	return len(text), true
+}
+
+// rustCharEnd returns the end of the char literal starting at the quote at i
+func rustCharEnd(text string, i int) (int, bool) {
+	if i+1 >= len(text) {
+		return 0, false
+	}
+	if text[i+1] == '\\' {
+		if idx := strings.IndexByte(text[i+3:], '\''); i+3 < len(text) && idx >= 0 {
+			return i + 3 + idx + 1, true
+		}
+		return 0, false
+	}
+	_, size := utf8.DecodeRuneInString(text[i+1:])
+	if end := i + 1 + size; end < len(text) && text[end] == '\'' {
+		return end + 1, true
+	}
+	return 0, false
+}
+
+// items parses the declarations between start and end
+func (s *rustSource) items(start, end int) []rustItem {
+	var items []rustItem
+	for _, decl := range s.declarations(start, end, false) {
+		item := s.parseItem(decl.Start, decl.HeaderEnd)
+		item.Doc = decl.Doc
+		item.Body = decl.Body
+		items = append(items, item)
+	}
+	return items
+}
+
+// parseItem classifies the header between start and end
+func (s *rustSource) parseItem(start, end int) rustItem {
+	// Like decorated Python definitions, items start at their first attribute
+	item := rustItem{Line: s.lineAt(start)}
+
+	pos := start
+	for {
+		for pos < end && strings.ContainsRune(" \t\r\n", rune(s.masked[pos])) {
+			pos++
+		}
+		if !strings.HasPrefix(s.masked[pos:end], "#[") && !strings.HasPrefix(s.masked[pos:end], "#![") {
+			break
+		}
+		open := strings.IndexByte(s.masked[pos:end], '[') + pos
+		closing := braceMatchingBracket(s.masked, open, end)
+		if s.masked[pos+1] == '[' {
+			item.Attrs = append(item.Attrs, strings.TrimSpace(s.code[open+1:closing]))
+		}
+		pos = closing + 1
+	}
+
+	item.Header = strings.Join(strings.Fields(s.code[pos:end]), " ")
+	m := rustItemRE.FindStringSubmatch(item.Header)
+	if m == nil {
+		return item
+	}
+
+	item.Public = strings.TrimSpace(m[1]) == "pub"
+	item.Kind = m[3]
+	rest := m[4]
+
+	if item.Kind == "impl" {
+		rest = strings.TrimSpace(rest)
+		if strings.HasPrefix(rest, "<") {
+			rest = strings.TrimSpace(rest[braceMatchingBracket(rest, 0, len(rest))+1:])
+		}
+		if idx := braceScanTo(rest, " where "); idx >= 0 {
+			rest = rest[:idx]
+		}
+		typ := rest
+		if idx := braceScanTo(rest, " for "); idx >= 0 {
+			item.Trait = strings.TrimSpace(rest[:idx])
+			typ = rest[idx+len(" for "):]
+		}
+		item.Name = rustTypeName(typ)
+		return item
+	}
+
+	if ident := rustIdentRE.FindStringSubmatch(rest); ident != nil {
+		item.Name = ident[1]
+	}
+	return item
+}
+
+// rustTypeName returns the bare name of a type path, e.g. "Bar" for "&mut foo::Bar<T>"
+func rustTypeName(typ string) string {
+	typ = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(typ), "&"))
+	typ = strings.TrimPrefix(typ, "mut ")
+	typ = strings.TrimPrefix(typ, "dyn ")
+	if idx := strings.IndexByte(typ, '<'); idx >= 0 {
+		typ = typ[:idx]
+	}
+	if idx := strings.LastIndex(typ, "::"); idx >= 0 {
+		typ = typ[idx+2:]
+	}
+	return strings.TrimSpace(typ)
+}
+
+// parseRustDoc parses rustdoc Markdown, reading the conventional "# Arguments",
+// "# Returns", "# Errors", "# Panics" and "# Examples" sections
+func parseRustDoc(text string) docComment {
+	var doc docComment
+	var prose []string
+	section := ""
+	var example []string
+	inCode := false
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if section == "examples" && inCode && len(example) > 0 {
+				doc.Examples = append(doc.Examples, strings.Join(example, "\n"))
+				example = nil
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			// Lines starting with "# " are hidden from rendered examples
+			if section == "examples" && trimmed != "#" && !strings.HasPrefix(trimmed, "# ") {
+				example = append(example, line)
+			} else if section == "" {
+				prose = append(prose, line)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "# ") {
+			section = strings.ToLower(strings.TrimSpace(trimmed[2:]))
+			if kind, ok := docSections[section]; ok {
+				section = kind
+			} else if section != "panics" && section != "errors" && section != "safety" {
+				section = "other"
+			}
+			continue
+		}
+
+		switch section {
+		case "":
+			prose = append(prose, line)
+		case "params":
+			// * `name` - description
+			item := strings.TrimSpace(strings.TrimLeft(trimmed, "*-"))
+			if strings.HasPrefix(item, "`") {
+				if end := strings.Index(item[1:], "`"); end >= 0 {
+					name := item[1 : end+1]
+					description := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(item[end+2:]), "-:"))
+					doc.Params = append(doc.Params, docParam{Name: name, Description: strings.TrimSpace(description)})
+					continue
+				}
+			}
+			if trimmed != "" && len(doc.Params) > 0 {
+				last := &doc.Params[len(doc.Params)-1]
+				last.Description = strings.TrimSpace(last.Description + " " + trimmed)
+			}
+		case "returns":
+			doc.Returns = strings.TrimSpace(doc.Returns + " " + trimmed)
+		case "errors", "panics":
+			if trimmed == "" {
+				continue
+			}
+			name := strings.ToUpper(section[:1]) + section[1:]
+			if n := len(doc.Raises); n > 0 && doc.Raises[n-1].Name == name {
+				doc.Raises[n-1].Description += " " + trimmed
+				continue
+			}
+			doc.Raises = append(doc.Raises, docParam{Name: name, Description: trimmed})
+		}
+	}
+
+	doc.setProse(prose)
+	return doc
+}
+
+// rustAttr returns the arguments of the named attribute, e.g. the contents of
+// the parentheses of #[deprecated(note = "...")]
+func rustAttr(attrs []string, name string) (string, bool) {
+	for _, attr := range attrs {
+		if attr == name {
+			return "", true
+		}
+		if strings.HasPrefix(attr, name) {
+			rest := strings.TrimSpace(attr[len(name):])
+			if strings.HasPrefix(rest, "(") && strings.HasSuffix(rest, ")") {
+				return rest[1 : len(rest)-1], true
+			}
+			if strings.HasPrefix(rest, "=") {
+				return strings.TrimSpace(rest[1:]), true
+			}
+		}
+	}
+	return "", false
+}
+
+// extractRustSignatures extracts public functions, structs, enums, traits and
+// impl methods from Rust source code
+func extractRustSignatures(code string) ([]FunctionSignature, error) {
+	src := newRustSource(code)
+	items := src.items(0, len(code))
+	types := collectRustTypes(src, items)
+
+	var signatures []FunctionSignature
+	src.appendSignatures(&signatures, items, "", types)
+	return signatures, nil
+}
+
+// appendSignatures adds the public items, descending into public modules
+func (s *rustSource) appendSignatures(signatures *[]FunctionSignature, items []rustItem, prefix string, types map[string]rustTypeDecl) {
+	for _, item := range items {
+		switch item.Kind {
+		case "mod":
+			if item.Public && item.Body != [2]int{} {
+				s.appendSignatures(signatures, s.items(item.Body[0], item.Body[1]), prefix+item.Name+"::", types)
+			}
+		case "fn":
+			if item.Public {
+				*signatures = append(*signatures, s.fnSignature(item, prefix+item.Name, "function", types))
+			}
+		case "struct", "enum", "union":
+			if !item.Public {
+				continue
+			}
+			sig := s.itemSignature(item, prefix+item.Name, item.Kind)
+			if decl, ok := types[item.Name]; ok && item.Kind != "enum" && decl.Fields != nil {
+				schema := rustTypeSchema(item.Name, types, map[string]bool{})
+				sig.Parameters = map[string]interface{}{
+					"type":       "object",
+					"properties": schema["properties"],
+				}
+				sig.Required = goStringSlice(schema["required"])
+			}
+			*signatures = append(*signatures, sig)
+		case "trait":
+			if !item.Public {
+				continue
+			}
+			*signatures = append(*signatures, s.itemSignature(item, prefix+item.Name, "trait"))
+			if item.Body == [2]int{} {
+				continue
+			}
+			// Trait methods are as public as the trait itself
+			for _, member := range s.items(item.Body[0], item.Body[1]) {
+				if member.Kind == "fn" {
+					*signatures = append(*signatures, s.methodSignature(member, prefix+item.Name, types))
+				}
+			}
+		case "impl":
+			// Methods of trait implementations are documented on the trait
+			if item.Trait != "" || item.Body == [2]int{} {
+				continue
+			}
+			for _, member := range s.items(item.Body[0], item.Body[1]) {
+				if member.Kind == "fn" && member.Public {
+					*signatures = append(*signatures, s.methodSignature(member, prefix+item.Name, types))
+				}
+			}
+		}
+	}
+}
+
+// methodSignature builds the signature of an associated function of class, a
+// type or trait. Functions without a self receiver are static, and a static
+// new is the conventional constructor.
+func (s *rustSource) methodSignature(item rustItem, class string, types map[string]rustTypeDecl) FunctionSignature {
+	static := true
+	if open := braceScanTo(item.Header, "("); open >= 0 {
+		params := item.Header[open+1 : braceMatchingBracket(item.Header, open, len(item.Header))]
+		static = !rustReceiverRE.MatchString(strings.TrimSpace(params))
+	}
+
+	kind := "method"
+	if static && item.Name == "new" {
+		kind = "constructor"
+	}
+	sig := s.fnSignature(item, class+"::"+item.Name, kind, types)
+	sig.Class = class
+	sig.Static = static
+	return sig
+}
+
+// itemSignature fills the fields shared by every kind of item
+func (s *rustSource) itemSignature(item rustItem, name, kind string) FunctionSignature {
+	doc := parseRustDoc(item.Doc)
+	_, deprecated := rustAttr(item.Attrs, "deprecated")
+	return FunctionSignature{
+		Name:        name,
+		Type:        kind,
+		Signature:   item.Header,
+		Description: doc.String(),
+		Returns:     docReturns(doc),
+		Raises:      docRaises(doc),
+		Deprecated:  deprecated || doc.Deprecated,
+		Examples:    doc.Examples,
+		Line:        item.Line,
+	}
+}
+
+// fnSignature builds the signature of a function, mapping its parameters
+func (s *rustSource) fnSignature(item rustItem, name, kind string, types map[string]rustTypeDecl) FunctionSignature {
+	sig := s.itemSignature(item, name, kind)
+	doc := parseRustDoc(item.Doc)
+
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	header := item.Header
+	open := braceScanTo(header, "(")
+	if open >= 0 {
+		closing := braceMatchingBracket(header, open, len(header))
+		params := header[open+1 : closing]
+
+		// The return type follows "->" up to any where clause
+		if sig.Returns == "" {
+			if idx := strings.Index(header[closing:], "->"); idx >= 0 {
+				returns := strings.TrimSpace(header[closing+idx+2:])
+				if where := braceScanTo(returns, " where "); where >= 0 {
+					returns = returns[:where]
+				}
+				sig.Returns = strings.TrimSpace(returns)
+			}
+		}
+
+		for i, param := range splitBraceTopLevel(params, ',') {
+			param = strings.TrimSpace(param)
+			// Receivers are supplied by the caller
+			if param == "" || rustReceiverRE.MatchString(param) {
+				continue
+			}
+
+			colon := braceScanTo(param, ":")
+			if colon < 0 {
+				continue
+			}
+			paramName := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(param[:colon]), "mut "))
+			if !rustIdentRE.MatchString(paramName) || strings.ContainsAny(paramName, " (") {
+				paramName = fmt.Sprintf("arg%d", i)
+			}
+			paramName = strings.TrimPrefix(paramName, "r#")
+			if paramName == "_" {
+				paramName = fmt.Sprintf("arg%d", i)
+			}
+			typ := strings.TrimSpace(param[colon+1:])
+
+			schema := rustTypeSchema(typ, types, map[string]bool{})
+			description := fmt.Sprintf("Parameter %s", paramName)
+			if p, ok := doc.Param(paramName); ok && p.Description != "" {
+				description = p.Description
+			}
+			schema["description"] = description
+			properties[paramName] = schema
+
+			if !rustIsOption(typ) {
+				required = append(required, paramName)
+			}
+		}
+	}
+
+	sig.Parameters = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	sig.Required = required
+	return sig
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRustSource(t *testing.T) {
+	src := newRustSource(`let s = "a { b"; // } comment
+let r = r#"raw " }"#; /* outer /* nested } */ */ let c = '{'; fn f<'a>(x: &'a str) {}`)
+
+	assert.Len(t, src.masked, len(src.text))
+	assert.Equal(t, 1, strings.Count(src.masked, "{"))
+	assert.Equal(t, 1, strings.Count(src.masked, "}"))
+	assert.Contains(t, src.code, `"a { b"`)
+	assert.NotContains(t, src.code, "comment")
+	assert.Contains(t, src.masked, "&'a str")
+}
+
+func TestParseRustDoc(t *testing.T) {
+	doc := parseRustDoc("Adds numbers.\n\nWraps on overflow.\n\n# Arguments\n\n* `a` - the first\n* `b` - the second,\n  continued\n\n# Errors\n\nFails when empty.\n\n# Examples\n\n```\n# use calc::add;\nassert_eq!(add(1, 2), 3);\n```")
+
+	assert.Equal(t, "Adds numbers.", doc.Summary)
+	assert.Equal(t, "Wraps on overflow.", doc.Description)
+	assert.Equal(t, []docParam{
+		{Name: "a", Description: "the first"},
+		{Name: "b", Description: "the second, continued"},
+	}, doc.Params)
+	assert.Equal(t, []docParam{{Name: "Errors", Description: "Fails when empty."}}, doc.Raises)
+	assert.Equal(t, []string{"assert_eq!(add(1, 2), 3);"}, doc.Examples)
+}
+
+func TestExtractRustSignatures(t *testing.T) {
+	code := `use std::collections::HashMap;
+
+/// A stored user.
+#[derive(Serialize, Deserialize)]
+#[serde(rename_all = "camelCase")]
+pub struct User {
+    /// Display name.
+    pub display_name: String,
+    pub age: Option<u8>,
+    #[serde(rename = "labels")]
+    tags: Vec<String>,
+    #[serde(skip)]
+    cache: HashMap<String, f64>,
+}
+
+pub enum Role { Admin, Guest }
+
+/// Creates a user.
+///
+/// # Arguments
+///
+/// * ` + "`name`" + ` - the display name
+pub fn create(name: &str, role: Role, scores: HashMap<String, i32>, age: Option<u8>) -> User {
+    let brace = "}";
+    todo!()
+}
+
+fn private_helper() {}
+
+impl User {
+    /// Creates an anonymous user.
+    pub fn new(age: u8) -> Self { todo!() }
+    /// Renames the user.
+    pub fn rename(&mut self, name: String) {}
+    fn secret(&self) {}
+}
+
+pub trait Greeter {
+    /// Greets someone.
+    fn greet(&self, who: &str) -> String;
+}
+
+impl Greeter for User {
+    fn greet(&self, who: &str) -> String { String::new() }
+}
+
+#[deprecated(note = "use create")]
+pub fn make() {}
+
+pub mod admin {
+    pub fn ban(user_id: u64) {}
+}
+
+#[cfg(test)]
+mod tests {
+    #[test]
+    fn works() {}
+}
+`
+
+	signatures, err := extractRustSignatures(code)
+	require.NoError(t, err)
+
+	var names []string
+	for _, sig := range signatures {
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{"User", "Role", "create", "User::new", "User::rename", "Greeter", "Greeter::greet", "make", "admin::ban"}, names)
+
+	user := signatures[0]
+	assert.Equal(t, "struct", user.Type)
+	assert.Equal(t, "A stored user.", user.Description)
+	assert.Equal(t, 4, user.Line)
+	assert.Equal(t, []string{"displayName", "labels"}, user.Required)
+	userProps := user.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "Display name."}, userProps["displayName"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "nullable": true}, userProps["age"])
+	assert.NotContains(t, userProps, "cache")
+
+	assert.Equal(t, "enum", signatures[1].Type)
+
+	create := signatures[2]
+	assert.Equal(t, "function", create.Type)
+	assert.Equal(t, "pub fn create(name: &str, role: Role, scores: HashMap<String, i32>, age: Option<u8>) -> User", create.Signature)
+	assert.Equal(t, "Creates a user.", create.Description)
+	assert.Equal(t, "User", create.Returns)
+	assert.Equal(t, []string{"name", "role", "scores"}, create.Required)
+	props := create.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "the display name"}, props["name"])
+	assert.Equal(t, []interface{}{"Admin", "Guest"}, props["role"].(map[string]interface{})["enum"])
+	assert.Equal(t, map[string]interface{}{"type": "integer"}, props["scores"].(map[string]interface{})["additionalProperties"])
+
+	ctor := signatures[3]
+	assert.Equal(t, "constructor", ctor.Type)
+	assert.Equal(t, "User", ctor.Class)
+	assert.True(t, ctor.Static)
+	assert.Equal(t, []string{"age"}, ctor.Required)
+
+	rename := signatures[4]
+	assert.Equal(t, "method", rename.Type)
+	assert.Equal(t, "User", rename.Class)
+	assert.False(t, rename.Static)
+	assert.Equal(t, []string{"name"}, rename.Required)
+
+	assert.Equal(t, "trait", signatures[5].Type)
+	assert.Equal(t, "Greets someone.", signatures[6].Description)
+	assert.Equal(t, "Greeter", signatures[6].Class)
+	assert.True(t, signatures[7].Deprecated)
+}
+
+func TestExtractRustStructFieldVisibility(t *testing.T) {
+	code := `pub struct Config {
+    pub name: String,
+    pub(crate) cache_size: usize,
+    pub(super) parent: Option<String>,
+    secret: String,
+}
+
+#[derive(Debug, serde::Deserialize)]
+pub struct Request {
+    pub query: String,
+    pub(crate) limit: u32,
+    token: String,
+}
+`
+
+	signatures, err := extractRustSignatures(code)
+	require.NoError(t, err)
+	require.Len(t, signatures, 2)
+
+	// Callers can only set the pub fields of a plain struct
+	config := signatures[0]
+	assert.Equal(t, []string{"name"}, config.Required)
+	configProps := config.Parameters["properties"].(map[string]interface{})
+	assert.Len(t, configProps, 1)
+	assert.Contains(t, configProps, "name")
+
+	// Serde deserializes every field whatever its visibility
+	request := signatures[1]
+	assert.Equal(t, []string{"query", "limit", "token"}, request.Required)
+}
@@ -0,0 +1,532 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rustTypeDecl is a struct, enum or type alias declared in the source
+type rustTypeDecl struct {
+	Fields   []rustField   // struct fields; nil for tuple and unit structs
+	Tuple    []string      // tuple struct field types
+	Variants []rustVariant // enum variants
+	Alias    string        // aliased type
+}
+
+// rustField is a named struct field as serde sees it
+type rustField struct {
+	Name        string
+	Type        string
+	Description string
+	Optional    bool // Option<T> or #[serde(default)]
+}
+
+// rustVariant is an enum variant
+type rustVariant struct {
+	Name   string
+	Tuple  []string
+	Fields []rustField
+}
+
+var rustSerdeRenameRE = regexp.MustCompile(`\brename(?:_all)?\s*=\s*"([^"]*)"`)
+
+// collectRustTypes indexes the structs, enums and type aliases declared in
+// the source by name, including those nested in modules
+func collectRustTypes(src *rustSource, items []rustItem) map[string]rustTypeDecl {
+	types := make(map[string]rustTypeDecl)
+
+	var collect func(items []rustItem)
+	collect = func(items []rustItem) {
+		for _, item := range items {
+			switch item.Kind {
+			case "mod":
+				if item.Body != [2]int{} {
+					collect(src.items(item.Body[0], item.Body[1]))
+				}
+			case "struct", "union":
+				renameAll := rustSerdeOption(item.Attrs, "rename_all")
+				switch {
+				case item.Body != [2]int{}:
+					types[item.Name] = rustTypeDecl{Fields: src.fields(item.Body[0], item.Body[1], renameAll, !rustDerives(item.Attrs, "Deserialize"))}
+				default:
+					decl := rustTypeDecl{}
+					rest := item.Header[strings.Index(item.Header, item.Kind+" "+item.Name)+len(item.Kind)+1+len(item.Name):]
+					if open := braceScanTo(rest, "("); open >= 0 {
+						closing := braceMatchingBracket(rest, open, len(rest))
+						decl.Tuple = rustTupleTypes(rest[open+1 : closing])
+					}
+					types[item.Name] = decl
+				}
+			case "enum":
+				if item.Body != [2]int{} {
+					types[item.Name] = rustTypeDecl{Variants: src.variants(item.Body[0], item.Body[1], rustSerdeOption(item.Attrs, "rename_all"))}
+				}
+			case "type":
+				if idx := strings.Index(item.Header, "="); idx >= 0 {
+					types[item.Name] = rustTypeDecl{Alias: strings.TrimSpace(item.Header[idx+1:])}
+				}
+			}
+		}
+	}
+	collect(items)
+
+	return types
+}
+
+// segments splits the block between start and end on top-level commas,
+// returning the attributes, doc comment and remaining code of each entry.
+// Body holds the offsets of the entry after its attributes.
+func (s *rustSource) segments(start, end int) []rustItem {
+	var entries []rustItem
+	masked := s.masked[start:end]
+	offset := start
+	for _, part := range splitBraceTopLevel(masked, ',') {
+		partStart, partEnd := offset, offset+len(part)
+		offset = partEnd + 1
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		var entry rustItem
+		pos := partStart
+		for {
+			for pos < partEnd && strings.ContainsRune(" \t\r\n", rune(s.masked[pos])) {
+				pos++
+			}
+			if !strings.HasPrefix(s.masked[pos:partEnd], "#[") {
+				break
+			}
+			closing := braceMatchingBracket(s.masked, pos+1, partEnd)
+			entry.Attrs = append(entry.Attrs, strings.TrimSpace(s.code[pos+2:closing]))
+			pos = closing + 1
+		}
+		entry.Doc = braceDocComment(s.text[partStart:pos])
+		entry.Header = strings.Join(strings.Fields(s.code[pos:partEnd]), " ")
+		entry.Body = [2]int{pos, partEnd}
+		if entry.Header != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// fields parses the named fields of a struct body. Serde reads every field
+// whatever its visibility, so pubOnly is set for structs that do not derive
+// Deserialize, whose callers can only set the fields declared plain pub.
+func (s *rustSource) fields(start, end int, renameAll string, pubOnly bool) []rustField {
+	fields := []rustField{}
+	for _, entry := range s.segments(start, end) {
+		if pubOnly && !strings.HasPrefix(entry.Header, "pub ") {
+			continue
+		}
+		if _, skip := rustSerdeFlag(entry.Attrs, "skip"); skip {
+			continue
+		}
+		if _, skip := rustSerdeFlag(entry.Attrs, "skip_deserializing"); skip {
+			continue
+		}
+
+		decl := rustStripVisibility(entry.Header)
+		colon := braceScanTo(decl, ":")
+		if colon < 0 {
+			continue
+		}
+
+		field := rustField{
+			Name:        strings.TrimPrefix(strings.TrimSpace(decl[:colon]), "r#"),
+			Type:        strings.TrimSpace(decl[colon+1:]),
+			Description: parseRustDoc(entry.Doc).String(),
+		}
+		if rename := rustSerdeOption(entry.Attrs, "rename"); rename != "" {
+			field.Name = rename
+		} else if renameAll != "" {
+			field.Name = rustRenameCase(field.Name, renameAll)
+		}
+		_, hasDefault := rustSerdeFlag(entry.Attrs, "default")
+		field.Optional = hasDefault || rustIsOption(field.Type)
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// variants parses the variants of an enum body
+func (s *rustSource) variants(start, end int, renameAll string) []rustVariant {
+	var variants []rustVariant
+	for _, entry := range s.segments(start, end) {
+		if _, skip := rustSerdeFlag(entry.Attrs, "skip"); skip {
+			continue
+		}
+
+		header := entry.Header
+		if idx := braceScanTo(header, "="); idx >= 0 {
+			header = strings.TrimSpace(header[:idx])
+		}
+		ident := rustIdentRE.FindStringSubmatch(header)
+		if ident == nil {
+			continue
+		}
+
+		variant := rustVariant{Name: ident[1]}
+		if rename := rustSerdeOption(entry.Attrs, "rename"); rename != "" {
+			variant.Name = rename
+		} else if renameAll != "" {
+			variant.Name = rustRenameCase(variant.Name, renameAll)
+		}
+
+		rest := strings.TrimSpace(header[len(ident[0]):])
+		switch {
+		case strings.HasPrefix(rest, "("):
+			variant.Tuple = rustTupleTypes(rest[1:braceMatchingBracket(rest, 0, len(rest))])
+		case strings.HasPrefix(rest, "{"):
+			if open := strings.IndexByte(s.masked[entry.Body[0]:entry.Body[1]], '{'); open >= 0 {
+				open += entry.Body[0]
+				variant.Fields = s.fields(open+1, s.matchingBrace(open, entry.Body[1]), "", false)
+			}
+		}
+		variants = append(variants, variant)
+	}
+	return variants
+}
+
+// rustTupleTypes returns the field types of a tuple struct or variant
+func rustTupleTypes(text string) []string {
+	types := []string{}
+	for _, part := range splitBraceTopLevel(text, ',') {
+		if part = rustStripVisibility(part); part != "" {
+			types = append(types, part)
+		}
+	}
+	return types
+}
+
+// rustDerives reports whether a #[derive(...)] attribute derives the trait,
+// named plainly or by path as in serde::Deserialize
+func rustDerives(attrs []string, trait string) bool {
+	for _, attr := range attrs {
+		args, ok := rustAttr([]string{attr}, "derive")
+		if !ok {
+			continue
+		}
+		for _, arg := range splitBraceTopLevel(args, ',') {
+			arg = strings.TrimSpace(arg)
+			if arg == trait || strings.HasSuffix(arg, "::"+trait) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rustStripVisibility removes a leading pub, pub(crate) or pub(in path)
+func rustStripVisibility(text string) string {
+	text = strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(text, "pub(") || strings.HasPrefix(text, "pub ("):
+		open := strings.IndexByte(text, '(')
+		return strings.TrimSpace(text[braceMatchingBracket(text, open, len(text))+1:])
+	case strings.HasPrefix(text, "pub "):
+		return strings.TrimSpace(text[len("pub "):])
+	}
+	return text
+}
+
+// rustSerdeFlag returns the option of a #[serde(...)] attribute, e.g.
+// `rename = "id"` for "rename", and whether any serde attribute sets it
+func rustSerdeFlag(attrs []string, option string) (string, bool) {
+	for _, attr := range attrs {
+		args, ok := rustAttr([]string{attr}, "serde")
+		if !ok {
+			continue
+		}
+		for _, arg := range splitBraceTopLevel(args, ',') {
+			arg = strings.TrimSpace(arg)
+			if arg == option || strings.HasPrefix(arg, option+" ") || strings.HasPrefix(arg, option+"=") {
+				return arg, true
+			}
+		}
+	}
+	return "", false
+}
+
+// rustSerdeOption returns the string value of rename or rename_all in a #[serde(...)] attribute
+func rustSerdeOption(attrs []string, option string) string {
+	arg, ok := rustSerdeFlag(attrs, option)
+	if !ok {
+		return ""
+	}
+	if m := rustSerdeRenameRE.FindStringSubmatch(arg); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// rustRenameCase applies a serde rename_all rule to a snake_case field or
+// PascalCase variant name
+func rustRenameCase(name, rule string) string {
+	var words []string
+	if strings.Contains(name, "_") || strings.ToLower(name) == name {
+		words = strings.Split(strings.ToLower(name), "_")
+	} else {
+		start := 0
+		for i := 1; i < len(name); i++ {
+			if name[i] >= 'A' && name[i] <= 'Z' {
+				words = append(words, strings.ToLower(name[start:i]))
+				start = i
+			}
+		}
+		words = append(words, strings.ToLower(name[start:]))
+	}
+
+	title := func(w string) string {
+		if w == "" {
+			return w
+		}
+		return strings.ToUpper(w[:1]) + w[1:]
+	}
+
+	switch rule {
+	case "lowercase":
+		return strings.Join(words, "")
+	case "UPPERCASE":
+		return strings.ToUpper(strings.Join(words, ""))
+	case "PascalCase":
+		for i, w := range words {
+			words[i] = title(w)
+		}
+		return strings.Join(words, "")
+	case "camelCase":
+		for i, w := range words[1:] {
+			words[i+1] = title(w)
+		}
+		return strings.Join(words, "")
+	case "snake_case":
+		return strings.Join(words, "_")
+	case "SCREAMING_SNAKE_CASE":
+		return strings.ToUpper(strings.Join(words, "_"))
+	case "kebab-case":
+		return strings.Join(words, "-")
+	case "SCREAMING-KEBAB-CASE":
+		return strings.ToUpper(strings.Join(words, "-"))
+	}
+	return name
+}
+
+// rustIsOption reports whether a type is Option<T>
+func rustIsOption(typ string) bool {
+	return rustTypeName(typ) == "Option" && strings.Contains(typ, "<")
+}
+
+// rustGenericArgs splits "Vec<T>" into its name and type arguments
+func rustGenericArgs(typ string) (string, []string) {
+	open := strings.IndexByte(typ, '<')
+	if open < 0 || !strings.HasSuffix(typ, ">") {
+		return rustTypeName(typ), nil
+	}
+	var args []string
+	for _, arg := range splitBraceTopLevel(typ[open+1:len(typ)-1], ',') {
+		// Lifetimes carry no type information
+		if arg = strings.TrimSpace(arg); arg != "" && !strings.HasPrefix(arg, "'") {
+			args = append(args, arg)
+		}
+	}
+	return rustTypeName(typ[:open]), args
+}
+
+// rustTypeSchema maps a Rust type to a JSON Schema, following serde's default
+// representation. seen guards against infinite recursion through recursive types.
+func rustTypeSchema(typ string, types map[string]rustTypeDecl, seen map[string]bool) map[string]interface{} {
+	typ = strings.TrimSpace(typ)
+
+	// References, lifetimes, mutability and trait objects
+	for {
+		trimmed := strings.TrimSpace(strings.TrimLeft(typ, "&*"))
+		if strings.HasPrefix(trimmed, "'") {
+			if idx := strings.IndexByte(trimmed, ' '); idx >= 0 {
+				trimmed = trimmed[idx+1:]
+			}
+		}
+		for _, prefix := range []string{"mut ", "const ", "dyn ", "impl "} {
+			trimmed = strings.TrimPrefix(trimmed, prefix)
+		}
+		trimmed = strings.TrimSpace(trimmed)
+		if trimmed == typ {
+			break
+		}
+		typ = trimmed
+	}
+
+	// Slices, arrays and tuples
+	switch {
+	case strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]"):
+		elem := typ[1 : len(typ)-1]
+		if idx := braceScanTo(elem, ";"); idx >= 0 {
+			elem = elem[:idx]
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": rustTypeSchema(elem, types, seen),
+		}
+	case typ == "()":
+		return map[string]interface{}{"type": "null"}
+	case strings.HasPrefix(typ, "(") && strings.HasSuffix(typ, ")"):
+		elems := rustTupleTypes(typ[1 : len(typ)-1])
+		if len(elems) == 1 && !strings.HasSuffix(strings.TrimSpace(typ[1:len(typ)-1]), ",") {
+			return rustTypeSchema(elems[0], types, seen)
+		}
+		return rustTupleSchema(elems, types, seen)
+	}
+
+	name, args := rustGenericArgs(typ)
+	switch name {
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "i8", "i16", "i32", "i64", "i128", "isize",
+		"u8", "u16", "u32", "u64", "u128", "usize",
+		"NonZeroU8", "NonZeroU16", "NonZeroU32", "NonZeroU64", "NonZeroUsize":
+		return map[string]interface{}{"type": "integer"}
+	case "f32", "f64":
+		return map[string]interface{}{"type": "number"}
+	case "char", "str", "String", "OsStr", "OsString", "Path", "PathBuf", "Uuid":
+		return map[string]interface{}{"type": "string"}
+	case "DateTime", "NaiveDateTime", "SystemTime", "OffsetDateTime":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "NaiveDate", "Date":
+		return map[string]interface{}{"type": "string", "format": "date"}
+	case "Value":
+		return map[string]interface{}{}
+	case "Self":
+		return map[string]interface{}{"type": "object"}
+	case "Option":
+		if len(args) == 1 {
+			schema := rustTypeSchema(args[0], types, seen)
+			schema["nullable"] = true
+			return schema
+		}
+	case "Box", "Rc", "Arc", "Cow", "RefCell", "Cell", "Mutex", "RwLock", "Result",
+		"Into", "AsRef", "Borrow", "Pin":
+		// Result is only meaningful as a return type, where errors are not part of the schema
+		if len(args) > 0 {
+			return rustTypeSchema(args[0], types, seen)
+		}
+	case "Vec", "VecDeque", "LinkedList", "BinaryHeap", "IntoIterator", "Iterator":
+		schema := map[string]interface{}{"type": "array"}
+		if len(args) > 0 {
+			schema["items"] = rustTypeSchema(args[0], types, seen)
+		}
+		return schema
+	case "HashSet", "BTreeSet", "IndexSet":
+		schema := map[string]interface{}{"type": "array", "uniqueItems": true}
+		if len(args) > 0 {
+			schema["items"] = rustTypeSchema(args[0], types, seen)
+		}
+		return schema
+	case "HashMap", "BTreeMap", "IndexMap", "Map":
+		schema := map[string]interface{}{"type": "object"}
+		if len(args) == 2 {
+			schema["additionalProperties"] = rustTypeSchema(args[1], types, seen)
+		}
+		return schema
+	}
+
+	decl, ok := types[name]
+	if !ok || seen[name] {
+		// Generic parameters, foreign types and recursive references
+		if len(name) == 1 && name[0] >= 'A' && name[0] <= 'Z' {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"type": "object"}
+	}
+
+	nested := make(map[string]bool, len(seen)+1)
+	for k, v := range seen {
+		nested[k] = v
+	}
+	nested[name] = true
+
+	switch {
+	case decl.Alias != "":
+		return rustTypeSchema(decl.Alias, types, nested)
+	case decl.Fields != nil:
+		return rustFieldsSchema(decl.Fields, types, nested)
+	case decl.Variants != nil:
+		return rustEnumSchema(decl.Variants, types, nested)
+	case len(decl.Tuple) == 1:
+		// Newtypes serialize as their inner value
+		return rustTypeSchema(decl.Tuple[0], types, nested)
+	case len(decl.Tuple) > 1:
+		return rustTupleSchema(decl.Tuple, types, nested)
+	}
+	return map[string]interface{}{"type": "null"}
+}
+
+// rustFieldsSchema maps struct fields to an object schema
+func rustFieldsSchema(fields []rustField, types map[string]rustTypeDecl, seen map[string]bool) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	for _, field := range fields {
+		schema := rustTypeSchema(field.Type, types, seen)
+		if field.Description != "" {
+			schema["description"] = field.Description
+		}
+		properties[field.Name] = schema
+		if !field.Optional {
+			required = append(required, field.Name)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// rustTupleSchema maps tuple elements to a fixed-length array
+func rustTupleSchema(elems []string, types map[string]rustTypeDecl, seen map[string]bool) map[string]interface{} {
+	items := make([]interface{}, 0, len(elems))
+	for _, elem := range elems {
+		items = append(items, rustTypeSchema(elem, types, seen))
+	}
+	return map[string]interface{}{
+		"type":        "array",
+		"prefixItems": items,
+		"minItems":    len(elems),
+		"maxItems":    len(elems),
+	}
+}
+
+// rustEnumSchema maps an enum using serde's externally tagged representation:
+// unit variants are strings, other variants objects keyed by the variant name
+func rustEnumSchema(variants []rustVariant, types map[string]rustTypeDecl, seen map[string]bool) map[string]interface{} {
+	var units []interface{}
+	var tagged []interface{}
+	for _, v := range variants {
+		var content map[string]interface{}
+		switch {
+		case v.Fields != nil:
+			content = rustFieldsSchema(v.Fields, types, seen)
+		case len(v.Tuple) == 1:
+			content = rustTypeSchema(v.Tuple[0], types, seen)
+		case len(v.Tuple) > 1:
+			content = rustTupleSchema(v.Tuple, types, seen)
+		default:
+			units = append(units, v.Name)
+			continue
+		}
+		tagged = append(tagged, map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{v.Name: content},
+			"required":   []string{v.Name},
+		})
+	}
+
+	if len(tagged) == 0 {
+		return enumSchema(units)
+	}
+	if len(units) > 0 {
+		tagged = append([]interface{}{enumSchema(units)}, tagged...)
+	}
+	if len(tagged) == 1 {
+		return tagged[0].(map[string]interface{})
+	}
+	return map[string]interface{}{"anyOf": tagged}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRustTypeSchema(t *testing.T) {
+	types := map[string]rustTypeDecl{
+		"UserId": {Tuple: []string{"u64"}},
+		"Point": {Fields: []rustField{
+			{Name: "x", Type: "f64"},
+			{Name: "label", Type: "Option<String>", Optional: true},
+		}},
+		"Shape": {Variants: []rustVariant{
+			{Name: "Empty"},
+			{Name: "Circle", Tuple: []string{"f64"}},
+		}},
+		"Names": {Alias: "Vec<String>"},
+		"Node":  {Fields: []rustField{{Name: "next", Type: "Option<Box<Node>>", Optional: true}}},
+	}
+
+	tests := []struct {
+		name     string
+		typeText string
+		expected map[string]interface{}
+	}{
+		{"i32", "i32", map[string]interface{}{"type": "integer"}},
+		{"f64", "f64", map[string]interface{}{"type": "number"}},
+		{"bool", "bool", map[string]interface{}{"type": "boolean"}},
+		{"String", "String", map[string]interface{}{"type": "string"}},
+		{"str reference", "&'a str", map[string]interface{}{"type": "string"}},
+		{"vec", "Vec<i64>", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "integer"},
+		}},
+		{"slice", "&[String]", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}},
+		{"option", "Option<String>", map[string]interface{}{"type": "string", "nullable": true}},
+		{"hashmap", "std::collections::HashMap<String, Vec<u8>>", map[string]interface{}{
+			"type": "object",
+			"additionalProperties": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "integer"},
+			},
+		}},
+		{"hashset", "HashSet<u32>", map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "integer"},
+			"uniqueItems": true,
+		}},
+		{"tuple", "(i32, String)", map[string]interface{}{
+			"type": "array",
+			"prefixItems": []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "string"},
+			},
+			"minItems": 2,
+			"maxItems": 2,
+		}},
+		{"box", "Box<bool>", map[string]interface{}{"type": "boolean"}},
+		{"impl trait", "impl Into<String>", map[string]interface{}{"type": "string"}},
+		{"newtype", "UserId", map[string]interface{}{"type": "integer"}},
+		{"alias", "Names", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}},
+		{"struct", "Point", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"x":     map[string]interface{}{"type": "number"},
+				"label": map[string]interface{}{"type": "string", "nullable": true},
+			},
+			"required": []string{"x"},
+		}},
+		{"enum", "Shape", map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string", "enum": []interface{}{"Empty"}},
+				map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"Circle": map[string]interface{}{"type": "number"}},
+					"required":   []string{"Circle"},
+				},
+			},
+		}},
+		{"recursive", "Node", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"next": map[string]interface{}{"type": "object", "nullable": true},
+			},
+			"required": []string{},
+		}},
+		{"generic parameter", "T", map[string]interface{}{}},
+		{"foreign type", "reqwest::Client", map[string]interface{}{"type": "object"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, rustTypeSchema(tc.typeText, types, map[string]bool{}))
+		})
+	}
+}
+
+func TestRustRenameCase(t *testing.T) {
+	assert.Equal(t, "displayName", rustRenameCase("display_name", "camelCase"))
+	assert.Equal(t, "DisplayName", rustRenameCase("display_name", "PascalCase"))
+	assert.Equal(t, "display-name", rustRenameCase("display_name", "kebab-case"))
+	assert.Equal(t, "not_found", rustRenameCase("NotFound", "snake_case"))
+	assert.Equal(t, "NOT_FOUND", rustRenameCase("NotFound", "SCREAMING_SNAKE_CASE"))
+	assert.Equal(t, "notfound", rustRenameCase("NotFound", "lowercase"))
+}
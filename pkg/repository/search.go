@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultSearchMatches and maxSearchMatches bound how many matching lines
+	// search_code collects
+	defaultSearchMatches = 200
+	maxSearchMatches     = 2000
+
+	// maxContextLines is the most context_lines search_code accepts
+	maxContextLines = 10
+
+	// maxSearchFileSize is the largest file search_code reads
+	maxSearchFileSize = 4 << 20
+
+	// maxMatchLineLength is where lines returned by search_code are cut
+	maxMatchLineLength = 500
+
+	// searchBatchSize is how many files are searched concurrently before
+	// checking whether enough matches were found
+	searchBatchSize = 64
+)
+
+// SearchCode searches the content of the repository files
+func SearchCode(roots *Roots) server.ServerTool {
+	tool, handler := searchCodeImpl(roots)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: handler,
+	}
+}
+
+func searchCodeImpl(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return mcp.NewTool("search_code",
+			mcp.WithDescription("Search the content of the files of the current repo for a literal string or a regular expression and return the matching lines with their path, line and column (paginated). Skips the same hidden and ignored files as get_file_list, and binary files."),
+			withRoot(roots),
+			withRef(),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("Text to search for, or a regular expression in Go RE2 syntax when regex is true"),
+			),
+			mcp.WithBoolean("regex",
+				mcp.Description("Treat query as a regular expression instead of a literal string"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("case_sensitive",
+				mcp.Description("Match letter case exactly; searches ignore case by default"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithArray("include",
+				mcp.Description("Only search files matching one of these globs, e.g. 'src/**/*.py'. A glob without a slash matches a file or directory name at any depth"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithArray("exclude",
+				mcp.Description("Skip files matching any of these globs, e.g. 'tests' or '**/*_test.go'"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithNumber("context_lines",
+				mcp.Description(fmt.Sprintf("Lines of context to return before and after each match (max %d)", maxContextLines)),
+				mcp.DefaultNumber(0),
+			),
+			mcp.WithNumber("max_matches",
+				mcp.Description(fmt.Sprintf("Maximum number of matching lines to collect across all pages (max %d)", maxSearchMatches)),
+				mcp.DefaultNumber(defaultSearchMatches),
+			),
+			mcp.WithNumber("per_page",
+				mcp.Description("Matches per page (max 100)"),
+				mcp.DefaultNumber(50),
+			),
+			mcp.WithNumber("page",
+				mcp.Description("Page number"),
+				mcp.DefaultNumber(1),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handleSearchCode(ctx, request, roots)
+		}
+}
+
+// SearchCodeTool returns the tool and handler separately for direct MCP server registration
+func SearchCodeTool(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return searchCodeImpl(roots)
+}
+
+func handleSearchCode(ctx context.Context, req mcp.CallToolRequest, roots *Roots) (*mcp.CallToolResult, error) {
+	query, err := RequiredParam[string](req, "query")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	isRegex, err := OptionalParam[bool](req, "regex")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	caseSensitive, err := OptionalParam[bool](req, "case_sensitive")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	include, err := OptionalStringArrayParam(req, "include")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	exclude, err := OptionalStringArrayParam(req, "exclude")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	contextLines, err := OptionalParam[float64](req, "context_lines")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	contextLines = min(max(contextLines, 0), maxContextLines)
+
+	maxMatches, err := OptionalParam[float64](req, "max_matches")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if maxMatches <= 0 {
+		maxMatches = defaultSearchMatches
+	}
+	maxMatches = min(maxMatches, maxSearchMatches)
+
+	perPage, err := OptionalParam[float64](req, "per_page")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 50
+	}
+
+	page, err := OptionalParam[float64](req, "page")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	pattern, err := compileSearchPattern(query, isRegex, caseSensitive)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	repo, release, err := requestRepository(req, roots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	search, err := searchRepository(ctx, repo, pattern, include, exclude, int(contextLines), int(maxMatches))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to search repository: %v", err)), nil
+	}
+
+	result := SearchResults{
+		Matches:       []SearchMatch{},
+		Page:          int(page),
+		PerPage:       int(perPage),
+		TotalMatches:  len(search.Matches),
+		FilesSearched: search.FilesSearched,
+		Truncated:     search.Truncated,
+	}
+	start := min((int(page)-1)*int(perPage), len(search.Matches))
+	end := min(start+int(perPage), len(search.Matches))
+	if end < len(search.Matches) {
+		result.NextPage = int(page) + 1
+	}
+	result.Matches = append(result.Matches, search.Matches[start:end]...)
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal search results: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// compileSearchPattern turns a search_code query into a regular expression
+func compileSearchPattern(query string, isRegex, caseSensitive bool) (*regexp.Regexp, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query must not be empty")
+	}
+	expr := query
+	if !isRegex {
+		expr = regexp.QuoteMeta(query)
+	}
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return pattern, nil
+}
+
+// codeSearch is the outcome of a search over a repository
+type codeSearch struct {
+	Matches       []SearchMatch
+	FilesSearched int
+	Truncated     bool // the search stopped at maxMatches
+}
+
+// searchRepository searches the files of a repository matching the include
+// and exclude globs, in path order, for up to maxMatches matching lines.
+// Files are searched concurrently, a batch at a time, and their matches
+// kept in path order, so the results do not depend on scheduling.
+func searchRepository(ctx context.Context, repo *RepoFS, pattern *regexp.Regexp, include, exclude []string, contextLines, maxMatches int) (*codeSearch, error) {
+	var files []string
+	err := walkRepository(repo, func(relPath string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if matchFilters(relPath, include, exclude) {
+			files = append(files, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &codeSearch{Matches: []SearchMatch{}}
+	workers := runtime.GOMAXPROCS(0)
+	for start := 0; start < len(files); start += searchBatchSize {
+		batch := files[start:min(start+searchBatchSize, len(files))]
+		matches := make([][]SearchMatch, len(batch))
+
+		var wg sync.WaitGroup
+		next := make(chan int)
+		for w := 0; w < min(workers, len(batch)); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					// One match past the limit tells whether the results are truncated
+					matches[i] = searchFile(repo, batch[i], pattern, contextLines, maxMatches+1)
+				}
+			}()
+		}
+		for i := range batch {
+			if ctx.Err() != nil {
+				break
+			}
+			next <- i
+		}
+		close(next)
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, fileMatches := range matches {
+			result.FilesSearched++
+			room := maxMatches - len(result.Matches)
+			if len(fileMatches) > room {
+				result.Matches = append(result.Matches, fileMatches[:room]...)
+				result.Truncated = true
+				return result, nil
+			}
+			result.Matches = append(result.Matches, fileMatches...)
+		}
+	}
+	return result, nil
+}
+
+// searchFile returns up to limit lines of a file matching the pattern.
+// Binary files, files larger than maxSearchFileSize and files that cannot
+// be read have no matches.
+func searchFile(repo *RepoFS, name string, pattern *regexp.Regexp, contextLines, limit int) []SearchMatch {
+	info, err := repo.Stat(name)
+	if err != nil || info.Size() > maxSearchFileSize {
+		return nil
+	}
+	content, err := repo.ReadFile(name)
+	if err != nil || looksBinary(content[:min(len(content), sniffSize)]) {
+		return nil
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSearchFileSize+1)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	var matches []SearchMatch
+	for i, line := range lines {
+		loc := pattern.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		match := SearchMatch{
+			Path:   name,
+			Line:   i + 1,
+			Column: utf8.RuneCountInString(line[:loc[0]]) + 1,
+			Text:   cutLine(line),
+		}
+		for j := max(i-contextLines, 0); j < i; j++ {
+			match.Before = append(match.Before, cutLine(lines[j]))
+		}
+		for j := i + 1; j <= min(i+contextLines, len(lines)-1); j++ {
+			match.After = append(match.After, cutLine(lines[j]))
+		}
+		matches = append(matches, match)
+		if len(matches) >= limit {
+			break
+		}
+	}
+	return matches
+}
+
+// cutLine shortens a line longer than maxMatchLineLength, without cutting a
+// rune, and drops the carriage return of a CRLF line ending
+func cutLine(line string) string {
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	if len(line) <= maxMatchLineLength {
+		return line
+	}
+	cut := maxMatchLineLength
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "…"
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSearchCode(t *testing.T) {
+	var many strings.Builder
+	for i := 1; i <= 150; i++ {
+		fmt.Fprintf(&many, "value_%d = %d\n", i, i)
+	}
+	writeTree(t, map[string]string{
+		".gitignore":        "generated/\n",
+		"app/main.py":       "import os\n\ndef Connect(url):\n    return open_connection(url)\n\n\ndef close():\n    pass\n",
+		"app/db.go":         "package app\n\n// connect opens the database\nfunc connect() {}\n",
+		"app/main_test.py":  "def test_connect():\n    connect()\n",
+		"generated/api.py":  "def connect(): pass\n",
+		"docs/guide.md":     "Call `connect()` to start, price is $5.\r\n",
+		"assets/logo.bin":   "connect\x00\x01",
+		"data/values.py":    many.String(),
+		"docs/unicode.txt":  "héllo wörld connect\n",
+		"node_modules/x.js": "connect()\n",
+	})
+
+	search := func(args map[string]interface{}) SearchResults {
+		t.Helper()
+		result, err := handleSearchCode(context.Background(), createMCPRequest(args), nil)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+		var results SearchResults
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &results))
+		return results
+	}
+	locations := func(results SearchResults) []string {
+		var locs []string
+		for _, m := range results.Matches {
+			locs = append(locs, fmt.Sprintf("%s:%d:%d", m.Path, m.Line, m.Column))
+		}
+		return locs
+	}
+
+	t.Run("literal search ignores case and skips ignored and binary files", func(t *testing.T) {
+		results := search(map[string]interface{}{"query": "connect("})
+		assert.Equal(t, []string{
+			"app/db.go:4:6",
+			"app/main.py:3:5",
+			"app/main_test.py:1:10",
+			"app/main_test.py:2:5",
+			"docs/guide.md:1:7",
+		}, locations(results))
+		assert.Equal(t, 7, results.FilesSearched)
+		assert.Equal(t, "Call `connect()` to start, price is $5.", results.Matches[4].Text)
+		assert.False(t, results.Truncated)
+	})
+
+	t.Run("case sensitivity and globs", func(t *testing.T) {
+		results := search(map[string]interface{}{"query": "Connect", "case_sensitive": true})
+		assert.Equal(t, []string{"app/main.py:3:5"}, locations(results))
+
+		results = search(map[string]interface{}{"query": "connect", "include": []interface{}{"app/**"}, "exclude": []interface{}{"*_test.py"}})
+		assert.Equal(t, []string{"app/db.go:3:4", "app/db.go:4:6", "app/main.py:3:5", "app/main.py:4:17"}, locations(results))
+
+		results = search(map[string]interface{}{"query": "connect", "include": []interface{}{"*.txt"}})
+		assert.Equal(t, []string{"docs/unicode.txt:1:13"}, locations(results))
+	})
+
+	t.Run("regular expressions", func(t *testing.T) {
+		results := search(map[string]interface{}{"query": `^def \w+\(\):`, "regex": true})
+		assert.Equal(t, []string{"app/main.py:7:1", "app/main_test.py:1:1"}, locations(results))
+
+		results = search(map[string]interface{}{"query": "$5"})
+		assert.Equal(t, []string{"docs/guide.md:1:37"}, locations(results))
+
+		result, err := handleSearchCode(context.Background(), createMCPRequest(map[string]interface{}{"query": "(", "regex": true}), nil)
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		assert.Contains(t, getTextResult(t, result).Text, "invalid regular expression")
+	})
+
+	t.Run("context lines", func(t *testing.T) {
+		results := search(map[string]interface{}{"query": "open_connection", "context_lines": float64(2)})
+		require.Len(t, results.Matches, 1)
+		assert.Equal(t, SearchMatch{
+			Path:   "app/main.py",
+			Line:   4,
+			Column: 12,
+			Text:   "    return open_connection(url)",
+			Before: []string{"", "def Connect(url):"},
+			After:  []string{"", ""},
+		}, results.Matches[0])
+	})
+
+	t.Run("pagination and caps", func(t *testing.T) {
+		results := search(map[string]interface{}{"query": "value_", "per_page": float64(40), "page": float64(2)})
+		assert.Len(t, results.Matches, 40)
+		assert.Equal(t, 41, results.Matches[0].Line)
+		assert.Equal(t, 3, results.NextPage)
+		assert.Equal(t, 150, results.TotalMatches)
+		assert.False(t, results.Truncated)
+
+		results = search(map[string]interface{}{"query": "value_", "max_matches": float64(100), "per_page": float64(100)})
+		assert.Len(t, results.Matches, 100)
+		assert.Equal(t, 100, results.TotalMatches)
+		assert.Zero(t, results.NextPage)
+		assert.True(t, results.Truncated)
+
+		// Exactly max_matches matches, with no further match in later files
+		results = search(map[string]interface{}{"query": "value_", "max_matches": float64(150), "per_page": float64(100)})
+		assert.Equal(t, 150, results.TotalMatches)
+		assert.Equal(t, 2, results.NextPage)
+		assert.False(t, results.Truncated)
+	})
+}
+
+func TestSearchFileLimit(t *testing.T) {
+	root := writeTree(t, map[string]string{"values.txt": "value 1\nvalue 2\nvalue 3\nvalue 4\n"})
+	repo := openRepo(t, root)
+
+	matches := searchFile(repo, "values.txt", regexp.MustCompile("value"), 0, 3)
+	require.Len(t, matches, 3, "the scan stops at the limit")
+	assert.Equal(t, 3, matches[2].Line)
+}
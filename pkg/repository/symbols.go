@@ -0,0 +1,696 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// maxIndexFiles bounds how many source files a symbol index holds
+	maxIndexFiles = 20000
+
+	// defaultDefinitionLines and maxDefinitionLines bound how much code
+	// get_definition returns for each definition
+	defaultDefinitionLines = 60
+	maxDefinitionLines     = 500
+
+	// maxDefinitions is the most definitions get_definition returns
+	maxDefinitions = 20
+)
+
+// SymbolIndex is an in-memory index of the public functions, classes,
+// methods and other symbols of a repository, as found by the extractors. It
+// is refreshed file by file, so only changed files are extracted again, and
+// is safe for concurrent use.
+type SymbolIndex struct {
+	refresh sync.Mutex // serializes refreshes
+
+	mu        sync.RWMutex
+	files     map[string]*indexedFile
+	truncated bool
+}
+
+// indexedFile holds the symbols of a source file and what they were
+// extracted from
+type indexedFile struct {
+	language string
+	size     int64
+	modTime  time.Time
+	symbols  []Symbol
+}
+
+// NewSymbolIndex returns an empty index
+func NewSymbolIndex() *SymbolIndex {
+	return &SymbolIndex{files: make(map[string]*indexedFile)}
+}
+
+// Refresh brings the index up to date with a repository: files that are new
+// or whose size or modification time changed are extracted again, and files
+// that are gone are dropped
+func (ix *SymbolIndex) Refresh(ctx context.Context, repo *RepoFS) error {
+	ix.refresh.Lock()
+	defer ix.refresh.Unlock()
+
+	sources, truncated, err := collectSourceFiles(ctx, repo, nil, nil, maxIndexFiles)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		seen[source.Path] = true
+		ix.refreshFile(repo, source.Path, source.Language, false)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for name := range ix.files {
+		if !seen[name] {
+			delete(ix.files, name)
+		}
+	}
+	ix.truncated = truncated
+	return nil
+}
+
+// refreshFile extracts the symbols of a source file again if it is new, its
+// size or modification time changed, or changed is set
+func (ix *SymbolIndex) refreshFile(repo *RepoFS, name, language string, changed bool) {
+	info, err := repo.Stat(name)
+	if err != nil {
+		ix.remove(name)
+		return
+	}
+
+	ix.mu.RLock()
+	current := ix.files[name]
+	full := current == nil && len(ix.files) >= maxIndexFiles
+	ix.mu.RUnlock()
+	if !changed && current != nil && current.language == language && current.size == info.Size() && current.modTime.Equal(info.ModTime()) {
+		return
+	}
+	if full {
+		ix.mu.Lock()
+		ix.truncated = true
+		ix.mu.Unlock()
+		return
+	}
+	ix.store(name, indexFile(repo, name, language, info.Size(), info.ModTime()))
+}
+
+// update brings the symbols of a changed file up to date: the file is
+// indexed again if it is a source file, even if a write left its size and
+// modification time alone, and dropped otherwise
+func (ix *SymbolIndex) update(repo *RepoFS, name string) {
+	ix.refresh.Lock()
+	defer ix.refresh.Unlock()
+	if language, ok := detectFileLanguage(repo, name); ok {
+		ix.refreshFile(repo, name, language, true)
+	} else {
+		ix.remove(name)
+	}
+}
+
+// remove drops the symbols of a file that is gone
+func (ix *SymbolIndex) remove(name string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	delete(ix.files, name)
+}
+
+// indexFile extracts the symbols of a source file. Files that cannot be
+// read or parsed are indexed without symbols, so they are not retried until
+// they change.
+func indexFile(repo *RepoFS, name, language string, size int64, modTime time.Time) *indexedFile {
+	file := &indexedFile{language: language, size: size, modTime: modTime}
+	signatures, err := extractFileSignatures(repo, name, language)
+	if err != nil {
+		return file
+	}
+	for _, sig := range signatures {
+		file.symbols = append(file.symbols, Symbol{
+			Name:      memberName(sig),
+			Kind:      sig.Type,
+			Class:     sig.Class,
+			Path:      name,
+			Line:      sig.Line,
+			Language:  language,
+			Signature: sig.Signature,
+		})
+	}
+	return file
+}
+
+// memberName returns the name of a symbol without the class the extractors
+// qualify members with, such as Cart.add, Canvas::draw or Service#load
+func memberName(sig FunctionSignature) string {
+	for _, sep := range []string{".", "::", "#"} {
+		if sig.Class != "" && strings.HasPrefix(sig.Name, sig.Class+sep) {
+			return sig.Name[len(sig.Class)+len(sep):]
+		}
+	}
+	return sig.Name
+}
+
+// normalizeSymbolName writes the separators of a qualified name as dots
+func normalizeSymbolName(name string) string {
+	return strings.NewReplacer("::", ".", "#", ".").Replace(name)
+}
+
+func (ix *SymbolIndex) store(name string, file *indexedFile) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.files[name] = file
+}
+
+// QualifiedName returns the name of a symbol prefixed with its class, such
+// as Cart.add, or its plain name when it has no class
+func (s Symbol) QualifiedName() string {
+	if s.Class == "" {
+		return s.Name
+	}
+	return s.Class + "." + s.Name
+}
+
+// Truncated reports whether the repository holds more source files than
+// the index does
+func (ix *SymbolIndex) Truncated() bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.truncated
+}
+
+// sources returns the indexed source files in walk order
+func (ix *SymbolIndex) sources() []sourceFile {
+	ix.mu.RLock()
+	sources := make([]sourceFile, 0, len(ix.files))
+	for name, file := range ix.files {
+		sources = append(sources, sourceFile{Path: name, Language: file.language})
+	}
+	ix.mu.RUnlock()
+	sort.Slice(sources, func(i, j int) bool { return walkOrderLess(sources[i].Path, sources[j].Path) })
+	return sources
+}
+
+// Symbols returns the indexed symbols accepted by keep, ordered by path and line
+func (ix *SymbolIndex) Symbols(keep func(Symbol) bool) []Symbol {
+	ix.mu.RLock()
+	var symbols []Symbol
+	for _, file := range ix.files {
+		for _, symbol := range file.symbols {
+			if keep == nil || keep(symbol) {
+				symbols = append(symbols, symbol)
+			}
+		}
+	}
+	ix.mu.RUnlock()
+
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Path != symbols[j].Path {
+			return symbols[i].Path < symbols[j].Path
+		}
+		return symbols[i].Line < symbols[j].Line
+	})
+	return symbols
+}
+
+// Definitions returns the symbols declared with a name, either a plain name
+// or one qualified by its class, such as Cart.add or Canvas::draw
+func (ix *SymbolIndex) Definitions(name string) []Symbol {
+	name = normalizeSymbolName(name)
+	return ix.Symbols(func(symbol Symbol) bool {
+		return symbol.Name == name || normalizeSymbolName(symbol.QualifiedName()) == name
+	})
+}
+
+// Symbol matching modes of find_symbol
+const (
+	matchExact    = "exact"
+	matchPrefix   = "prefix"
+	matchContains = "contains"
+)
+
+// matchSymbolName reports whether a symbol name, or its qualified name,
+// matches a query
+func matchSymbolName(symbol Symbol, query, mode string, caseSensitive bool) bool {
+	for _, name := range []string{symbol.Name, normalizeSymbolName(symbol.QualifiedName())} {
+		if !caseSensitive {
+			name = strings.ToLower(name)
+		}
+		var ok bool
+		switch mode {
+		case matchExact:
+			ok = name == query
+		case matchPrefix:
+			ok = strings.HasPrefix(name, query)
+		default:
+			ok = strings.Contains(name, query)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// symbolIndex returns the symbol index of a repository, created on first use
+func (r *RepoFS) symbolIndex() *SymbolIndex {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.symbols == nil {
+		r.symbols = NewSymbolIndex()
+	}
+	return r.symbols
+}
+
+// refreshedIndex returns the symbol index of a repository, up to date. The
+// index of a watched repository is kept up to date as files change, so it
+// is only refreshed for other repositories.
+func refreshedIndex(ctx context.Context, repo *RepoFS) (*SymbolIndex, error) {
+	index := repo.symbolIndex()
+	if repo.watched() {
+		return index, nil
+	}
+	if err := index.Refresh(ctx, repo); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// symbolKinds are the kinds of symbols the extractors report
+var symbolKinds = []string{"function", "class", "method", "constructor", "struct", "interface", "enum", "trait"}
+
+// FindSymbol finds the symbols of the repository by name
+func FindSymbol(roots *Roots) server.ServerTool {
+	tool, handler := findSymbolImpl(roots)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: handler,
+	}
+}
+
+func findSymbolImpl(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return mcp.NewTool("find_symbol",
+			mcp.WithDescription("Find the public functions, classes, methods and other symbols of the current repo by name and return their kind, signature, file and line (paginated). Names can be qualified by their class, e.g. 'Cart.add'. Symbols come from an in-memory index that only re-reads changed files."),
+			withRoot(roots),
+			withRef(),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("Name, or part of a name, of the symbols to find"),
+			),
+			mcp.WithString("match",
+				mcp.Description("How query must match a symbol name"),
+				mcp.Enum(matchExact, matchPrefix, matchContains),
+				mcp.DefaultString(matchContains),
+			),
+			mcp.WithBoolean("case_sensitive",
+				mcp.Description("Match letter case exactly; names are matched ignoring case by default"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithString("kind",
+				mcp.Description("Only return symbols of this kind"),
+				mcp.Enum(symbolKinds...),
+			),
+			mcp.WithString("language",
+				mcp.Description("Only return symbols written in this language"),
+				mcp.Enum(Languages()...),
+			),
+			mcp.WithNumber("per_page",
+				mcp.Description("Symbols per page (max 100)"),
+				mcp.DefaultNumber(50),
+			),
+			mcp.WithNumber("page",
+				mcp.Description("Page number"),
+				mcp.DefaultNumber(1),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handleFindSymbol(ctx, request, roots)
+		}
+}
+
+// FindSymbolTool returns the tool and handler separately for direct MCP server registration
+func FindSymbolTool(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return findSymbolImpl(roots)
+}
+
+func handleFindSymbol(ctx context.Context, req mcp.CallToolRequest, roots *Roots) (*mcp.CallToolResult, error) {
+	query, err := RequiredParam[string](req, "query")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	mode, err := OptionalParam[string](req, "match")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	switch mode {
+	case "":
+		mode = matchContains
+	case matchExact, matchPrefix, matchContains:
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("unknown match mode: %s", mode)), nil
+	}
+
+	caseSensitive, err := OptionalParam[bool](req, "case_sensitive")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	query = normalizeSymbolName(query)
+	if !caseSensitive {
+		query = strings.ToLower(query)
+	}
+
+	kind, err := OptionalParam[string](req, "kind")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	language, err := OptionalParam[string](req, "language")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	perPage, err := OptionalParam[float64](req, "per_page")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 50
+	}
+
+	page, err := OptionalParam[float64](req, "page")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	repo, release, err := requestRepository(req, roots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	index, err := refreshedIndex(ctx, repo)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to index repository: %v", err)), nil
+	}
+
+	symbols := index.Symbols(func(symbol Symbol) bool {
+		return (kind == "" || symbol.Kind == kind) &&
+			(language == "" || symbol.Language == language) &&
+			matchSymbolName(symbol, query, mode, caseSensitive)
+	})
+
+	result := SymbolResults{
+		Symbols:      []Symbol{},
+		Page:         int(page),
+		PerPage:      int(perPage),
+		TotalSymbols: len(symbols),
+		Truncated:    index.Truncated(),
+	}
+	start := min((int(page)-1)*int(perPage), len(symbols))
+	end := min(start+int(perPage), len(symbols))
+	if end < len(symbols) {
+		result.NextPage = int(page) + 1
+	}
+	result.Symbols = append(result.Symbols, symbols[start:end]...)
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal symbols: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// GetDefinition returns the code declaring a symbol of the repository
+func GetDefinition(roots *Roots) server.ServerTool {
+	tool, handler := getDefinitionImpl(roots)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: handler,
+	}
+}
+
+func getDefinitionImpl(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return mcp.NewTool("get_definition",
+			mcp.WithDescription("Go to the definition of a function, class, method or other symbol of the current repo by name: return where it is declared and the code of the declaration, with the comments, attributes and decorators right above it."),
+			withRoot(roots),
+			withRef(),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("Exact name of the symbol, optionally qualified by its class, e.g. 'Cart.add'"),
+			),
+			mcp.WithString("path",
+				mcp.Description("Only return definitions in this file, relative to the repository root"),
+			),
+			mcp.WithNumber("max_lines",
+				mcp.Description(fmt.Sprintf("Maximum number of lines of code to return for each definition (max %d)", maxDefinitionLines)),
+				mcp.DefaultNumber(defaultDefinitionLines),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handleGetDefinition(ctx, request, roots)
+		}
+}
+
+// GetDefinitionTool returns the tool and handler separately for direct MCP server registration
+func GetDefinitionTool(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return getDefinitionImpl(roots)
+}
+
+func handleGetDefinition(ctx context.Context, req mcp.CallToolRequest, roots *Roots) (*mcp.CallToolResult, error) {
+	name, err := RequiredParam[string](req, "name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	filePath, err := OptionalParam[string](req, "path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if filePath != "" {
+		if filePath, err = cleanRepoPath(filePath); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	maxLines, err := OptionalParam[float64](req, "max_lines")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if maxLines <= 0 {
+		maxLines = defaultDefinitionLines
+	}
+	maxLines = min(maxLines, maxDefinitionLines)
+
+	repo, release, err := requestRepository(req, roots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	index, err := refreshedIndex(ctx, repo)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to index repository: %v", err)), nil
+	}
+
+	var symbols []Symbol
+	for _, symbol := range index.Definitions(name) {
+		if filePath == "" || symbol.Path == filePath {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("no definition of %s found", name)), nil
+	}
+
+	definitions := make([]Definition, 0, min(len(symbols), maxDefinitions))
+	texts := make(map[string]string)
+	for _, symbol := range symbols[:min(len(symbols), maxDefinitions)] {
+		text, ok := texts[symbol.Path]
+		if !ok {
+			content, err := repo.ReadFile(symbol.Path)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to read file: %v", err)), nil
+			}
+			text = string(content)
+			texts[symbol.Path] = text
+		}
+		definitions = append(definitions, symbolDefinition(text, symbol, int(maxLines)))
+	}
+
+	out, err := json.MarshalIndent(definitions, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal definitions: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// symbolDefinition cuts the declaration of a symbol out of its file, up to
+// maxLines lines
+func symbolDefinition(text string, symbol Symbol, maxLines int) Definition {
+	lines := strings.Split(text, "\n")
+	start, end := definitionLines(text, lines, symbol)
+	def := Definition{Symbol: symbol, StartLine: start, EndLine: end}
+	if end-start+1 > maxLines {
+		def.EndLine = start + maxLines - 1
+		def.Truncated = true
+	}
+	def.Code = strings.Join(lines[def.StartLine-1:def.EndLine], "\n")
+	return def
+}
+
+// definitionLines returns the first and last lines of the declaration of a
+// symbol, with the comments, attributes and decorators right above it. The
+// extent is found lexically: by indentation for Python and by matching
+// brackets for the other languages.
+func definitionLines(text string, lines []string, symbol Symbol) (int, int) {
+	line := min(max(symbol.Line, 1), len(lines))
+	masked := strings.Split(maskSource(symbol.Language, text), "\n")
+
+	var end int
+	if symbol.Language == "python" {
+		end = pythonDefinitionEnd(lines, masked, line)
+	} else {
+		end = braceDefinitionEnd(masked, line, symbol.Language)
+	}
+
+	start := line
+	for start > 1 && isLeadingComment(strings.TrimSpace(lines[start-2]), symbol.Language) {
+		start--
+	}
+	return start, max(end, line)
+}
+
+// isLeadingComment reports whether a line above a declaration belongs to it
+func isLeadingComment(line, language string) bool {
+	if language == "python" {
+		return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "@")
+	}
+	for _, prefix := range []string{"//", "/*", "*", "#[", "@"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// pythonDefinitionEnd returns the last line of the def or class statement
+// at line, or under the decorators starting at line: the last line of its
+// header, or of its indented body
+func pythonDefinitionEnd(lines, masked []string, line int) int {
+	header := line - 1
+	for header < len(masked) && strings.HasPrefix(strings.TrimSpace(masked[header]), "@") {
+		header++
+	}
+	if header == len(masked) {
+		return line
+	}
+	indent := pythonIndent(masked[header])
+
+	// The header ends at the colon outside brackets
+	depth, end := 0, header
+headerLoop:
+	for ; end < len(masked); end++ {
+		for _, c := range masked[end] {
+			switch c {
+			case '(', '[', '{':
+				depth++
+			case ')', ']', '}':
+				depth--
+			case ':':
+				if depth == 0 {
+					break headerLoop
+				}
+			}
+		}
+	}
+	if end == len(masked) {
+		return line
+	}
+
+	for i := end + 1; i < len(masked); i++ {
+		original := strings.TrimSpace(lines[i])
+		switch {
+		case original == "" || strings.HasPrefix(original, "#"):
+			continue
+		case strings.TrimSpace(masked[i]) == "":
+			// Inside a multi-line string, such as a docstring
+			end = i
+		case pythonIndent(masked[i]) <= indent:
+			return end + 1
+		default:
+			end = i
+		}
+	}
+	return end + 1
+}
+
+// pythonIndent returns the indentation width of a line
+func pythonIndent(line string) int {
+	col := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			col++
+		case '\t':
+			col = (col/8 + 1) * 8
+		default:
+			return col
+		}
+	}
+	return col
+}
+
+// braceDefinitionEnd returns the last line of the declaration starting at
+// line: the line closing its block, or the line ending it with a semicolon.
+// In Go, Kotlin, JavaScript and TypeScript a line break outside brackets
+// also ends a declaration without a block, unless the line is an annotation
+// or ends with an operator.
+func braceDefinitionEnd(masked []string, line int, language string) int {
+	lineBreaks := language == "go" || language == "kotlin" || language == "javascript" || language == "typescript"
+	depth, blocks := 0, 0
+	for i := line - 1; i < len(masked); i++ {
+		for _, c := range masked[i] {
+			switch c {
+			case '(', '[':
+				depth++
+			case ')', ']':
+				depth--
+			case '{':
+				blocks++
+			case '}':
+				blocks--
+				if blocks == 0 && depth <= 0 {
+					return i + 1
+				}
+			case ';':
+				if blocks == 0 && depth <= 0 {
+					return i + 1
+				}
+			}
+		}
+		trimmed := strings.TrimSpace(masked[i])
+		if lineBreaks && blocks == 0 && depth <= 0 && trimmed != "" &&
+			!strings.HasPrefix(trimmed, "@") && !strings.ContainsAny(trimmed[len(trimmed)-1:], "=,:.>|&+-") {
+			return i + 1
+		}
+	}
+	return len(masked)
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// symbolTree is a small repository with symbols in several languages
+var symbolTree = map[string]string{
+	"shop/cart.py": `"""Shopping carts."""
+
+
+class Cart:
+    """A shopping cart."""
+
+    def add(self, item: str, count: int = 1) -> None:
+        """Add an item.
+
+Text at column zero stays in the docstring.
+"""
+        self.items.append((item, count))
+
+    # Totals
+
+    @property
+    def total(self) -> float:
+        return sum(self.prices)
+
+
+def checkout(cart: Cart) -> bool:
+    return cart.total > 0
+`,
+	"shop/cart.go": `package shop
+
+// Cart holds items
+type Cart struct {
+	Items []string
+}
+
+// Add appends an item
+// to the cart
+func (c *Cart) Add(item string) {
+	if item == "}" {
+		return
+	}
+	c.Items = append(c.Items, item)
+}
+
+type Total int
+`,
+	"web/cart.ts": `/** Adds to a cart */
+export function addToCart(id: string,
+    count: number): void {
+  console.log("{", id);
+}
+
+export const removeFromCart = (id: string) => id
+`,
+	"Main.java": `public class Main {
+    @Deprecated
+    public static void main(String[] args) {
+        System.out.println("}");
+    }
+}
+`,
+}
+
+func TestSymbolIndex(t *testing.T) {
+	root := writeTree(t, symbolTree)
+	repo := openRepo(t, root)
+	index := NewSymbolIndex()
+	require.NoError(t, index.Refresh(context.Background(), repo))
+
+	var names []string
+	for _, symbol := range index.Symbols(nil) {
+		names = append(names, fmt.Sprintf("%s:%d %s %s", symbol.Path, symbol.Line, symbol.Kind, symbol.QualifiedName()))
+	}
+	assert.Equal(t, []string{
+		"Main.java:1 class Main",
+		"Main.java:2 method Main.main",
+		"shop/cart.go:4 struct Cart",
+		"shop/cart.go:10 method Cart.Add",
+		"shop/cart.py:4 class Cart",
+		"shop/cart.py:7 method Cart.add",
+		"shop/cart.py:16 method Cart.total",
+		"shop/cart.py:21 function checkout",
+		"web/cart.ts:2 function addToCart",
+		"web/cart.ts:7 function removeFromCart",
+	}, names)
+
+	defs := index.Definitions("Cart.add")
+	require.Len(t, defs, 1)
+	assert.Equal(t, Symbol{
+		Name:      "add",
+		Kind:      "method",
+		Class:     "Cart",
+		Path:      "shop/cart.py",
+		Line:      7,
+		Language:  "python",
+		Signature: "def add(self, item: str, count: int = 1) -> None:",
+	}, defs[0])
+	assert.Len(t, index.Definitions("Cart"), 2)
+
+	t.Run("refresh re-reads changed files only", func(t *testing.T) {
+		index.mu.Lock()
+		index.files["Main.java"].symbols = nil
+		index.mu.Unlock()
+
+		later := time.Now().Add(time.Hour)
+		require.NoError(t, os.WriteFile(filepath.Join(root, "shop/cart.go"), []byte("package shop\n\nfunc Pay() {}\n"), 0o600))
+		require.NoError(t, os.Chtimes(filepath.Join(root, "shop/cart.go"), later, later))
+		require.NoError(t, os.Remove(filepath.Join(root, "web/cart.ts")))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "lib.rs"), []byte("pub fn pay() {}\n"), 0o600))
+		require.NoError(t, index.Refresh(context.Background(), repo))
+
+		names = nil
+		for _, symbol := range index.Symbols(func(symbol Symbol) bool { return symbol.Language != "python" }) {
+			names = append(names, fmt.Sprintf("%s:%d %s", symbol.Path, symbol.Line, symbol.QualifiedName()))
+		}
+		assert.Equal(t, []string{"lib.rs:1 pay", "shop/cart.go:3 Pay"}, names, "Main.java was not re-read")
+	})
+}
+
+func TestHandleFindSymbol(t *testing.T) {
+	writeTree(t, symbolTree)
+
+	find := func(args map[string]interface{}) SymbolResults {
+		t.Helper()
+		result, err := handleFindSymbol(context.Background(), createMCPRequest(args), nil)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+		var results SymbolResults
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &results))
+		return results
+	}
+	names := func(results SymbolResults) []string {
+		var names []string
+		for _, symbol := range results.Symbols {
+			names = append(names, symbol.Path+" "+symbol.QualifiedName())
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"shop/cart.go Cart", "shop/cart.go Cart.Add", "shop/cart.py Cart", "shop/cart.py Cart.add", "shop/cart.py Cart.total", "web/cart.ts addToCart", "web/cart.ts removeFromCart"},
+		names(find(map[string]interface{}{"query": "cart"})))
+	assert.Equal(t, []string{"shop/cart.go Cart.Add", "shop/cart.py Cart.add"},
+		names(find(map[string]interface{}{"query": "cart.add", "match": "exact"})))
+	assert.Equal(t, []string{"shop/cart.go Cart.Add"},
+		names(find(map[string]interface{}{"query": "Add", "match": "exact", "case_sensitive": true})))
+	assert.Equal(t, []string{"shop/cart.py Cart", "shop/cart.py Cart.add", "shop/cart.py Cart.total"},
+		names(find(map[string]interface{}{"query": "cart", "match": "prefix", "language": "python"})))
+	assert.Equal(t, []string{"Main.java Main", "shop/cart.py Cart"},
+		names(find(map[string]interface{}{"query": "a", "kind": "class"})))
+	assert.Equal(t, []string{"Main.java Main.main"},
+		names(find(map[string]interface{}{"query": "main#main", "match": "exact"})))
+
+	results := find(map[string]interface{}{"query": "cart", "per_page": float64(3), "page": float64(2)})
+	assert.Equal(t, []string{"shop/cart.py Cart.add", "shop/cart.py Cart.total", "web/cart.ts addToCart"}, names(results))
+	assert.Equal(t, 3, results.NextPage)
+	assert.Equal(t, 7, results.TotalSymbols)
+
+	result, err := handleFindSymbol(context.Background(), createMCPRequest(map[string]interface{}{"query": "cart", "match": "fuzzy"}), nil)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Equal(t, "unknown match mode: fuzzy", getTextResult(t, result).Text)
+}
+
+func TestWorkingDirectoryIndexIsReused(t *testing.T) {
+	writeTree(t, symbolTree)
+	roots := &Roots{}
+
+	find := func() {
+		t.Helper()
+		result, err := handleFindSymbol(context.Background(), createMCPRequest(map[string]interface{}{"query": "cart"}), roots)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+	}
+	indexed := func() *indexedFile {
+		t.Helper()
+		repo, err := roots.Resolve("")
+		require.NoError(t, err)
+		index := repo.symbolIndex()
+		index.mu.RLock()
+		defer index.mu.RUnlock()
+		return index.files["shop/cart.py"]
+	}
+
+	find()
+	first, err := roots.Resolve("")
+	require.NoError(t, err)
+	file := indexed()
+	require.NotNil(t, file)
+
+	// The second call reuses the repository and does not extract unchanged files again
+	find()
+	second, err := roots.Resolve("")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Same(t, file, indexed())
+}
+
+func TestHandleGetDefinition(t *testing.T) {
+	writeTree(t, symbolTree)
+
+	define := func(args map[string]interface{}) []Definition {
+		t.Helper()
+		result, err := handleGetDefinition(context.Background(), createMCPRequest(args), nil)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+		var defs []Definition
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &defs))
+		return defs
+	}
+	code := func(defs []Definition) []string {
+		var code []string
+		for _, def := range defs {
+			code = append(code, fmt.Sprintf("%s:%d-%d\n%s", def.Path, def.StartLine, def.EndLine, def.Code))
+		}
+		return code
+	}
+
+	assert.Equal(t, []string{
+		"shop/cart.go:8-15\n// Add appends an item\n// to the cart\nfunc (c *Cart) Add(item string) {\n\tif item == \"}\" {\n\t\treturn\n\t}\n\tc.Items = append(c.Items, item)\n}",
+	}, code(define(map[string]interface{}{"name": "Add"})), "names are matched exactly")
+	assert.Equal(t, []string{
+		"shop/cart.py:7-12\n    def add(self, item: str, count: int = 1) -> None:\n        \"\"\"Add an item.\n\nText at column zero stays in the docstring.\n\"\"\"\n        self.items.append((item, count))",
+	}, code(define(map[string]interface{}{"name": "add"})))
+	assert.Equal(t, []string{
+		"shop/cart.py:16-18\n    @property\n    def total(self) -> float:\n        return sum(self.prices)",
+	}, code(define(map[string]interface{}{"name": "Cart.total"})))
+
+	assert.Equal(t, []string{
+		"shop/cart.go:3-6\n// Cart holds items\ntype Cart struct {\n\tItems []string\n}",
+	}, code(define(map[string]interface{}{"name": "Cart", "path": "shop/cart.go"})))
+	assert.Equal(t, []string{
+		"web/cart.ts:1-5\n/** Adds to a cart */\nexport function addToCart(id: string,\n    count: number): void {\n  console.log(\"{\", id);\n}",
+	}, code(define(map[string]interface{}{"name": "addToCart"})))
+	assert.Equal(t, []string{
+		"web/cart.ts:7-7\nexport const removeFromCart = (id: string) => id",
+	}, code(define(map[string]interface{}{"name": "removeFromCart"})))
+	assert.Equal(t, []string{
+		"Main.java:2-5\n    @Deprecated\n    public static void main(String[] args) {\n        System.out.println(\"}\");\n    }",
+	}, code(define(map[string]interface{}{"name": "main"})))
+
+	defs := define(map[string]interface{}{"name": "Cart", "path": "/shop/cart.py", "max_lines": float64(3)})
+	require.Len(t, defs, 1)
+	assert.Equal(t, "class Cart:\n    \"\"\"A shopping cart.\"\"\"\n", defs[0].Code)
+	assert.Equal(t, 6, defs[0].EndLine)
+	assert.True(t, defs[0].Truncated)
+
+	result, err := handleGetDefinition(context.Background(), createMCPRequest(map[string]interface{}{"name": "Missing"}), nil)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Equal(t, "no definition of Missing found", getTextResult(t, result).Text)
+}
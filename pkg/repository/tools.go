@@ -4,8 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/fs"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -13,18 +12,31 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
+const (
+	// defaultRepositoryFiles and maxRepositoryFiles bound how many source
+	// files extract_repository_signatures considers
+	defaultRepositoryFiles = 500
+	maxRepositoryFiles     = 5000
+
+	// maxExtractFileSize is the largest source file whose signatures are
+	// extracted; larger files are usually generated or bundled
+	maxExtractFileSize = 1 << 20
+)
+
 // GetFileList returns every file path in the repository with optional filtering and pagination
-func GetFileList() server.ServerTool {
-	tool, handler := getFileListImpl()
+func GetFileList(roots *Roots) server.ServerTool {
+	tool, handler := getFileListImpl(roots)
 	return server.ServerTool{
 		Tool:    tool,
 		Handler: handler,
 	}
 }
 
-func getFileListImpl() (mcp.Tool, server.ToolHandlerFunc) {
+func getFileListImpl(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
 	return mcp.NewTool("get_file_list",
-			mcp.WithDescription("Return every file path in the default branch of the *current* repo (paginated)."),
+			mcp.WithDescription("Return every file of the *current* repo, in the working tree or at a git revision, with its size and detected language (paginated). Files ignored by .gitignore, .git/info/exclude or .mcpprimeignore are left out."),
+			withRoot(roots),
+			withRef(),
 			mcp.WithNumber("per_page",
 				mcp.Description("Items per page (max 100)"),
 				mcp.DefaultNumber(100),
@@ -36,31 +48,60 @@ func getFileListImpl() (mcp.Tool, server.ToolHandlerFunc) {
 			mcp.WithString("extension",
 				mcp.Description("Optional filter, e.g. 'py', 'js', 'ts'"),
 			),
+			mcp.WithArray("extensions",
+				mcp.Description("Only list files with one of these extensions, e.g. ['py', 'pyi']"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithArray("include",
+				mcp.Description("Only list files matching one of these globs, e.g. 'src/**/*.py'. A glob without a slash matches a file or directory name at any depth"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithArray("exclude",
+				mcp.Description("Skip files matching any of these globs, e.g. 'tests' or '**/*_test.go'"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			return handleGetFileList(ctx, request)
+			return handleGetFileList(ctx, request, roots)
 		}
 }
 
-// GetFileContent returns the UTF-8 decoded content of any file in the repository
-func GetFileContent() server.ServerTool {
-	tool, handler := getFileContentImpl()
+// GetFileContent returns the UTF-8 decoded content of any file in the repository,
+// returning at most contentWindowSize bytes unless a call asks for another limit
+func GetFileContent(roots *Roots, contentWindowSize int) server.ServerTool {
+	tool, handler := getFileContentImpl(roots, contentWindowSize)
 	return server.ServerTool{
 		Tool:    tool,
 		Handler: handler,
 	}
 }
 
-func getFileContentImpl() (mcp.Tool, server.ToolHandlerFunc) {
+func getFileContentImpl(roots *Roots, contentWindowSize int) (mcp.Tool, server.ToolHandlerFunc) {
+	if contentWindowSize <= 0 {
+		contentWindowSize = defaultContentWindowSize
+	}
 	return mcp.NewTool("get_file_content",
-			mcp.WithDescription("Return the UTF-8 decoded content of any file in the current repo (default branch)."),
+			mcp.WithDescription("Return the UTF-8 decoded content of any file in the current repo, from the working tree or at a git revision. Long content is cut at max_bytes with a marker telling the start_line to continue from. Images are returned as base64 blobs and other binary files as their size and MIME type."),
+			withRoot(roots),
+			withRef(),
 			mcp.WithString("path",
 				mcp.Required(),
 				mcp.Description("Repository-relative path, e.g. 'src/utils.py'"),
 			),
+			mcp.WithNumber("start_line",
+				mcp.Description("First line to return, 1-based"),
+				mcp.DefaultNumber(1),
+			),
+			mcp.WithNumber("end_line",
+				mcp.Description("Last line to return, inclusive; defaults to the end of the file"),
+			),
+			mcp.WithNumber("max_bytes",
+				mcp.Description(fmt.Sprintf("Maximum number of bytes of content to return (max %d)", maxContentBytes)),
+				mcp.DefaultNumber(float64(contentWindowSize)),
+			),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			return handleGetFileContent(ctx, request)
+			return handleGetFileContent(ctx, request, roots, contentWindowSize)
 		}
 }
 
@@ -75,15 +116,17 @@ func ExtractSignatures() server.ServerTool {
 
 func extractSignaturesImpl() (mcp.Tool, server.ToolHandlerFunc) {
 	return mcp.NewTool("extract_signatures",
-			mcp.WithDescription("Parse Python or JavaScript/TypeScript source and emit every top-level function/class with its signature + docstring."),
+			mcp.WithDescription("Parse source code and emit every public function, class and method with its signature, docstring and line number."),
 			mcp.WithString("code",
 				mcp.Required(),
 				mcp.Description("Full source code to analyse"),
 			),
 			mcp.WithString("language",
-				mcp.Required(),
-				mcp.Description("Language of the code"),
-				mcp.Enum("python", "javascript", "typescript"),
+				mcp.Description("Language of the code. Detected from path or the shebang line when omitted"),
+				mcp.Enum(Languages()...),
+			),
+			mcp.WithString("path",
+				mcp.Description("File name or path of the code, used to detect the language, e.g. 'src/utils.py'"),
 			),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -91,6 +134,137 @@ func extractSignaturesImpl() (mcp.Tool, server.ToolHandlerFunc) {
 		}
 }
 
+// ExtractRepositorySignatures extracts the signatures of every supported source file in the repository
+func ExtractRepositorySignatures(roots *Roots) server.ServerTool {
+	tool, handler := extractRepositorySignaturesImpl(roots)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: handler,
+	}
+}
+
+func extractRepositorySignaturesImpl(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return mcp.NewTool("extract_repository_signatures",
+			mcp.WithDescription("Walk the current repo and emit the public functions, classes and methods of every supported source file, grouped by file with line numbers (paginated by file)."),
+			withRoot(roots),
+			withRef(),
+			mcp.WithArray("include",
+				mcp.Description("Only read files matching one of these globs, e.g. 'src/**/*.py'. A glob without a slash matches a file or directory name at any depth"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithArray("exclude",
+				mcp.Description("Skip files matching any of these globs, e.g. 'tests' or '**/*_test.go'"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithNumber("max_files",
+				mcp.Description(fmt.Sprintf("Maximum number of source files to consider (max %d)", maxRepositoryFiles)),
+				mcp.DefaultNumber(defaultRepositoryFiles),
+			),
+			mcp.WithNumber("per_page",
+				mcp.Description("Files per page (max 100)"),
+				mcp.DefaultNumber(20),
+			),
+			mcp.WithNumber("page",
+				mcp.Description("Page number"),
+				mcp.DefaultNumber(1),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handleExtractRepositorySignatures(ctx, request, roots)
+		}
+}
+
+// ConvertRepository converts every public function of the repository into tool definitions in one call
+func ConvertRepository(roots *Roots) server.ServerTool {
+	tool, handler := convertRepositoryImpl(roots)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: handler,
+	}
+}
+
+func convertRepositoryImpl(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return mcp.NewTool("convert_repository",
+			mcp.WithDescription("Convert the current repo into a bundle of OpenAI-style tool definitions in one call: list the source files, extract their public functions, methods and constructors, and emit a tool for each, with the source location of every tool and a report of skipped symbols."),
+			withRoot(roots),
+			withRef(),
+			mcp.WithArray("include",
+				mcp.Description("Only read files matching one of these globs, e.g. 'src/**/*.py'. A glob without a slash matches a file or directory name at any depth"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithArray("exclude",
+				mcp.Description("Skip files matching any of these globs, e.g. 'tests' or '**/*_test.go'"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithNumber("max_files",
+				mcp.Description(fmt.Sprintf("Maximum number of source files to read (max %d)", maxRepositoryFiles)),
+				mcp.DefaultNumber(defaultRepositoryFiles),
+			),
+			mcp.WithBoolean("include_deprecated",
+				mcp.Description("Convert symbols marked as deprecated instead of skipping them"),
+				mcp.DefaultBool(false),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handleConvertRepository(ctx, request, roots)
+		}
+}
+
+// GenerateServer scaffolds an MCP server project whose tools call the repository's functions
+func GenerateServer(roots *Roots) server.ServerTool {
+	tool, handler := generateServerImpl(roots)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: handler,
+	}
+}
+
+func generateServerImpl(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return mcp.NewTool("generate_server",
+			mcp.WithDescription("Scaffold an MCP server project, in Go using mcp-go or in Python using FastMCP, with a tool for each public function of the current repo. Each tool decodes its arguments into the function's parameter types, calls the original function and returns its result as JSON. Returns the project files, the tools with their source symbols and a report of skipped symbols, and writes the files when requested."),
+			withRoot(roots),
+			mcp.WithString("language",
+				mcp.Required(),
+				mcp.Description("Language of the generated server; only source files in this language are served"),
+				mcp.Enum(ServerLanguages()...),
+			),
+			mcp.WithString("name",
+				mcp.Description("Server name; defaults to the name of the repository directory"),
+			),
+			mcp.WithString("output_dir",
+				mcp.Description("Directory of the project, relative to the repo root and inside it. Generated imports and paths point from it back to the repo"),
+				mcp.DefaultString(DefaultServerDir),
+			),
+			mcp.WithBoolean("write",
+				mcp.Description("Write the files to output_dir instead of returning their content"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithArray("files",
+				mcp.Description("Signatures to serve, as the files returned by extract_repository_signatures. The repo is read when omitted"),
+				mcp.Items(map[string]interface{}{"type": "object"}),
+			),
+			mcp.WithArray("include",
+				mcp.Description("Only read files matching one of these globs when files is omitted"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithArray("exclude",
+				mcp.Description("Skip files matching any of these globs when files is omitted"),
+				mcp.Items(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithNumber("max_files",
+				mcp.Description(fmt.Sprintf("Maximum number of source files to read (max %d)", maxRepositoryFiles)),
+				mcp.DefaultNumber(defaultRepositoryFiles),
+			),
+			mcp.WithBoolean("include_deprecated",
+				mcp.Description("Serve symbols marked as deprecated instead of skipping them"),
+				mcp.DefaultBool(false),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handleGenerateServer(ctx, request, roots)
+		}
+}
+
 // EmitToolJSON converts function descriptors into OpenAI-style tool descriptions
 func EmitToolJSON() server.ServerTool {
 	tool, handler := emitToolJSONImpl()
@@ -102,10 +276,19 @@ func EmitToolJSON() server.ServerTool {
 
 func emitToolJSONImpl() (mcp.Tool, server.ToolHandlerFunc) {
 	return mcp.NewTool("emit_tool_json",
-			mcp.WithDescription("Convert a list of function/class descriptors into a single JSON array of OpenAI-style tool descriptions."),
+			mcp.WithDescription("Convert a list of function/class descriptors into tool descriptions, in the OpenAI format or that of another provider. Names are made valid and unique, parameters are validated as JSON Schema, and the tools are returned with the warnings and errors found."),
 			mcp.WithArray("functions",
 				mcp.Required(),
-				mcp.Description("Each item must have: name, description, parameters (object), required (array[string])"),
+				mcp.Description("Each item must have: name, description, parameters (object), required (array[string]). Optional: deprecated (boolean), examples (array[string]), type (string), class (string). Methods are named Class_method and constructors Class_new"),
+			),
+			mcp.WithBoolean("include_deprecated",
+				mcp.Description("Emit functions marked as deprecated instead of skipping them"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format: 'openai' function tools, 'mcp' tools/list tool objects, 'anthropic' tool-use tools, 'gemini' function declarations or plain 'jsonschema' schemas. Names and descriptions are cut to each provider's limits"),
+				mcp.Enum(ToolFormats()...),
+				mcp.DefaultString(DefaultToolFormat),
 			),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -113,7 +296,7 @@ func emitToolJSONImpl() (mcp.Tool, server.ToolHandlerFunc) {
 		}
 }
 
-func handleGetFileList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func handleGetFileList(ctx context.Context, req mcp.CallToolRequest, roots *Roots) (*mcp.CallToolResult, error) {
 	perPage, err := OptionalParam[float64](req, "per_page")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -121,7 +304,7 @@ func handleGetFileList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	if perPage == 0 {
 		perPage = 100
 	}
-	
+
 	page, err := OptionalParam[float64](req, "page")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -129,12 +312,30 @@ func handleGetFileList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	if page == 0 {
 		page = 1
 	}
-	
+
 	extension, err := OptionalParam[string](req, "extension")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	extensions, err := OptionalStringArrayParam(req, "extensions")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if extension != "" {
+		extensions = append(extensions, extension)
+	}
+
+	include, err := OptionalStringArrayParam(req, "include")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	exclude, err := OptionalStringArrayParam(req, "exclude")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	// Set limits
 	if perPage > 100 {
 		perPage = 100
@@ -146,51 +347,24 @@ func handleGetFileList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		page = 1
 	}
 
-	// Get current working directory as repository root
-	repoRoot, err := os.Getwd()
+	repo, release, err := requestRepository(req, roots)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get working directory: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	defer release()
 
 	var allFiles []string
 
 	// Walk through all files in the repository
-	err = filepath.WalkDir(repoRoot, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip hidden directories and common build/dependency directories
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".") && name != "." {
-				return filepath.SkipDir
-			}
-			if name == "node_modules" || name == "vendor" || name == "__pycache__" || name == "dist" || name == "build" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Get relative path
-		relPath, err := filepath.Rel(repoRoot, path)
-		if err != nil {
+	err = walkRepository(repo, func(relPath string) error {
+		if err := ctx.Err(); err != nil {
 			return err
 		}
 
-		// Skip hidden files
-		if strings.HasPrefix(filepath.Base(relPath), ".") {
+		if !matchFilters(relPath, include, exclude) || !matchExtension(relPath, extensions) {
 			return nil
 		}
 
-		// Filter by extension if specified
-		if extension != "" {
-			ext := strings.TrimPrefix(filepath.Ext(relPath), ".")
-			if ext != extension {
-				return nil
-			}
-		}
-
 		allFiles = append(allFiles, relPath)
 		return nil
 	})
@@ -211,7 +385,15 @@ func handleGetFileList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		end = len(allFiles)
 	}
 
-	pageFiles := allFiles[start:end]
+	// Only the files of the page are inspected
+	pageFiles := make([]FileEntry, 0, end-start)
+	for _, relPath := range allFiles[start:end] {
+		entry := FileEntry{Path: relPath, Language: fileLanguage(repo, relPath)}
+		if info, err := repo.Stat(relPath); err == nil {
+			entry.Size = info.Size()
+		}
+		pageFiles = append(pageFiles, entry)
+	}
 
 	// Convert to JSON
 	result, err := json.MarshalIndent(pageFiles, "", "  ")
@@ -222,43 +404,74 @@ func handleGetFileList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	return mcp.NewToolResultText(string(result)), nil
 }
 
-func handleGetFileContent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// matchExtension reports whether a path has one of the extensions, given
+// with or without their leading dot, or there are none
+func matchExtension(name string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	for _, candidate := range extensions {
+		if strings.EqualFold(strings.TrimPrefix(candidate, "."), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func handleGetFileContent(ctx context.Context, req mcp.CallToolRequest, roots *Roots, contentWindowSize int) (*mcp.CallToolResult, error) {
 	path, err := RequiredParam[string](req, "path")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Get current working directory as repository root
-	repoRoot, err := os.Getwd()
+	startLine, err := OptionalParam[float64](req, "start_line")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get working directory: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if startLine <= 0 {
+		startLine = 1
 	}
 
-	// Resolve the full path
-	fullPath := filepath.Join(repoRoot, path)
-
-	// Security: ensure the path is within the repository
-	cleanPath, err := filepath.Abs(fullPath)
+	endLine, err := OptionalParam[float64](req, "end_line")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to resolve path: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if endLine < 0 || (endLine > 0 && endLine < startLine) {
+		return mcp.NewToolResultError("end_line must not be before start_line"), nil
 	}
 
-	cleanRepoRoot, err := filepath.Abs(repoRoot)
+	maxBytes, err := OptionalParam[float64](req, "max_bytes")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to resolve repository root: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if maxBytes <= 0 {
+		maxBytes = float64(contentWindowSize)
 	}
+	maxBytes = min(maxBytes, maxContentBytes)
 
-	if !strings.HasPrefix(cleanPath, cleanRepoRoot) {
-		return mcp.NewToolResultError("path is outside repository bounds"), nil
+	repo, release, err := requestRepository(req, roots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	defer release()
 
-	// Read file content
-	content, err := os.ReadFile(cleanPath)
+	// Security: the path, and any symlink along it, must stay within the
+	// repository, which the RepoFS checks on every access
+	name, err := cleanRepoPath(path)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to read file: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return mcp.NewToolResultText(string(content)), nil
+	result, err := fileContentResult(repo, name, lineRange{
+		StartLine: int(startLine),
+		EndLine:   int(endLine),
+		MaxBytes:  int(maxBytes),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return result, nil
 }
 
 func handleExtractSignatures(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -267,22 +480,30 @@ func handleExtractSignatures(ctx context.Context, req mcp.CallToolRequest) (*mcp
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	language, err := RequiredParam[string](req, "language")
+	language, err := OptionalParam[string](req, "language")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	var signatures []FunctionSignature
+	path, err := OptionalParam[string](req, "path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	switch language {
-	case "python":
-		signatures, err = extractPythonSignatures(code)
-	case "javascript", "typescript":
-		signatures, err = extractJavaScriptSignatures(code)
-	default:
+	if language == "" {
+		detected, ok := DetectLanguage(path, code)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("could not detect the language, pass one of: %s", strings.Join(Languages(), ", "))), nil
+		}
+		language = detected
+	}
+
+	extractor, ok := LookupExtractor(language)
+	if !ok {
 		return mcp.NewToolResultError(fmt.Sprintf("unsupported language: %s", language)), nil
 	}
 
+	signatures, err := extractor.Extract(code)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to extract signatures: %v", err)), nil
 	}
@@ -295,6 +516,237 @@ func handleExtractSignatures(ctx context.Context, req mcp.CallToolRequest) (*mcp
 	return mcp.NewToolResultText(string(result)), nil
 }
 
+func handleExtractRepositorySignatures(ctx context.Context, req mcp.CallToolRequest, roots *Roots) (*mcp.CallToolResult, error) {
+	include, err := OptionalStringArrayParam(req, "include")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	exclude, err := OptionalStringArrayParam(req, "exclude")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	maxFiles, err := OptionalParam[float64](req, "max_files")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if maxFiles <= 0 {
+		maxFiles = defaultRepositoryFiles
+	}
+	maxFiles = min(maxFiles, maxRepositoryFiles)
+
+	perPage, err := OptionalParam[float64](req, "per_page")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if perPage <= 0 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	page, err := OptionalParam[float64](req, "page")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	repo, release, err := requestRepository(req, roots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	// Collect the supported files first so pages are stable
+	files, truncated, err := collectSourceFiles(ctx, repo, include, exclude, int(maxFiles))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to walk directory: %v", err)), nil
+	}
+
+	result := RepositorySignatures{
+		Files:      []FileSignatures{},
+		Page:       int(page),
+		PerPage:    int(perPage),
+		TotalFiles: len(files),
+		Truncated:  truncated,
+	}
+
+	start := min((int(page)-1)*int(perPage), len(files))
+	end := min(start+int(perPage), len(files))
+	if end < len(files) {
+		result.NextPage = int(page) + 1
+	}
+
+	for _, file := range files[start:end] {
+		group := FileSignatures{Path: file.Path, Language: file.Language}
+		signatures, err := extractFileSignatures(repo, file.Path, file.Language)
+		switch {
+		case err != nil:
+			group.Error = err.Error()
+		case len(signatures) == 0:
+			continue
+		default:
+			group.Signatures = signatures
+		}
+		result.Files = append(result.Files, group)
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal signatures: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+func handleConvertRepository(ctx context.Context, req mcp.CallToolRequest, roots *Roots) (*mcp.CallToolResult, error) {
+	include, err := OptionalStringArrayParam(req, "include")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	exclude, err := OptionalStringArrayParam(req, "exclude")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	maxFiles, err := OptionalParam[float64](req, "max_files")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	includeDeprecated, err := OptionalParam[bool](req, "include_deprecated")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	repo, release, err := requestRepository(req, roots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	bundle, err := Convert(ctx, repo, ConvertOptions{
+		Include:           include,
+		Exclude:           exclude,
+		MaxFiles:          int(min(maxFiles, maxRepositoryFiles)),
+		IncludeDeprecated: includeDeprecated,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal tool bundle: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(result)), nil
+}
+
+func handleGenerateServer(ctx context.Context, req mcp.CallToolRequest, roots *Roots) (*mcp.CallToolResult, error) {
+	language, err := RequiredParam[string](req, "language")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	name, err := OptionalParam[string](req, "name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	outputDir, err := OptionalParam[string](req, "output_dir")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if outputDir == "" {
+		outputDir = DefaultServerDir
+	}
+
+	write, err := OptionalParam[bool](req, "write")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	include, err := OptionalStringArrayParam(req, "include")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	exclude, err := OptionalStringArrayParam(req, "exclude")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	maxFiles, err := OptionalParam[float64](req, "max_files")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	includeDeprecated, err := OptionalParam[bool](req, "include_deprecated")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var files []FileSignatures
+	if filesParam, ok := req.GetArguments()["files"]; ok && filesParam != nil {
+		// Convert to JSON bytes first then unmarshal to ensure proper type conversion
+		filesJSON, err := json.Marshal(filesParam)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal files parameter: %v", err)), nil
+		}
+		if err := json.Unmarshal(filesJSON, &files); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to unmarshal files: %v", err)), nil
+		}
+	}
+
+	repo, err := requestRoot(req, roots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// The project may only be written inside the repository
+	cleanDir := filepath.Clean(outputDir)
+	if filepath.IsAbs(cleanDir) || cleanDir == "." || cleanDir == ".." || strings.HasPrefix(cleanDir, ".."+string(filepath.Separator)) {
+		return mcp.NewToolResultError("output_dir must be a subdirectory of the repository"), nil
+	}
+
+	project, err := Generate(ctx, repo, GenerateOptions{
+		Language:          language,
+		Name:              name,
+		OutputDir:         cleanDir,
+		Files:             files,
+		Include:           include,
+		Exclude:           exclude,
+		MaxFiles:          int(min(maxFiles, maxRepositoryFiles)),
+		IncludeDeprecated: includeDeprecated,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if write {
+		if err := project.writeTo(repo, filepath.ToSlash(cleanDir)); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("cannot write the project: %v", err)), nil
+		}
+		// The files are on disk, so only their paths are returned
+		for i := range project.Files {
+			project.Files[i].Content = ""
+		}
+	}
+
+	result, err := json.MarshalIndent(project, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal server project: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(result)), nil
+}
+
 func handleEmitToolJSON(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	functionsParam, ok := req.GetArguments()["functions"]
 	if !ok {
@@ -316,19 +768,34 @@ func handleEmitToolJSON(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return mcp.NewToolResultError("functions parameter cannot be empty"), nil
 	}
 
-	tools := make([]ToolDefinition, len(functions))
-	for i, fn := range functions {
-		tools[i] = ToolDefinition{
-			Type: "function",
-			Function: FunctionDef{
-				Name:        fn.Name,
-				Description: fn.Description,
-				Parameters:  fn.Parameters,
-			},
-		}
+	includeDeprecated, err := OptionalParam[bool](req, "include_deprecated")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	formatName, err := OptionalParam[string](req, "format")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if formatName == "" {
+		formatName = DefaultToolFormat
+	}
+	format, ok := lookupToolFormat(formatName)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported format: %s, pass one of: %s", formatName, strings.Join(ToolFormats(), ", "))), nil
 	}
 
-	result, err := json.MarshalIndent(tools, "", "  ")
+	tools, warnings, errors := format.emit(functions, includeDeprecated)
+	emitted := EmitResult{
+		Tools:    make([]interface{}, 0, len(tools)),
+		Warnings: warnings,
+		Errors:   errors,
+	}
+	for _, tool := range tools {
+		emitted.Tools = append(emitted.Tools, tool.Tool)
+	}
+
+	result, err := json.MarshalIndent(emitted, "", "  ")
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal tool definitions: %v", err)), nil
 	}
@@ -336,14 +803,43 @@ func handleEmitToolJSON(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	return mcp.NewToolResultText(string(result)), nil
 }
 
+// toolName renders a function's qualified name as a tool name. Members
+// become Class_method, whatever separator the language uses, and
+// constructors such as Person.__init__ or Canvas::Canvas become Class_new.
+func toolName(fn FunctionDescriptor) string {
+	name := fn.Name
+	if fn.Type == "constructor" && fn.Class != "" {
+		for _, sep := range []string{".", "::", "#"} {
+			if strings.HasPrefix(name, fn.Class+sep) {
+				name = fn.Class + ".new"
+				break
+			}
+		}
+	}
+	return strings.NewReplacer("::", "_", ".", "_", "#", "_").Replace(name)
+}
+
+// toolDescription renders a function's description for a tool definition,
+// flagging deprecated functions and appending documented examples
+func toolDescription(fn FunctionDescriptor) string {
+	description := fn.Description
+	if fn.Deprecated {
+		description = strings.TrimSpace("Deprecated. " + description)
+	}
+	if len(fn.Examples) > 0 {
+		description += "\n\nExamples:\n" + strings.Join(fn.Examples, "\n\n")
+	}
+	return description
+}
+
 // GetFileListTool returns the tool and handler separately for direct MCP server registration
-func GetFileListTool() (mcp.Tool, server.ToolHandlerFunc) {
-	return getFileListImpl()
+func GetFileListTool(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return getFileListImpl(roots)
 }
 
 // GetFileContentTool returns the tool and handler separately for direct MCP server registration
-func GetFileContentTool() (mcp.Tool, server.ToolHandlerFunc) {
-	return getFileContentImpl()
+func GetFileContentTool(roots *Roots, contentWindowSize int) (mcp.Tool, server.ToolHandlerFunc) {
+	return getFileContentImpl(roots, contentWindowSize)
 }
 
 // ExtractSignaturesTool returns the tool and handler separately for direct MCP server registration
@@ -351,11 +847,48 @@ func ExtractSignaturesTool() (mcp.Tool, server.ToolHandlerFunc) {
 	return extractSignaturesImpl()
 }
 
+// ExtractRepositorySignaturesTool returns the tool and handler separately for direct MCP server registration
+func ExtractRepositorySignaturesTool(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return extractRepositorySignaturesImpl(roots)
+}
+
+// ConvertRepositoryTool returns the tool and handler separately for direct MCP server registration
+func ConvertRepositoryTool(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return convertRepositoryImpl(roots)
+}
+
+// GenerateServerTool returns the tool and handler separately for direct MCP server registration
+func GenerateServerTool(roots *Roots) (mcp.Tool, server.ToolHandlerFunc) {
+	return generateServerImpl(roots)
+}
+
 // EmitToolJSONTool returns the tool and handler separately for direct MCP server registration
 func EmitToolJSONTool() (mcp.Tool, server.ToolHandlerFunc) {
 	return emitToolJSONImpl()
 }
 
+// ToolNames returns the names of the repository analysis tools
+func ToolNames() []string {
+	tools := []mcp.Tool{
+		GetFileList(nil).Tool,
+		GetFileContent(nil, 0).Tool,
+		ExtractSignatures().Tool,
+		ExtractRepositorySignatures(nil).Tool,
+		SearchCode(nil).Tool,
+		FindSymbol(nil).Tool,
+		GetDefinition(nil).Tool,
+		FindReferences(nil).Tool,
+		ConvertRepository(nil).Tool,
+		GenerateServer(nil).Tool,
+		EmitToolJSON().Tool,
+	}
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
 // Parameter helper functions (copied from github package)
 func RequiredParam[T comparable](r mcp.CallToolRequest, p string) (T, error) {
 	var zero T
@@ -392,4 +925,32 @@ func OptionalParam[T any](r mcp.CallToolRequest, p string) (T, error) {
 	}
 
 	return r.GetArguments()[p].(T), nil
-}
\ No newline at end of file
+}
+
+// OptionalStringArrayParam returns an optional string array parameter,
+// accepting both []string and []any values (copied from github package)
+func OptionalStringArrayParam(r mcp.CallToolRequest, p string) ([]string, error) {
+	// Check if the parameter is present in the request
+	if _, ok := r.GetArguments()[p]; !ok {
+		return []string{}, nil
+	}
+
+	switch v := r.GetArguments()[p].(type) {
+	case nil:
+		return []string{}, nil
+	case []string:
+		return v, nil
+	case []any:
+		strSlice := make([]string, len(v))
+		for i, v := range v {
+			s, ok := v.(string)
+			if !ok {
+				return []string{}, fmt.Errorf("parameter %s is not of type string, is %T", p, v)
+			}
+			strSlice[i] = s
+		}
+		return strSlice, nil
+	default:
+		return []string{}, fmt.Errorf("parameter %s could not be coerced to []string, is %T", p, r.GetArguments()[p])
+	}
+}
@@ -1,7 +1,12 @@
 package repository
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExtractPythonSignatures(t *testing.T) {
@@ -39,8 +44,8 @@ def _private_function():
 		t.Fatalf("Failed to extract Python signatures: %v", err)
 	}
 
-	if len(signatures) != 2 {
-		t.Errorf("Expected 2 signatures, got %d", len(signatures))
+	if len(signatures) != 3 {
+		t.Errorf("Expected 3 signatures, got %d", len(signatures))
 	}
 
 	// Check function signature
@@ -75,6 +80,24 @@ def _private_function():
 	if !found {
 		t.Error("Expected to find Person class")
 	}
+
+	// Check constructor signature
+	found = false
+	for _, sig := range signatures {
+		if sig.Name == "Person.__init__" && sig.Type == "constructor" {
+			found = true
+			if sig.Class != "Person" {
+				t.Errorf("Expected constructor of Person, got %q", sig.Class)
+			}
+			if len(sig.Required) != 1 || sig.Required[0] != "name" {
+				t.Errorf("Expected constructor to require name, got %v", sig.Required)
+			}
+			break
+		}
+	}
+	if !found {
+		t.Error("Expected to find Person.__init__ constructor")
+	}
 }
 
 func TestExtractJavaScriptSignatures(t *testing.T) {
@@ -150,32 +173,111 @@ function _privateFunction() {
 	}
 }
 
+func TestExtractJavaScriptClassMembers(t *testing.T) {
+	code := `
+/**
+ * A shopping cart.
+ * @param {string} owner - Who the cart belongs to
+ */
+export class Cart {
+    #items = [];
+
+    constructor(owner: string, currency = "EUR") {
+        this.owner = owner;
+    }
+
+    /**
+     * Adds an item to the cart.
+     * @param {string} sku - Stock keeping unit
+     */
+    add(sku: string, quantity?: number): void {
+        const inner = function helper(x) { return x; };
+        if (quantity) {
+            this.#items.push(sku);
+        }
+    }
+
+    /** Creates an empty cart. */
+    static empty(): Cart {
+        return new Cart("nobody");
+    }
+
+    total = (discount: number) => {
+        return 0;
+    };
+
+    private reset(): void {}
+    protected audit(): void {}
+    _cache(): void {}
+    get size() { return 0; }
+}
+
+function helper() {
+    const text = ` + "`{`" + `;
+    return text;
+}
+`
+
+	signatures, err := extractJavaScriptSignatures(code)
+	require.NoError(t, err)
+
+	byName := map[string]FunctionSignature{}
+	var names []string
+	for _, sig := range signatures {
+		byName[sig.Name] = sig
+		names = append(names, sig.Name)
+	}
+	assert.Equal(t, []string{"Cart", "Cart.constructor", "Cart.add", "Cart.empty", "Cart.total", "helper"}, names)
+
+	ctor := byName["Cart.constructor"]
+	assert.Equal(t, "constructor", ctor.Type)
+	assert.Equal(t, "Cart", ctor.Class)
+	assert.Equal(t, "A shopping cart.", ctor.Description)
+	assert.Equal(t, []string{"owner"}, ctor.Required)
+	assert.Equal(t, "Who the cart belongs to", ctor.Parameters["properties"].(map[string]interface{})["owner"].(map[string]interface{})["description"])
+	assert.Equal(t, 9, ctor.Line)
+
+	add := byName["Cart.add"]
+	assert.Equal(t, "method", add.Type)
+	assert.Equal(t, "Cart", add.Class)
+	assert.False(t, add.Static)
+	assert.Equal(t, []string{"sku"}, add.Required)
+	assert.Contains(t, add.Description, "Adds an item to the cart.")
+
+	empty := byName["Cart.empty"]
+	assert.True(t, empty.Static)
+	assert.Equal(t, "Creates an empty cart.", empty.Description)
+
+	assert.Equal(t, []string{"discount"}, byName["Cart.total"].Required)
+	assert.Equal(t, "function", byName["helper"].Type)
+}
+
 func TestParsePythonParameters(t *testing.T) {
 	params := "name: str, age: int = 25, *args, **kwargs"
-	
-	parameters, required := parsePythonParameters(params)
-	
+
+	parameters, required := parsePythonParameters(params, nil, nil)
+
 	if len(required) != 1 {
 		t.Errorf("Expected 1 required parameter, got %d", len(required))
 	}
-	
+
 	if required[0] != "name" {
 		t.Errorf("Expected 'name' to be required, got %s", required[0])
 	}
-	
+
 	props, ok := parameters["properties"].(map[string]interface{})
 	if !ok {
 		t.Fatal("Expected properties to be a map")
 	}
-	
+
 	if len(props) < 2 {
 		t.Errorf("Expected at least 2 properties, got %d", len(props))
 	}
-	
+
 	if _, ok := props["name"]; !ok {
 		t.Error("Expected 'name' property to exist")
 	}
-	
+
 	if _, ok := props["age"]; !ok {
 		t.Error("Expected 'age' property to exist")
 	}
@@ -183,23 +285,235 @@ func TestParsePythonParameters(t *testing.T) {
 
 func TestParseJavaScriptParameters(t *testing.T) {
 	params := "a, b = 10, ...rest"
-	
-	parameters, required := parseJavaScriptParameters(params)
-	
+
+	parameters, required := parseJavaScriptParameters(params, nil, nil)
+
 	if len(required) != 1 {
 		t.Errorf("Expected 1 required parameter, got %d", len(required))
 	}
-	
+
 	if required[0] != "a" {
 		t.Errorf("Expected 'a' to be required, got %s", required[0])
 	}
-	
+
 	props, ok := parameters["properties"].(map[string]interface{})
 	if !ok {
 		t.Fatal("Expected properties to be a map")
 	}
-	
+
 	if len(props) < 2 {
 		t.Errorf("Expected at least 2 properties, got %d", len(props))
 	}
-}
\ No newline at end of file
+}
+
+func TestHandleEmitToolJSONDeprecatedAndExamples(t *testing.T) {
+	functions := []interface{}{
+		map[string]interface{}{
+			"name":        "move",
+			"description": "Moves the cursor.",
+			"parameters":  map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+			"required":    []interface{}{},
+			"examples":    []interface{}{"move(3)"},
+		},
+		map[string]interface{}{
+			"name":        "old",
+			"description": "Legacy mover.",
+			"parameters":  map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+			"required":    []interface{}{},
+			"deprecated":  true,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		expected []string
+	}{
+		{
+			name:     "deprecated skipped by default",
+			args:     map[string]interface{}{"functions": functions},
+			expected: []string{"Moves the cursor.\n\nExamples:\nmove(3)"},
+		},
+		{
+			name:     "deprecated included on request",
+			args:     map[string]interface{}{"functions": functions, "include_deprecated": true},
+			expected: []string{"Moves the cursor.\n\nExamples:\nmove(3)", "Deprecated. Legacy mover."},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := handleEmitToolJSON(context.Background(), createMCPRequest(tc.args))
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+
+			var emitted struct {
+				Tools []ToolDefinition `json:"tools"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &emitted))
+
+			var descriptions []string
+			for _, tool := range emitted.Tools {
+				descriptions = append(descriptions, tool.Function.Description)
+			}
+			assert.Equal(t, tc.expected, descriptions)
+		})
+	}
+}
+
+func TestHandleEmitToolJSONMethodNames(t *testing.T) {
+	descriptor := func(name, kind, class string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":        name,
+			"type":        kind,
+			"class":       class,
+			"description": name,
+			"parameters":  map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+			"required":    []interface{}{},
+		}
+	}
+	functions := []interface{}{
+		descriptor("greet", "function", ""),
+		descriptor("Person.__init__", "constructor", "Person"),
+		descriptor("Person.greet", "method", "Person"),
+		descriptor("geo::Canvas::Canvas", "constructor", "geo::Canvas"),
+		descriptor("UserService.Point#Point", "constructor", "UserService.Point"),
+		descriptor("User::rename", "method", "User"),
+		descriptor("NewOrder", "constructor", "Order"),
+	}
+
+	result, err := handleEmitToolJSON(context.Background(), createMCPRequest(map[string]interface{}{"functions": functions}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var emitted struct {
+		Tools []ToolDefinition `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &emitted))
+
+	var names []string
+	for _, tool := range emitted.Tools {
+		names = append(names, tool.Function.Name)
+	}
+	assert.Equal(t, []string{
+		"greet", "Person_new", "Person_greet", "geo_Canvas_new", "UserService_Point_new", "User_rename", "NewOrder",
+	}, names)
+}
+
+func TestHandleExtractRepositorySignatures(t *testing.T) {
+	writeTree(t, map[string]string{
+		"app.py":                    "def greet(name: str) -> str:\n    \"\"\"Greets.\"\"\"\n",
+		"src/util.js":               "\n\nexport function add(a, b) {\n    return a + b;\n}\n",
+		"src/empty.py":              "# nothing public\n_x = 1\n",
+		"src/broken.go":             "package broken\nfunc (",
+		"tests/test_app.py":         "def test_greet():\n    pass\n",
+		"bin/tool":                  "#!/usr/bin/env python3\ndef main():\n    pass\n",
+		"README.md":                 "# readme\n",
+		"node_modules/lib/index.js": "export function skipped() {}\n",
+		".hidden/secret.py":         "def secret():\n    pass\n",
+	})
+
+	call := func(args map[string]interface{}) RepositorySignatures {
+		t.Helper()
+		result, err := handleExtractRepositorySignatures(context.Background(), createMCPRequest(args), nil)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+
+		var page RepositorySignatures
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &page))
+		return page
+	}
+	paths := func(page RepositorySignatures) []string {
+		var paths []string
+		for _, file := range page.Files {
+			paths = append(paths, file.Path)
+		}
+		return paths
+	}
+
+	all := call(map[string]interface{}{})
+	assert.Equal(t, 6, all.TotalFiles)
+	assert.Equal(t, []string{"app.py", "bin/tool", "src/broken.go", "src/util.js", "tests/test_app.py"}, paths(all))
+	assert.Zero(t, all.NextPage)
+	assert.False(t, all.Truncated)
+
+	app := all.Files[0]
+	assert.Equal(t, "python", app.Language)
+	require.Len(t, app.Signatures, 1)
+	assert.Equal(t, "greet", app.Signatures[0].Name)
+	assert.Equal(t, 1, app.Signatures[0].Line)
+
+	assert.NotEmpty(t, all.Files[2].Error)
+	assert.Equal(t, 3, all.Files[3].Signatures[0].Line)
+
+	filtered := call(map[string]interface{}{"include": []interface{}{"*.py"}, "exclude": []interface{}{"tests"}})
+	assert.Equal(t, []string{"app.py"}, paths(filtered))
+	assert.Equal(t, 2, filtered.TotalFiles)
+
+	paged := call(map[string]interface{}{"per_page": float64(2), "page": float64(2)})
+	assert.Equal(t, []string{"src/broken.go"}, paths(paged))
+	assert.Equal(t, 3, paged.NextPage)
+
+	capped := call(map[string]interface{}{"max_files": float64(2)})
+	assert.Equal(t, 2, capped.TotalFiles)
+	assert.True(t, capped.Truncated)
+}
+
+func TestHandleGetFileList(t *testing.T) {
+	writeTree(t, map[string]string{
+		".gitignore":        "*.log\ncoverage/\n",
+		".mcpprimeignore":   "assets/*.png\n",
+		"app.py":            "def greet():\n    pass\n",
+		"src/util.js":       "export function add(a, b) {}\n",
+		"src/types.ts":      "export type A = string;\n",
+		"docs/guide.md":     "# guide\n",
+		"bin/tool":          "#!/usr/bin/env python3\n",
+		"Makefile":          "all:\n",
+		"assets/logo.png":   "png",
+		"assets/data.bin":   "\x00\x01",
+		"debug.log":         "log",
+		"coverage/cov.html": "<html>",
+	})
+
+	call := func(args map[string]interface{}) []FileEntry {
+		t.Helper()
+		result, err := handleGetFileList(context.Background(), createMCPRequest(args), nil)
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextResult(t, result).Text)
+
+		var files []FileEntry
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &files))
+		return files
+	}
+	paths := func(files []FileEntry) []string {
+		var paths []string
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		return paths
+	}
+
+	all := call(map[string]interface{}{})
+	assert.Equal(t, []FileEntry{
+		{Path: "Makefile", Size: 5, Language: "makefile"},
+		{Path: "app.py", Size: 22, Language: "python"},
+		{Path: "assets/data.bin", Size: 2},
+		{Path: "bin/tool", Size: 23, Language: "python"},
+		{Path: "docs/guide.md", Size: 8, Language: "markdown"},
+		{Path: "src/types.ts", Size: 24, Language: "typescript"},
+		{Path: "src/util.js", Size: 29, Language: "javascript"},
+	}, all)
+
+	assert.Equal(t, []string{"src/types.ts", "src/util.js"}, paths(call(map[string]interface{}{
+		"extensions": []interface{}{"js", ".ts"},
+	})))
+	assert.Equal(t, []string{"app.py"}, paths(call(map[string]interface{}{"extension": "py"})))
+	assert.Equal(t, []string{"src/util.js"}, paths(call(map[string]interface{}{
+		"include": []interface{}{"src/**"},
+		"exclude": []interface{}{"*.ts"},
+	})))
+	assert.Equal(t, []string{"assets/data.bin", "bin/tool"}, paths(call(map[string]interface{}{
+		"per_page": float64(2),
+		"page":     float64(2),
+	})))
+}
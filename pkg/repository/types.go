@@ -1,21 +1,34 @@
 package repository
 
+import "github.com/mark3labs/mcp-go/mcp"
+
 // FunctionSignature represents a function or class extracted from source code
 type FunctionSignature struct {
 	Name        string                 `json:"name"`
-	Type        string                 `json:"type"` // "function" or "class"
+	Type        string                 `json:"type"`             // "function", "class", "method", "constructor", "struct", "interface", "enum" or "trait"
+	Class       string                 `json:"class,omitempty"`  // owning class, type or trait of a method or constructor
+	Static      bool                   `json:"static,omitempty"` // static method or Python classmethod, called without an instance
 	Signature   string                 `json:"signature"`
 	Description string                 `json:"description"`
 	Parameters  map[string]interface{} `json:"parameters,omitempty"`
 	Required    []string               `json:"required,omitempty"`
+	Returns     string                 `json:"returns,omitempty"` // documented return value
+	Raises      []string               `json:"raises,omitempty"`  // documented exceptions, e.g. "ValueError: if x is negative"
+	Deprecated  bool                   `json:"deprecated,omitempty"`
+	Examples    []string               `json:"examples,omitempty"`
+	Line        int                    `json:"line,omitempty"` // 1-based line where the symbol is declared
 }
 
 // FunctionDescriptor represents a function descriptor for tool generation
 type FunctionDescriptor struct {
 	Name        string                 `json:"name"`
+	Type        string                 `json:"type,omitempty"`  // kind of symbol, as in FunctionSignature
+	Class       string                 `json:"class,omitempty"` // owning class of a method or constructor
 	Description string                 `json:"description"`
 	Parameters  map[string]interface{} `json:"parameters"`
 	Required    []string               `json:"required"`
+	Deprecated  bool                   `json:"deprecated,omitempty"`
+	Examples    []string               `json:"examples,omitempty"`
 }
 
 // ToolDefinition represents an OpenAI-compatible tool definition
@@ -29,4 +42,137 @@ type FunctionDef struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	Parameters  map[string]interface{} `json:"parameters"`
-}
\ No newline at end of file
+}
+
+// MCPToolDefinition is a tool object as listed by an MCP server's tools/list
+type MCPToolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations *mcp.ToolAnnotation    `json:"annotations,omitempty"`
+}
+
+// AnthropicToolDefinition is a tool definition for the Anthropic Messages API
+type AnthropicToolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// GeminiFunctionDeclaration is a function declaration for the Gemini API
+type GeminiFunctionDeclaration struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// FileEntry is a repository file listed by get_file_list
+type FileEntry struct {
+	Path     string `json:"path"` // slash-separated, relative to the repository root
+	Size     int64  `json:"size"` // in bytes
+	Language string `json:"language,omitempty"`
+}
+
+// BinaryFile describes a binary file whose content get_file_content does not return
+type BinaryFile struct {
+	Path     string `json:"path"` // slash-separated, relative to the repository root
+	Size     int64  `json:"size"` // in bytes
+	MIMEType string `json:"mime_type"`
+	Binary   bool   `json:"binary"`
+	Note     string `json:"note"`
+}
+
+// FileSignatures groups the signatures extracted from one repository file
+type FileSignatures struct {
+	Path       string              `json:"path"` // slash-separated, relative to the repository root
+	Language   string              `json:"language"`
+	Signatures []FunctionSignature `json:"signatures,omitempty"`
+	Error      string              `json:"error,omitempty"` // why the file could not be read or parsed
+}
+
+// SearchMatch is a line matching a search_code query, with its context
+type SearchMatch struct {
+	Path   string   `json:"path"`
+	Line   int      `json:"line"`
+	Column int      `json:"column"` // 1-based, in runes, of the start of the first match
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// SearchResults is one page of search_code results. Pages cover the
+// TotalMatches matching lines found in FilesSearched files.
+type SearchResults struct {
+	Matches       []SearchMatch `json:"matches"`
+	Page          int           `json:"page"`
+	PerPage       int           `json:"per_page"`
+	NextPage      int           `json:"next_page,omitempty"`
+	TotalMatches  int           `json:"total_matches"`
+	FilesSearched int           `json:"files_searched"`
+	Truncated     bool          `json:"truncated,omitempty"` // the search stopped at max_matches, more matches may exist
+}
+
+// RepositorySignatures is one page of extract_repository_signatures results.
+// Pages cover TotalFiles source files; files without public symbols are omitted.
+type RepositorySignatures struct {
+	Files      []FileSignatures `json:"files"`
+	Page       int              `json:"page"`
+	PerPage    int              `json:"per_page"`
+	NextPage   int              `json:"next_page,omitempty"`
+	TotalFiles int              `json:"total_files"`
+	Truncated  bool             `json:"truncated,omitempty"` // more files exist beyond max_files
+}
+
+// Symbol is a function, class, method or other symbol of the symbol index
+type Symbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`            // Type of the FunctionSignature it was extracted as
+	Class     string `json:"class,omitempty"` // owning class, type or trait of a method or constructor
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Language  string `json:"language"`
+	Signature string `json:"signature"`
+}
+
+// SymbolResults is one page of find_symbol results. Pages cover the
+// TotalSymbols matching symbols.
+type SymbolResults struct {
+	Symbols      []Symbol `json:"symbols"`
+	Page         int      `json:"page"`
+	PerPage      int      `json:"per_page"`
+	NextPage     int      `json:"next_page,omitempty"`
+	TotalSymbols int      `json:"total_symbols"`
+	Truncated    bool     `json:"truncated,omitempty"` // the repository has more source files than the index holds
+}
+
+// Definition is a symbol returned by get_definition, with the code that
+// declares it and the comments or decorators right above it
+type Definition struct {
+	Symbol
+	StartLine int    `json:"start_line"` // first line of Code
+	EndLine   int    `json:"end_line"`   // last line of Code
+	Code      string `json:"code"`
+	Truncated bool   `json:"truncated,omitempty"` // the declaration goes on past max_lines
+}
+
+// Reference is an occurrence of a name in code, outside comments and
+// string literals
+type Reference struct {
+	Path       string `json:"path"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"` // 1-based, in runes
+	Text       string `json:"text"`
+	Definition bool   `json:"definition,omitempty"` // the line declares a symbol of that name
+}
+
+// ReferenceResults is one page of find_references results. Pages cover the
+// TotalReferences occurrences found in FilesSearched files.
+type ReferenceResults struct {
+	References      []Reference `json:"references"`
+	Page            int         `json:"page"`
+	PerPage         int         `json:"per_page"`
+	NextPage        int         `json:"next_page,omitempty"`
+	TotalReferences int         `json:"total_references"`
+	FilesSearched   int         `json:"files_searched"`
+	Truncated       bool        `json:"truncated,omitempty"` // the search stopped at max_references, more references may exist
+}
@@ -0,0 +1,524 @@
+package repository
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tsInterfaceRE = regexp.MustCompile(`(?m)^[ \t]*(?:export\s+)?(?:declare\s+)?interface\s+([A-Za-z_$][\w$]*)\s*(?:<[^{]*>)?\s*(?:extends\s+([^{]+))?\{`)
+	tsTypeAliasRE = regexp.MustCompile(`(?m)^[ \t]*(?:export\s+)?(?:declare\s+)?type\s+([A-Za-z_$][\w$]*)\s*(?:<[^=]*>)?\s*=`)
+	tsNumberRE    = regexp.MustCompile(`^-?\d+(?:\.\d+)?$`)
+)
+
+// jsStringEnd returns the index just past the string or template literal starting at start
+func jsStringEnd(code string, start int) int {
+	quote := code[start]
+	for i := start + 1; i < len(code); i++ {
+		switch code[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+	return len(code)
+}
+
+// stripJSComments blanks out line and block comments while keeping string
+// literals and line breaks intact, so offsets and line numbers are preserved
+func stripJSComments(code string) string {
+	out := []byte(code)
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '"' || out[i] == '\'' || out[i] == '`':
+			i = jsStringEnd(code, i) - 1
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := strings.Index(code[i+2:], "*/")
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		}
+	}
+	return string(out)
+}
+
+// jsScanTo returns the index of the first occurrence of any byte in stops at
+// bracket depth zero and outside string literals and comments, or -1. Angle
+// brackets count as brackets so generic arguments such as
+// Record<string, number> stay intact.
+func jsScanTo(text string, stops string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			i = jsStringEnd(text, i) - 1
+		case strings.HasPrefix(text[i:], "//"):
+			if end := strings.IndexByte(text[i:], '\n'); end >= 0 {
+				i += end - 1
+			} else {
+				i = len(text)
+			}
+		case strings.HasPrefix(text[i:], "/*"):
+			if end := strings.Index(text[i+2:], "*/"); end >= 0 {
+				i += 2 + end + 1
+			} else {
+				i = len(text)
+			}
+		case c == '=' && i+1 < len(text) && text[i+1] == '>':
+			if depth == 0 && strings.IndexByte(stops, c) >= 0 {
+				return i
+			}
+			i++
+		case depth == 0 && strings.IndexByte(stops, c) >= 0:
+			return i
+		case c == '(' || c == '[' || c == '{' || c == '<':
+			depth++
+		case c == ')' || c == ']' || c == '}' || c == '>':
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	return -1
+}
+
+// splitJSTopLevel splits text on sep, ignoring separators nested in brackets or strings
+func splitJSTopLevel(text string, sep byte) []string {
+	var parts []string
+	for {
+		idx := jsScanTo(text, string(sep))
+		if idx < 0 {
+			return append(parts, text)
+		}
+		parts = append(parts, text[:idx])
+		text = text[idx+1:]
+	}
+}
+
+// jsMatchingBracket returns the index of the bracket closing the one at open, or -1
+func jsMatchingBracket(code string, open int) int {
+	depth := 0
+	for i := open; i < len(code); i++ {
+		switch code[i] {
+		case '"', '\'', '`':
+			i = jsStringEnd(code, i) - 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// collectTypeScriptTypes indexes the interfaces and type aliases declared in the
+// source by name. Interfaces are recorded as object literal types intersected
+// with the interfaces they extend. Comments are kept in the recorded types
+// for the doc comments of their members.
+func collectTypeScriptTypes(source string) map[string]string {
+	code := stripJSComments(source)
+	types := make(map[string]string)
+
+	for _, m := range tsInterfaceRE.FindAllStringSubmatchIndex(code, -1) {
+		name := code[m[2]:m[3]]
+		open := m[1] - 1
+		end := jsMatchingBracket(code, open)
+		if end < 0 {
+			continue
+		}
+		body := source[open : end+1]
+		if m[4] >= 0 {
+			for _, parent := range splitJSTopLevel(code[m[4]:m[5]], ',') {
+				if parent = strings.TrimSpace(parent); parent != "" {
+					body = parent + " & " + body
+				}
+			}
+		}
+		types[name] = body
+	}
+
+	for _, m := range tsTypeAliasRE.FindAllStringSubmatchIndex(code, -1) {
+		name := code[m[2]:m[3]]
+		rest := code[m[1]:]
+
+		// The alias ends at a top-level semicolon, or at a line break unless the
+		// next line continues the union or intersection
+		end := len(rest)
+		depth := 0
+	scan:
+		for i := 0; i < len(rest); i++ {
+			switch c := rest[i]; {
+			case c == '"' || c == '\'' || c == '`':
+				i = jsStringEnd(rest, i) - 1
+			case c == '(' || c == '[' || c == '{' || c == '<':
+				depth++
+			case c == ')' || c == ']' || c == '}' || c == '>':
+				if c == '>' && i > 0 && rest[i-1] == '=' {
+					continue
+				}
+				depth--
+			case depth == 0 && c == ';':
+				end = i
+				break scan
+			case depth == 0 && c == '\n':
+				next := strings.TrimSpace(rest[i+1:])
+				if strings.TrimSpace(rest[:i]) != "" && !strings.HasPrefix(next, "|") && !strings.HasPrefix(next, "&") {
+					end = i
+					break scan
+				}
+			}
+		}
+		types[name] = strings.TrimSpace(source[m[1] : m[1]+end])
+	}
+
+	return types
+}
+
+// tsTypeSchema maps a TypeScript type expression to a JSON Schema, resolving
+// locally declared interfaces and type aliases. seen guards against recursion.
+// Comments in text are ignored, except for the doc comments of object members.
+func tsTypeSchema(text string, types map[string]string, seen map[string]bool) map[string]interface{} {
+	text = strings.TrimSpace(text)
+	text = strings.TrimSpace(strings.TrimPrefix(text, "|"))
+
+	if parts := splitJSTopLevel(text, '|'); len(parts) > 1 {
+		return tsUnionSchema(parts, types, seen)
+	}
+	if parts := splitJSTopLevel(text, '&'); len(parts) > 1 {
+		return tsIntersectionSchema(parts, types, seen)
+	}
+
+	// Object members keep their comments, everything else is read without
+	code := stripJSComments(text)
+	start := len(code) - len(strings.TrimLeft(code, " \t\r\n"))
+	end := len(strings.TrimRight(code, " \t\r\n"))
+	if start < end && code[start] == '{' && jsMatchingBracket(code, start) == end-1 {
+		return tsObjectSchema(text[start+1:end-1], types, seen)
+	}
+	text = strings.TrimSpace(code)
+
+	// Parenthesized types, e.g. (string | number)[]
+	if strings.HasPrefix(text, "(") && jsMatchingBracket(text, 0) == len(text)-1 {
+		return tsTypeSchema(text[1:len(text)-1], types, seen)
+	}
+
+	if strings.HasSuffix(text, "[]") {
+		return map[string]interface{}{
+			"type":  "array",
+			"items": tsTypeSchema(text[:len(text)-2], types, seen),
+		}
+	}
+
+	if strings.HasPrefix(text, "{") && strings.HasSuffix(text, "}") {
+		return tsObjectSchema(text[1:len(text)-1], types, seen)
+	}
+
+	if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+		var items []interface{}
+		for _, element := range splitJSTopLevel(text[1:len(text)-1], ',') {
+			if element = strings.TrimSpace(element); element != "" {
+				if idx := jsScanTo(element, ":"); idx >= 0 {
+					element = element[idx+1:]
+				}
+				items = append(items, tsTypeSchema(element, types, seen))
+			}
+		}
+		return map[string]interface{}{
+			"type":        "array",
+			"prefixItems": items,
+			"minItems":    len(items),
+			"maxItems":    len(items),
+		}
+	}
+
+	if value, ok := tsLiteral(text); ok {
+		return enumSchema([]interface{}{value})
+	}
+
+	name, args := text, []string(nil)
+	if open := strings.IndexByte(text, '<'); open > 0 && strings.HasSuffix(text, ">") {
+		name = strings.TrimSpace(text[:open])
+		for _, arg := range splitJSTopLevel(text[open+1:len(text)-1], ',') {
+			args = append(args, strings.TrimSpace(arg))
+		}
+	}
+
+	switch name {
+	case "string":
+		return map[string]interface{}{"type": "string"}
+	case "number":
+		return map[string]interface{}{"type": "number"}
+	case "bigint":
+		return map[string]interface{}{"type": "integer"}
+	case "boolean":
+		return map[string]interface{}{"type": "boolean"}
+	case "null", "undefined", "void":
+		return map[string]interface{}{"type": "null"}
+	case "any", "unknown", "never":
+		return map[string]interface{}{}
+	case "object", "Object":
+		return map[string]interface{}{"type": "object"}
+	case "Date":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "Array", "ReadonlyArray", "Set", "ReadonlySet":
+		schema := map[string]interface{}{"type": "array"}
+		if len(args) == 1 {
+			schema["items"] = tsTypeSchema(args[0], types, seen)
+		}
+		if strings.HasSuffix(name, "Set") {
+			schema["uniqueItems"] = true
+		}
+		return schema
+	case "Record", "Map", "ReadonlyMap":
+		schema := map[string]interface{}{"type": "object"}
+		if len(args) == 2 {
+			schema["additionalProperties"] = tsTypeSchema(args[1], types, seen)
+		}
+		return schema
+	case "Promise", "Readonly", "Awaited", "NonNullable":
+		if len(args) == 1 {
+			return tsTypeSchema(args[0], types, seen)
+		}
+	case "Partial":
+		if len(args) == 1 {
+			schema := tsTypeSchema(args[0], types, seen)
+			delete(schema, "required")
+			return schema
+		}
+	}
+
+	if body, ok := types[name]; ok && !seen[name] {
+		nested := make(map[string]bool, len(seen)+1)
+		for k, v := range seen {
+			nested[k] = v
+		}
+		nested[name] = true
+		return tsTypeSchema(body, types, nested)
+	}
+
+	// Classes and imported types are passed as JSON objects
+	return map[string]interface{}{"type": "object"}
+}
+
+// tsUnionSchema maps a union type, turning literal unions into enums
+func tsUnionSchema(parts []string, types map[string]string, seen map[string]bool) map[string]interface{} {
+	nullable := false
+	var literals []interface{}
+	var schemas []interface{}
+	allLiterals := true
+
+	for _, part := range parts {
+		part = strings.TrimSpace(stripJSComments(part))
+		if part == "" {
+			continue
+		}
+		if part == "null" || part == "undefined" {
+			nullable = true
+			continue
+		}
+		if value, ok := tsLiteral(part); ok {
+			literals = append(literals, value)
+		} else {
+			allLiterals = false
+		}
+		schemas = append(schemas, tsTypeSchema(part, types, seen))
+	}
+
+	var schema map[string]interface{}
+	switch {
+	case len(schemas) == 0:
+		return map[string]interface{}{"type": "null"}
+	case allLiterals:
+		schema = enumSchema(literals)
+	case len(schemas) == 1:
+		schema = schemas[0].(map[string]interface{})
+	default:
+		schema = map[string]interface{}{"anyOf": schemas}
+	}
+	if nullable {
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+// tsIntersectionSchema merges the object members of an intersection type
+func tsIntersectionSchema(parts []string, types map[string]string, seen map[string]bool) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	var others []interface{}
+
+	for _, part := range parts {
+		schema := tsTypeSchema(part, types, seen)
+		props, ok := schema["properties"].(map[string]interface{})
+		if !ok {
+			others = append(others, schema)
+			continue
+		}
+		for k, v := range props {
+			properties[k] = v
+		}
+		if req, ok := schema["required"].([]string); ok {
+			required = append(required, req...)
+		}
+	}
+
+	if len(others) > 0 {
+		return map[string]interface{}{"allOf": append(others, tsObject(properties, required))}
+	}
+	return tsObject(properties, required)
+}
+
+// tsObjectSchema maps the members of an object literal type or interface body
+func tsObjectSchema(body string, types map[string]string, seen map[string]bool) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	var additional interface{}
+
+	for _, member := range splitTSMembers(body) {
+		doc := parseJSDoc(braceDocComment(member)).String()
+		member = strings.TrimSpace(stripJSComments(member))
+		member = strings.TrimSpace(strings.TrimPrefix(member, "readonly "))
+		colon := jsScanTo(member, ":")
+		if member == "" || colon < 0 {
+			continue
+		}
+
+		key := strings.TrimSpace(member[:colon])
+		valueType := member[colon+1:]
+
+		// Index signatures such as [key: string]: number
+		if strings.HasPrefix(key, "[") {
+			if end := jsMatchingBracket(member, 0); end > 0 {
+				if rest := strings.TrimSpace(member[end+1:]); strings.HasPrefix(rest, ":") {
+					additional = tsTypeSchema(rest[1:], types, seen)
+				}
+			}
+			continue
+		}
+
+		// Method signatures are not data
+		if strings.Contains(key, "(") {
+			continue
+		}
+
+		optional := strings.HasSuffix(key, "?")
+		key = strings.Trim(strings.TrimSuffix(key, "?"), `"'`)
+		schema := tsTypeSchema(valueType, types, seen)
+		if doc != "" {
+			schema["description"] = doc
+		}
+		properties[key] = schema
+		if !optional {
+			required = append(required, key)
+		}
+	}
+
+	schema := tsObject(properties, required)
+	if additional != nil {
+		schema["additionalProperties"] = additional
+	}
+	return schema
+}
+
+// splitTSMembers splits an object type body on top-level semicolons, commas
+// and line breaks. Comments on lines of their own, such as doc comments, are
+// kept at the start of the member that follows them.
+func splitTSMembers(body string) []string {
+	var members []string
+	comments := ""
+	for _, part := range splitJSTopLevel(body, ';') {
+		for _, sub := range splitJSTopLevel(part, ',') {
+			for _, line := range splitJSTopLevel(sub, '\n') {
+				line = strings.TrimSpace(line)
+				code := strings.TrimSpace(stripJSComments(line))
+				if code == "" {
+					if line != "" {
+						comments += line + "\n"
+					}
+					continue
+				}
+				// Keep multi-line unions and intersections together
+				if n := len(members); n > 0 {
+					prev := strings.TrimSpace(stripJSComments(members[n-1]))
+					if strings.HasPrefix(code, "|") || strings.HasPrefix(code, "&") ||
+						strings.HasSuffix(prev, ":") || strings.HasSuffix(prev, "|") || strings.HasSuffix(prev, "&") {
+						members[n-1] += " " + line
+						continue
+					}
+				}
+				members = append(members, comments+line)
+				comments = ""
+			}
+		}
+	}
+	return members
+}
+
+func tsObject(properties map[string]interface{}, required []string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// tsLiteral parses a string, number or boolean literal type
+func tsLiteral(text string) (interface{}, bool) {
+	switch {
+	case text == "true":
+		return true, true
+	case text == "false":
+		return false, true
+	case tsNumberRE.MatchString(text):
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return i, true
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		return f, err == nil
+	case len(text) >= 2 && (text[0] == '"' || text[0] == '\'' || text[0] == '`') && jsStringEnd(text, 0) == len(text):
+		return strings.NewReplacer(`\"`, `"`, `\'`, `'`, `\\`, `\`).Replace(text[1 : len(text)-1]), true
+	}
+	return nil, false
+}
+
+// parseJSLiteral evaluates a JavaScript default value made of literals
+func parseJSLiteral(text string) (interface{}, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" || text == "undefined" {
+		return nil, false
+	}
+	if value, ok := tsLiteral(text); ok {
+		return value, true
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		return value, true
+	}
+	return nil, false
+}
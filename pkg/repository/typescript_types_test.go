@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTSTypeSchema(t *testing.T) {
+	types := map[string]string{
+		"Point":  "{ x: number; y: number }",
+		"Status": `"open" | "closed"`,
+	}
+
+	tests := []struct {
+		name     string
+		typeText string
+		expected map[string]interface{}
+	}{
+		{"string", "string", map[string]interface{}{"type": "string"}},
+		{"number", "number", map[string]interface{}{"type": "number"}},
+		{"boolean", "boolean", map[string]interface{}{"type": "boolean"}},
+		{"array suffix", "string[]", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}},
+		{"generic array", "Array<number>", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "number"},
+		}},
+		{"string literal union", `"asc" | 'desc'`, map[string]interface{}{
+			"type": "string",
+			"enum": []interface{}{"asc", "desc"},
+		}},
+		{"nullable", "number | null", map[string]interface{}{"type": "number", "nullable": true}},
+		{"record", "Record<string, boolean>", map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "boolean"},
+		}},
+		{"alias", "Status", map[string]interface{}{
+			"type": "string",
+			"enum": []interface{}{"open", "closed"},
+		}},
+		{"parenthesized union array", "(string | number)[]", map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"anyOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{"type": "number"},
+				},
+			},
+		}},
+		{"object literal", "{ id: string; tags?: string[] }", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id":   map[string]interface{}{"type": "string"},
+				"tags": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			},
+			"required": []string{"id"},
+		}},
+		{"partial", "Partial<Point>", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"x": map[string]interface{}{"type": "number"},
+				"y": map[string]interface{}{"type": "number"},
+			},
+		}},
+		{"unknown class", "HTMLElement", map[string]interface{}{"type": "object"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tsTypeSchema(tc.typeText, types, map[string]bool{}))
+		})
+	}
+}
+
+func TestCollectTypeScriptTypes(t *testing.T) {
+	code := `
+// A point.
+export interface Point {
+  x: number; // horizontal
+  y: number;
+}
+
+interface Point3D extends Point {
+  z: number;
+}
+
+export type Direction =
+  | "up"
+  | "down";
+
+type Handler = (event: Event) => void;
+`
+
+	types := collectTypeScriptTypes(code)
+	require.Contains(t, types, "Point")
+	assert.Equal(t, "Point & {\n  z: number;\n}", types["Point3D"])
+	assert.Equal(t, "| \"up\"\n  | \"down\"", types["Direction"])
+	assert.Equal(t, "(event: Event) => void", types["Handler"])
+}
+
+func TestExtractTypeScriptSignatures(t *testing.T) {
+	code := `
+interface Options {
+  /** Page size */
+  limit: number;
+  order?: "asc" | "desc";
+  filter: { field: string; values: string[] };
+}
+
+type Mode = "fast" | "safe";
+
+export async function search(query: string, options: Options, mode: Mode = "fast", tags?: string[]): Promise<Result[]> {
+  return [];
+}
+
+export const sum = (values: Array<number>, precision = 2): number => values.reduce((a, b) => a + b, 0);
+
+const notAFunction = (1 + 2);
+`
+
+	signatures, err := extractJavaScriptSignatures(code)
+	require.NoError(t, err)
+	require.Len(t, signatures, 2)
+
+	search := signatures[0]
+	assert.Equal(t, "search", search.Name)
+	assert.Equal(t, []string{"query", "options"}, search.Required)
+
+	props := search.Parameters["properties"].(map[string]interface{})
+	options := props["options"].(map[string]interface{})
+	assert.Equal(t, "object", options["type"])
+	assert.ElementsMatch(t, []string{"limit", "filter"}, options["required"])
+
+	optionProps := options["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "number", "description": "Page size"}, optionProps["limit"])
+	assert.Equal(t, []interface{}{"asc", "desc"}, optionProps["order"].(map[string]interface{})["enum"])
+	filter := optionProps["filter"].(map[string]interface{})
+	assert.Equal(t, []string{"field", "values"}, filter["required"])
+
+	mode := props["mode"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"fast", "safe"}, mode["enum"])
+	assert.Equal(t, "fast", mode["default"])
+
+	sum := signatures[1]
+	assert.Equal(t, "sum", sum.Name)
+	sumProps := sum.Parameters["properties"].(map[string]interface{})
+	assert.Equal(t, "array", sumProps["values"].(map[string]interface{})["type"])
+	precision := sumProps["precision"].(map[string]interface{})
+	assert.Equal(t, "integer", precision["type"])
+	assert.Equal(t, int64(2), precision["default"])
+}
+
+func TestExtractTypeScriptMemberDocs(t *testing.T) {
+	code := `
+interface Base {
+  /** The record's id, e.g. "a;b". */
+  id: string;
+}
+
+export interface User extends Base {
+  /**
+   * Display name.
+   *
+   * Shown in the header.
+   */
+  name: string;
+  // not a doc comment
+  age?: number; // trailing comment
+  /** Groups, in order */
+  groups: string[];
+}
+
+export function save(user: User): void {}
+`
+
+	signatures, err := extractJavaScriptSignatures(code)
+	require.NoError(t, err)
+	require.Len(t, signatures, 1)
+
+	user := signatures[0].Parameters["properties"].(map[string]interface{})["user"].(map[string]interface{})
+	assert.ElementsMatch(t, []string{"id", "name", "groups"}, user["required"])
+	props := user["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": `The record's id, e.g. "a;b".`}, props["id"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "Display name.\n\nShown in the header."}, props["name"])
+	assert.Equal(t, map[string]interface{}{"type": "number"}, props["age"])
+	assert.Equal(t, "Groups, in order", props["groups"].(map[string]interface{})["description"])
+}
+
+func TestParseJavaScriptObjectDefaults(t *testing.T) {
+	parameters, required := parseJavaScriptParameters(`opts = {}, retry = { count: 3 }, { a, b } = {}, list = [1, 2], pairs = [[1, "a"]]`, nil, nil)
+	assert.Empty(t, required)
+
+	props := parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "object", "default": map[string]interface{}{}, "description": "Parameter opts"}, props["opts"])
+	assert.Equal(t, map[string]interface{}{"type": "object", "description": "Parameter retry"}, props["retry"])
+	assert.Equal(t, "object", props["options"].(map[string]interface{})["type"])
+	assert.Equal(t, "array", props["list"].(map[string]interface{})["type"])
+	assert.Equal(t, "array", props["pairs"].(map[string]interface{})["type"])
+}
+
+func TestParseJavaScriptRestParameters(t *testing.T) {
+	parameters, required := parseJavaScriptParameters("format: string, ...args: string[]", nil, nil)
+	assert.Equal(t, []string{"format"}, required)
+	props := parameters["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Parameter args",
+	}, props["args"])
+
+	// Untyped
+	parameters, required = parseJavaScriptParameters("...values", nil, nil)
+	assert.Empty(t, required)
+	assert.Equal(t, "array", parameters["properties"].(map[string]interface{})["values"].(map[string]interface{})["type"])
+}
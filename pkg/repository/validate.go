@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ToolIssue is a problem found while validating a function descriptor.
+// Warnings describe something that was fixed, such as a renamed tool;
+// errors describe why a descriptor was not emitted.
+type ToolIssue struct {
+	Index   int    `json:"index"`           // position of the descriptor in the input
+	Name    string `json:"name"`            // descriptor name as given
+	Field   string `json:"field,omitempty"` // offending field, e.g. "name" or "parameters.properties.id.type"
+	Message string `json:"message"`
+}
+
+// EmitResult is the output of emit_tool_json: the tool objects in the
+// requested format with the warnings and errors found while building them
+type EmitResult struct {
+	Tools    []interface{} `json:"tools"`
+	Warnings []ToolIssue   `json:"warnings"`
+	Errors   []ToolIssue   `json:"errors"`
+}
+
+// emittedTool is a tool built from the descriptor at Index
+type emittedTool struct {
+	Index int
+	Name  string
+	Tool  interface{}
+}
+
+// schemaIssue is a problem found in, or a fix applied to, a parameters schema
+type schemaIssue struct {
+	Field   string
+	Message string
+}
+
+// jsonSchemaTypes are the values of the JSON Schema type keyword
+var jsonSchemaTypes = map[string]bool{
+	"string": true, "number": true, "integer": true, "boolean": true, "array": true, "object": true, "null": true,
+}
+
+// emit validates the descriptors and renders a tool for each valid one.
+// Names are made to follow the format's rules and de-duplicated in input
+// order by appending _2, _3, ... so the same input always yields the same
+// names. Descriptors marked as deprecated are left out unless
+// includeDeprecated is set.
+func (f toolFormat) emit(functions []FunctionDescriptor, includeDeprecated bool) ([]emittedTool, []ToolIssue, []ToolIssue) {
+	var tools []emittedTool
+	warnings := []ToolIssue{}
+	errors := []ToolIssue{}
+
+	used := make(map[string]bool)
+	for i, fn := range functions {
+		if fn.Deprecated && !includeDeprecated {
+			continue
+		}
+		warn := func(field, format string, args ...interface{}) {
+			warnings = append(warnings, ToolIssue{Index: i, Name: fn.Name, Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if strings.TrimSpace(fn.Name) == "" {
+			errors = append(errors, ToolIssue{Index: i, Field: "name", Message: "name is empty"})
+			continue
+		}
+
+		parameters, problems, fixes := validateParameters(fn.Parameters, fn.Required)
+		if len(problems) > 0 {
+			for _, problem := range problems {
+				errors = append(errors, ToolIssue{Index: i, Name: fn.Name, Field: problem.Field, Message: problem.Message})
+			}
+			continue
+		}
+		for _, fix := range fixes {
+			warn(fix.Field, "%s", fix.Message)
+		}
+		fn.Parameters = parameters
+
+		description := toolDescription(fn)
+		if strings.TrimSpace(description) == "" {
+			description = fmt.Sprintf("Calls %s.", fn.Name)
+			warn("description", "description is empty, using %q", description)
+		}
+		if f.MaxDescription > 0 && utf8.RuneCountInString(description) > f.MaxDescription {
+			description = truncateRunes(description, f.MaxDescription, "...")
+			warn("description", "description is longer than %d characters and was cut", f.MaxDescription)
+		}
+
+		name := f.sanitizeName(toolName(fn))
+		if name != toolName(fn) {
+			warn("name", "tool name %s does not follow the %s naming rules, using %s", toolName(fn), f.Name, name)
+		}
+		if used[name] {
+			unique := name
+			for n := 2; used[unique]; n++ {
+				suffix := fmt.Sprintf("_%d", n)
+				unique = truncateRunes(name, f.MaxName-utf8.RuneCountInString(suffix), "") + suffix
+			}
+			warn("name", "tool name %s is already used, using %s", name, unique)
+			name = unique
+		}
+		used[name] = true
+
+		tools = append(tools, emittedTool{Index: i, Name: name, Tool: f.Render(fn, name, description)})
+	}
+	return tools, warnings, errors
+}
+
+// sanitizeName replaces the characters the format does not allow in tool
+// names with underscores and cuts the name to the format's limit
+func (f toolFormat) sanitizeName(name string) string {
+	if f.NameRune != nil {
+		var b strings.Builder
+		for i, r := range []rune(name) {
+			switch {
+			case f.NameRune(r, i):
+				b.WriteRune(r)
+			case i == 0 && f.NameRune('_', 0):
+				// Keep the character after a leading underscore when it may not start a name
+				b.WriteRune('_')
+				if f.NameRune(r, 1) {
+					b.WriteRune(r)
+				}
+			default:
+				b.WriteRune('_')
+			}
+		}
+		name = b.String()
+	}
+	return truncateRunes(name, f.MaxName, "")
+}
+
+// validateParameters checks that a parameters schema is a valid JSON Schema
+// for an object and merges the descriptor's required list into it. It
+// returns the schema to emit, the problems that make it unusable and the
+// fixes applied.
+func validateParameters(parameters map[string]interface{}, required []string) (map[string]interface{}, []schemaIssue, []schemaIssue) {
+	var problems, fixes []schemaIssue
+
+	schema := make(map[string]interface{}, len(parameters)+2)
+	for key, value := range parameters {
+		schema[key] = value
+	}
+	switch typ, ok := schema["type"]; {
+	case parameters == nil:
+		fixes = append(fixes, schemaIssue{"parameters", "parameters are missing, the tool takes no arguments"})
+		schema["type"] = "object"
+	case !ok:
+		fixes = append(fixes, schemaIssue{"parameters.type", "type is missing, using object"})
+		schema["type"] = "object"
+	case typ != "object":
+		problems = append(problems, schemaIssue{"parameters.type", fmt.Sprintf("parameters must be an object schema, not %v", typ)})
+	}
+	if _, ok := schema["properties"]; !ok {
+		schema["properties"] = map[string]interface{}{}
+	}
+
+	problems = append(problems, validateSchema(schema, "parameters")...)
+	if len(problems) > 0 {
+		return nil, problems, nil
+	}
+
+	// Merge the descriptor's required list with the schema's own, keeping
+	// only names that are properties
+	properties := schema["properties"].(map[string]interface{})
+	var names []string
+	if existing, ok := schema["required"].([]interface{}); ok {
+		for _, name := range existing {
+			names = append(names, name.(string))
+		}
+	}
+	if existing, ok := schema["required"].([]string); ok {
+		names = append(names, existing...)
+	}
+	names = append(names, required...)
+
+	merged := []interface{}{}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, ok := properties[name]; !ok {
+			fixes = append(fixes, schemaIssue{"required", fmt.Sprintf("required parameter %s is not a property and was dropped", name)})
+			continue
+		}
+		merged = append(merged, name)
+	}
+	delete(schema, "required")
+	if len(merged) > 0 {
+		schema["required"] = merged
+	}
+	return schema, nil, fixes
+}
+
+// validateSchema checks the structure of a JSON Schema: keyword values must
+// have the right types and nested schemas must themselves be valid. Fields
+// of the problems found are dotted paths starting at path.
+func validateSchema(schema interface{}, path string) []schemaIssue {
+	node, ok := schema.(map[string]interface{})
+	if !ok {
+		if _, ok := schema.(bool); ok {
+			return nil
+		}
+		return []schemaIssue{{path, fmt.Sprintf("schema must be an object, not %T", schema)}}
+	}
+
+	var problems []schemaIssue
+	problem := func(keyword, format string, args ...interface{}) {
+		problems = append(problems, schemaIssue{path + "." + keyword, fmt.Sprintf(format, args...)})
+	}
+
+	// Visit keywords in a stable order so problems are reported deterministically
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := node[key]
+		switch key {
+		case "type":
+			types, ok := value.([]interface{})
+			if !ok {
+				types = []interface{}{value}
+			}
+			for _, typ := range types {
+				if name, ok := typ.(string); !ok || !jsonSchemaTypes[name] {
+					problem(key, "unknown type %v", typ)
+				}
+			}
+		case "properties", "patternProperties", "$defs", "definitions":
+			props, ok := value.(map[string]interface{})
+			if !ok {
+				problem(key, "%s must be an object", key)
+				continue
+			}
+			names := make([]string, 0, len(props))
+			for name := range props {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				problems = append(problems, validateSchema(props[name], path+"."+key+"."+name)...)
+			}
+		case "items", "additionalProperties", "not", "contains", "propertyNames", "additionalItems":
+			if list, ok := value.([]interface{}); ok && key == "items" {
+				// Draft 4 tuple validation
+				for i, item := range list {
+					problems = append(problems, validateSchema(item, fmt.Sprintf("%s.%s.%d", path, key, i))...)
+				}
+				continue
+			}
+			problems = append(problems, validateSchema(value, path+"."+key)...)
+		case "anyOf", "oneOf", "allOf", "prefixItems":
+			list, ok := value.([]interface{})
+			if !ok || len(list) == 0 {
+				problem(key, "%s must be a non-empty array", key)
+				continue
+			}
+			for i, item := range list {
+				problems = append(problems, validateSchema(item, fmt.Sprintf("%s.%s.%d", path, key, i))...)
+			}
+		case "required":
+			switch list := value.(type) {
+			case []string:
+			case []interface{}:
+				for _, name := range list {
+					if _, ok := name.(string); !ok {
+						problem(key, "required must list property names, found %v", name)
+					}
+				}
+			default:
+				problem(key, "required must be an array of property names")
+			}
+		case "enum":
+			if list, ok := value.([]interface{}); !ok || len(list) == 0 {
+				problem(key, "enum must be a non-empty array")
+			}
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+			"minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties":
+			switch value.(type) {
+			case float64, int, int64, bool:
+				// exclusiveMinimum and exclusiveMaximum are booleans in draft 4
+			default:
+				problem(key, "%s must be a number", key)
+			}
+		case "description", "title", "format", "pattern", "$ref":
+			if _, ok := value.(string); !ok {
+				problem(key, "%s must be a string", key)
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		format string
+		name   string
+		want   string
+	}{
+		{"openai", "get user", "get_user"},
+		{"openai", "add-item", "add-item"},
+		{"openai", "a.b", "a_b"},
+		{"mcp", "a.b", "a.b"},
+		{"anthropic", "$count", "_count"},
+		{"gemini", "2fa_check", "_2fa_check"},
+		{"gemini", "ns:call", "ns:call"},
+		{"openai", "naïve", "na_ve"},
+		{"openai", strings.Repeat("x", 70), strings.Repeat("x", 64)},
+		{"jsonschema", "any name!", "any name!"},
+	}
+
+	for _, tc := range tests {
+		format, ok := lookupToolFormat(tc.format)
+		require.True(t, ok)
+		assert.Equal(t, tc.want, format.sanitizeName(tc.name), "%s: %s", tc.format, tc.name)
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	t.Run("merges required", func(t *testing.T) {
+		schema, problems, fixes := validateParameters(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"a": map[string]interface{}{"type": "string"},
+				"b": map[string]interface{}{"type": "integer"},
+			},
+			"required": []interface{}{"a"},
+		}, []string{"a", "b", "ghost"})
+
+		assert.Empty(t, problems)
+		assert.Equal(t, []interface{}{"a", "b"}, schema["required"])
+		assert.Equal(t, []schemaIssue{{"required", "required parameter ghost is not a property and was dropped"}}, fixes)
+	})
+
+	t.Run("fills missing schema", func(t *testing.T) {
+		schema, problems, fixes := validateParameters(nil, nil)
+		assert.Empty(t, problems)
+		assert.Equal(t, map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}, schema)
+		assert.Len(t, fixes, 1)
+	})
+
+	t.Run("rejects invalid schemas", func(t *testing.T) {
+		_, problems, _ := validateParameters(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"a": map[string]interface{}{"type": "str"},
+				"b": "integer",
+				"c": map[string]interface{}{"type": "array", "items": map[string]interface{}{"enum": []interface{}{}}},
+			},
+		}, nil)
+
+		assert.Equal(t, []schemaIssue{
+			{"parameters.properties.a.type", "unknown type str"},
+			{"parameters.properties.b", "schema must be an object, not string"},
+			{"parameters.properties.c.items.enum", "enum must be a non-empty array"},
+		}, problems)
+
+		_, problems, _ = validateParameters(map[string]interface{}{"type": "array"}, nil)
+		assert.Equal(t, []schemaIssue{{"parameters.type", "parameters must be an object schema, not array"}}, problems)
+	})
+}
+
+func TestHandleEmitToolJSONValidation(t *testing.T) {
+	object := func(props map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"type": "object", "properties": props}
+	}
+	functions := []interface{}{
+		map[string]interface{}{
+			"name":        "search",
+			"description": "Searches.",
+			"parameters":  object(map[string]interface{}{"query": map[string]interface{}{"type": "string"}}),
+			"required":    []interface{}{"query", "limit"},
+		},
+		map[string]interface{}{
+			"name":        "search",
+			"description": "Searches again.",
+			"parameters":  object(map[string]interface{}{}),
+			"required":    []interface{}{},
+		},
+		map[string]interface{}{
+			"name":        "find user",
+			"description": "",
+			"parameters":  object(map[string]interface{}{}),
+			"required":    []interface{}{},
+		},
+		map[string]interface{}{
+			"name":        "broken",
+			"description": "Broken.",
+			"parameters":  object(map[string]interface{}{"x": map[string]interface{}{"type": "text"}}),
+			"required":    []interface{}{},
+		},
+		map[string]interface{}{
+			"name":        "search",
+			"description": "Searches a third time.",
+			"parameters":  object(map[string]interface{}{}),
+			"required":    []interface{}{},
+		},
+	}
+
+	result, err := handleEmitToolJSON(context.Background(), createMCPRequest(map[string]interface{}{"functions": functions}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var emitted struct {
+		Tools    []ToolDefinition `json:"tools"`
+		Warnings []ToolIssue      `json:"warnings"`
+		Errors   []ToolIssue      `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &emitted))
+
+	var names []string
+	for _, tool := range emitted.Tools {
+		names = append(names, tool.Function.Name)
+	}
+	assert.Equal(t, []string{"search", "search_2", "find_user", "search_3"}, names)
+	assert.Equal(t, []interface{}{"query"}, emitted.Tools[0].Function.Parameters["required"])
+	assert.Equal(t, "Calls find user.", emitted.Tools[2].Function.Description)
+
+	assert.Equal(t, []ToolIssue{
+		{Index: 0, Name: "search", Field: "required", Message: "required parameter limit is not a property and was dropped"},
+		{Index: 1, Name: "search", Field: "name", Message: "tool name search is already used, using search_2"},
+		{Index: 2, Name: "find user", Field: "description", Message: `description is empty, using "Calls find user."`},
+		{Index: 2, Name: "find user", Field: "name", Message: "tool name find user does not follow the openai naming rules, using find_user"},
+		{Index: 4, Name: "search", Field: "name", Message: "tool name search is already used, using search_3"},
+	}, emitted.Warnings)
+	assert.Equal(t, []ToolIssue{
+		{Index: 3, Name: "broken", Field: "parameters.properties.x.type", Message: "unknown type text"},
+	}, emitted.Errors)
+}
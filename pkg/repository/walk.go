@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// skippedDir reports whether a directory is never listed: hidden directories
+// and common build and dependency directories
+func skippedDir(name string) bool {
+	if strings.HasPrefix(name, ".") && name != "." {
+		return true
+	}
+	switch name {
+	case "node_modules", "vendor", "__pycache__", "dist", "build":
+		return true
+	}
+	return false
+}
+
+// walkRepository calls visit with the slash-separated path of every file of
+// the repository, in lexical order, skipping hidden files, the directories
+// rejected by skippedDir and the paths ignored by .gitignore,
+// .git/info/exclude and .mcpprimeignore files. Symlinks to directories are
+// not followed, and symlinks leading out of the repository are skipped.
+// Watched repositories are not walked: their cached file list is visited.
+func walkRepository(fsys *RepoFS, visit func(relPath string) error) error {
+	if files, ok := fsys.watchedFiles(); ok {
+		for _, relPath := range files {
+			if err := visit(relPath); err != nil {
+				if err == fs.SkipAll {
+					return nil
+				}
+				return err
+			}
+		}
+		return nil
+	}
+	return walkTree(fsys, ".", func(relPath string, isDir bool) error {
+		if isDir {
+			return nil
+		}
+		return visit(relPath)
+	})
+}
+
+// walkTree walks the file or directory root of the repository like
+// walkRepository, calling visit for the directories it enters as well as
+// for the files. Nothing is visited when root lies in a skipped or ignored
+// directory, or is itself skipped or ignored.
+func walkTree(fsys *RepoFS, root string, visit func(relPath string, isDir bool) error) error {
+	ignore := newIgnoreMatcher(fsys)
+	if root != "." {
+		ignore.load(fsys, ".")
+		parts := strings.Split(root, "/")
+		for i := range parts[:len(parts)-1] {
+			dir := strings.Join(parts[:i+1], "/")
+			if skippedDir(parts[i]) || ignore.ignored(dir, true) {
+				return nil
+			}
+			ignore.load(fsys, dir)
+		}
+	}
+
+	return fs.WalkDir(fsys, root, func(relPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if relPath != "." && (skippedDir(d.Name()) || ignore.ignored(relPath, true)) {
+				return fs.SkipDir
+			}
+			ignore.load(fsys, relPath)
+			return visit(relPath, true)
+		}
+
+		// Skip hidden and ignored files
+		if strings.HasPrefix(d.Name(), ".") || ignore.ignored(relPath, false) {
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			info, err := fsys.Stat(relPath)
+			if err != nil || info.IsDir() {
+				return nil
+			}
+		}
+
+		return visit(relPath, false)
+	})
+}
+
+// sourceFile is a repository file in a supported language
+type sourceFile struct {
+	Path     string // slash-separated, relative to the repository root
+	Language string
+}
+
+// collectSourceFiles lists up to maxFiles files of the repository that match the
+// include and exclude globs and are written in a registered language. The
+// returned flag reports whether more files were left out.
+func collectSourceFiles(ctx context.Context, fsys *RepoFS, include, exclude []string, maxFiles int) ([]sourceFile, bool, error) {
+	var files []sourceFile
+	truncated := false
+	err := walkRepository(fsys, func(relPath string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !matchFilters(relPath, include, exclude) {
+			return nil
+		}
+		language, ok := detectFileLanguage(fsys, relPath)
+		if !ok {
+			return nil
+		}
+		if len(files) == maxFiles {
+			truncated = true
+			return fs.SkipAll
+		}
+		files = append(files, sourceFile{Path: relPath, Language: language})
+		return nil
+	})
+	return files, truncated, err
+}
+
+// detectFileLanguage detects the language of a repository file from its
+// extension or, for files without one, from its shebang line
+func detectFileLanguage(fsys *RepoFS, name string) (string, bool) {
+	if language, ok := DetectLanguage(name, ""); ok || path.Ext(name) != "" {
+		return language, ok
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	head := make([]byte, 256)
+	n, _ := io.ReadFull(f, head)
+	line, _, _ := strings.Cut(string(head[:n]), "\n")
+	return DetectLanguage("", line)
+}
+
+// fileTypes names the languages of common files that no extractor handles,
+// by lower-case extension or, for files without one, by name
+var fileTypes = map[string]string{
+	"md":         "markdown",
+	"rst":        "restructuredtext",
+	"txt":        "text",
+	"json":       "json",
+	"yaml":       "yaml",
+	"yml":        "yaml",
+	"toml":       "toml",
+	"xml":        "xml",
+	"html":       "html",
+	"htm":        "html",
+	"css":        "css",
+	"scss":       "scss",
+	"sql":        "sql",
+	"sh":         "shell",
+	"bash":       "shell",
+	"proto":      "protobuf",
+	"rb":         "ruby",
+	"php":        "php",
+	"cs":         "csharp",
+	"swift":      "swift",
+	"dockerfile": "dockerfile",
+	"makefile":   "makefile",
+}
+
+// fileLanguage detects the language of any repository file: a registered
+// language, detected as by detectFileLanguage, or one of fileTypes. It
+// returns "" when the language is unknown.
+func fileLanguage(fsys *RepoFS, name string) string {
+	if language, ok := detectFileLanguage(fsys, name); ok {
+		return language
+	}
+	if ext := path.Ext(name); ext != "" {
+		return fileTypes[strings.ToLower(strings.TrimPrefix(ext, "."))]
+	}
+	return fileTypes[strings.ToLower(path.Base(name))]
+}
+
+// extractFileSignatures reads a repository file and extracts its signatures
+func extractFileSignatures(fsys *RepoFS, name, language string) ([]FunctionSignature, error) {
+	extractor, ok := LookupExtractor(language)
+	if !ok {
+		return nil, fmt.Errorf("unsupported language: %s", language)
+	}
+
+	info, err := fsys.Stat(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if info.Size() > maxExtractFileSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxExtractFileSize)
+	}
+
+	content, err := fsys.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	signatures, err := extractor.Extract(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract signatures: %w", err)
+	}
+	return signatures, nil
+}
+
+// matchGlob reports whether a slash-separated path matches a glob pattern.
+// Patterns use path.Match syntax per segment, and a "**" segment matches any
+// number of directories. A pattern matching a directory matches everything
+// below it, and patterns without a slash match a file or directory name at
+// any depth, so "*.py" selects every Python file and "testdata" everything
+// below any testdata directory.
+func matchGlob(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if !strings.Contains(pattern, "/") {
+		for _, segment := range strings.Split(name, "/") {
+			if ok, _ := path.Match(pattern, segment); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return true
+}
+
+// matchFilters reports whether a slash-separated path matches any of the
+// include globs, or there are none, and none of the exclude globs
+func matchFilters(name string, include, exclude []string) bool {
+	for _, pattern := range exclude {
+		if matchGlob(pattern, name) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.py", "app.py", true},
+		{"*.py", "src/pkg/app.py", true},
+		{"*.py", "src/app.go", false},
+		{"tests", "tests/test_app.py", true},
+		{"tests", "src/tests/test_app.py", true},
+		{"tests", "src/contests.py", false},
+		{"src/*.go", "src/main.go", true},
+		{"src/*.go", "cmd/src/main.go", false},
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/a/b/main.go", true},
+		{"**/*_test.go", "pkg/x/x_test.go", true},
+		{"./src", "src/a/b.py", true},
+		{"src/", "src/a/b.py", true},
+		{"src/a", "src/ab/c.py", false},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, matchGlob(tc.pattern, tc.path), "%s ~ %s", tc.pattern, tc.path)
+	}
+}
+
+func TestMatchFilters(t *testing.T) {
+	assert.True(t, matchFilters("src/app.py", nil, nil))
+	assert.True(t, matchFilters("src/app.py", []string{"*.go", "src/**"}, nil))
+	assert.False(t, matchFilters("src/app.py", []string{"*.go"}, nil))
+	assert.False(t, matchFilters("src/app.py", []string{"src/**"}, []string{"*.py"}))
+}
@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long a watcher waits for further changes before
+// applying the ones it was notified of, so a burst of writes, such as a
+// checkout, is applied at once
+const watchDebounce = 200 * time.Millisecond
+
+// repoWatcher keeps the file list and symbol index of a local repository up
+// to date with the file system notifications of its directories
+type repoWatcher struct {
+	repo *RepoFS
+	fsw  *fsnotify.Watcher
+
+	mu     sync.RWMutex
+	files  map[string]bool // what walkRepository visits
+	dirs   map[string]bool // directories being watched
+	sorted []string        // files in walk order, nil when stale
+}
+
+// Watch keeps the file list and symbol index of a local repository up to
+// date until ctx is done: the tools then read the cached file list instead
+// of walking the repository on every call, and the symbol index is updated
+// file by file as files change instead of being refreshed on every call.
+// Changes are applied in batches, after which onChange, if not nil, is
+// called with the paths of the files added, changed or removed.
+func (r *RepoFS) Watch(ctx context.Context, onChange func(changed []string)) error {
+	if r.dir == "" {
+		return fmt.Errorf("repository %s is not a local directory, so it cannot be watched", r.name)
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch repository: %w", err)
+	}
+
+	w := &repoWatcher{repo: r, fsw: fsw, files: make(map[string]bool), dirs: make(map[string]bool)}
+	w.watchGitInfo()
+	if _, err := w.rescan("."); err != nil {
+		fsw.Close()
+		return fmt.Errorf("failed to watch repository: %w", err)
+	}
+
+	r.mu.Lock()
+	if r.watcher != nil {
+		r.mu.Unlock()
+		fsw.Close()
+		return fmt.Errorf("repository %s is already watched", r.name)
+	}
+	r.watcher = w
+	r.mu.Unlock()
+
+	// Build the index from the cached file list
+	if err := r.symbolIndex().Refresh(ctx, r); err != nil {
+		w.stop()
+		return fmt.Errorf("failed to index repository: %w", err)
+	}
+
+	go w.run(ctx, onChange)
+	return nil
+}
+
+// watchedFiles returns the cached file list of a watched repository, in
+// walk order
+func (r *RepoFS) watchedFiles() ([]string, bool) {
+	r.mu.Lock()
+	w := r.watcher
+	r.mu.Unlock()
+	if w == nil {
+		return nil, false
+	}
+	return w.list(), true
+}
+
+// watched reports whether a repository is being watched
+func (r *RepoFS) watched() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.watcher != nil
+}
+
+// run applies the changes the watcher is notified of until ctx is done
+func (w *repoWatcher) run(ctx context.Context, onChange func(changed []string)) {
+	defer w.stop()
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			rel, err := filepath.Rel(w.repo.dir, event.Name)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			name := filepath.ToSlash(rel)
+			switch {
+			case name == ".git" || name == ".git/info" || name == ".git/info/exclude":
+				// The rules of .git/info/exclude apply to the whole repository
+				name = "."
+			case strings.HasPrefix(name, ".git/"):
+				continue
+			}
+			pending[name] = true
+			timer.Reset(watchDebounce)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			// Events were lost, so nothing short of a rescan is reliable
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				pending["."] = true
+				timer.Reset(watchDebounce)
+			}
+		case <-timer.C:
+			changed := w.apply(pending)
+			pending = make(map[string]bool)
+			if len(changed) > 0 && onChange != nil {
+				onChange(changed)
+			}
+		}
+	}
+}
+
+// stop stops watching, so the repository is walked again
+func (w *repoWatcher) stop() {
+	w.repo.mu.Lock()
+	if w.repo.watcher == w {
+		w.repo.watcher = nil
+	}
+	w.repo.mu.Unlock()
+	w.fsw.Close()
+}
+
+// list returns the cached file list in walk order
+func (w *repoWatcher) list() []string {
+	w.mu.RLock()
+	sorted := w.sorted
+	w.mu.RUnlock()
+	if sorted != nil {
+		return sorted
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.sorted == nil {
+		w.sorted = make([]string, 0, len(w.files))
+		for name := range w.files {
+			w.sorted = append(w.sorted, name)
+		}
+		sort.Slice(w.sorted, func(i, j int) bool { return walkOrderLess(w.sorted[i], w.sorted[j]) })
+	}
+	return w.sorted
+}
+
+// walkOrderLess orders slash-separated paths as fs.WalkDir visits them:
+// segment by segment, so a/b comes before a.txt
+func walkOrderLess(a, b string) bool {
+	return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/")) < 0
+}
+
+// apply brings the file list and symbol index up to date with changes to
+// paths, returning the files that were added, changed or removed
+func (w *repoWatcher) apply(paths map[string]bool) []string {
+	if paths["."] {
+		w.watchGitInfo()
+	}
+
+	// Rescan the directories of changed ignore files, whose rules changed,
+	// and the paths themselves otherwise. A rescan covers everything below.
+	roots := make(map[string]bool)
+	for name := range paths {
+		if slices.Contains(ignoreFiles, path.Base(name)) {
+			name = path.Dir(name)
+		}
+		roots[name] = true
+	}
+	var scans []string
+	for name := range roots {
+		covered := false
+		for dir := path.Dir(name); ; dir = path.Dir(dir) {
+			if roots[dir] && dir != name {
+				covered = true
+				break
+			}
+			if dir == "." {
+				break
+			}
+		}
+		if !covered {
+			scans = append(scans, name)
+		}
+	}
+	sort.Strings(scans)
+
+	changed := make(map[string]bool)
+	for _, root := range scans {
+		scanChanged, err := w.rescan(root)
+		if err != nil {
+			continue
+		}
+		for _, name := range scanChanged {
+			changed[name] = true
+		}
+	}
+	// Files written in place are still listed, but changed
+	w.mu.RLock()
+	for name := range paths {
+		if w.files[name] {
+			changed[name] = true
+		}
+	}
+	w.mu.RUnlock()
+
+	index := w.repo.symbolIndex()
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+		w.mu.RLock()
+		listed := w.files[name]
+		w.mu.RUnlock()
+		if listed {
+			index.update(w.repo, name)
+		} else {
+			index.remove(name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return walkOrderLess(names[i], names[j]) })
+	return names
+}
+
+// watchGitInfo watches .git/info for changes to .git/info/exclude, and .git
+// for .git/info being created. Walks skip .git, so rescans do not watch them.
+func (w *repoWatcher) watchGitInfo() {
+	for _, dir := range []string{".git", ".git/info"} {
+		_ = w.fsw.Add(filepath.Join(w.repo.dir, filepath.FromSlash(dir)))
+	}
+}
+
+// rescan walks the file or directory root again, replacing what the file
+// list holds below it and watching the directories found. It returns the
+// files that were added to or removed from the list.
+func (w *repoWatcher) rescan(root string) ([]string, error) {
+	below := func(name string) bool {
+		return root == "." || name == root || strings.HasPrefix(name, root+"/")
+	}
+
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	err := walkTree(w.repo, root, func(relPath string, isDir bool) error {
+		if isDir {
+			dirs[relPath] = true
+		} else {
+			files[relPath] = true
+		}
+		return nil
+	})
+	// Below the root, an error means the path is gone, or was replaced by
+	// something that cannot be listed
+	if err != nil && root == "." {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changed []string
+	for name := range w.files {
+		if below(name) && !files[name] {
+			delete(w.files, name)
+			changed = append(changed, name)
+		}
+	}
+	for name := range files {
+		if !w.files[name] {
+			w.files[name] = true
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) > 0 {
+		w.sorted = nil
+	}
+
+	for dir := range w.dirs {
+		if below(dir) && !dirs[dir] {
+			delete(w.dirs, dir)
+			_ = w.fsw.Remove(filepath.Join(w.repo.dir, filepath.FromSlash(dir)))
+		}
+	}
+	for dir := range dirs {
+		if !w.dirs[dir] {
+			if err := w.fsw.Add(filepath.Join(w.repo.dir, filepath.FromSlash(dir))); err != nil {
+				continue
+			}
+			w.dirs[dir] = true
+		}
+	}
+	return changed, nil
+}
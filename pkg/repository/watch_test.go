@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootsWatch(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".gitignore":  "gen/\n",
+		"README.md":   "# App\n",
+		"app/main.py": "def start():\n    pass\n",
+		"gen/out.py":  "def generated():\n    pass\n",
+	})
+	roots, err := ParseRoots([]string{root})
+	require.NoError(t, err)
+	repo, err := roots.Resolve("")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	var changes [][]string
+	require.NoError(t, roots.Watch(ctx, func(changedRepo *RepoFS, changed []string) {
+		assert.Same(t, repo, changedRepo)
+		mu.Lock()
+		changes = append(changes, changed)
+		mu.Unlock()
+	}))
+
+	files := func() []string {
+		var files []string
+		require.NoError(t, walkRepository(repo, func(relPath string) error {
+			files = append(files, relPath)
+			return nil
+		}))
+		return files
+	}
+	symbols := func() []string {
+		index, err := refreshedIndex(context.Background(), repo)
+		require.NoError(t, err)
+		var names []string
+		for _, symbol := range index.Symbols(nil) {
+			names = append(names, symbol.Path+" "+symbol.Name)
+		}
+		return names
+	}
+	eventually := func(want []string, got func() []string) {
+		t.Helper()
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			assert.Equal(c, want, got())
+		}, 5*time.Second, 20*time.Millisecond)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	assert.True(t, repo.watched())
+	assert.Equal(t, []string{"README.md", "app/main.py"}, files())
+	assert.Equal(t, []string{"app/main.py start"}, symbols())
+
+	t.Run("new files and directories", func(t *testing.T) {
+		write("app/util.py", "def helper():\n    pass\n")
+		write("lib/deep/run.go", "package deep\n\nfunc Run() {}\n")
+		write("app.txt", "notes\n")
+		eventually([]string{"README.md", "app/main.py", "app/util.py", "app.txt", "lib/deep/run.go"}, files)
+		eventually([]string{"app/main.py start", "app/util.py helper", "lib/deep/run.go Run"}, symbols)
+
+		write("lib/deep/more.go", "package deep\n\nfunc More() {}\n")
+		eventually([]string{"app/main.py start", "app/util.py helper", "lib/deep/more.go More", "lib/deep/run.go Run"}, symbols)
+	})
+
+	t.Run("changed and removed files", func(t *testing.T) {
+		mu.Lock()
+		changes = nil
+		mu.Unlock()
+		write("app/main.py", "def begin():\n    pass\n")
+		require.NoError(t, os.RemoveAll(filepath.Join(root, "lib")))
+		eventually([]string{"app/main.py begin", "app/util.py helper"}, symbols)
+		eventually([]string{"README.md", "app/main.py", "app/util.py", "app.txt"}, files)
+
+		mu.Lock()
+		var changed []string
+		for _, batch := range changes {
+			changed = append(changed, batch...)
+		}
+		mu.Unlock()
+		assert.Subset(t, changed, []string{"app/main.py", "lib/deep/more.go", "lib/deep/run.go"})
+	})
+
+	t.Run("ignore files", func(t *testing.T) {
+		write(".gitignore", "*.txt\n")
+		eventually([]string{"README.md", "app/main.py", "app/util.py", "gen/out.py"}, files)
+		eventually([]string{"app/main.py begin", "app/util.py helper", "gen/out.py generated"}, symbols)
+
+		write("app/.mcpprimeignore", "util.py\n")
+		eventually([]string{"README.md", "app/main.py", "gen/out.py"}, files)
+	})
+
+	t.Run("git exclude", func(t *testing.T) {
+		write(".git/info/exclude", "*.md\n")
+		eventually([]string{"app/main.py", "gen/out.py"}, files)
+
+		write(".git/info/exclude", "gen/\n")
+		eventually([]string{"README.md", "app/main.py"}, files)
+		eventually([]string{"app/main.py begin"}, symbols)
+	})
+
+	t.Run("stopping", func(t *testing.T) {
+		cancel()
+		require.Eventually(t, func() bool { return !repo.watched() }, 5*time.Second, 20*time.Millisecond)
+		write("late.py", "def late():\n    pass\n")
+		assert.Contains(t, files(), "late.py", "the repository is walked again")
+	})
+}
+
+func TestRepoFSWatchArchive(t *testing.T) {
+	repo := NewRepoFS(os.DirFS(t.TempDir()), "archive")
+	err := repo.Watch(context.Background(), nil)
+	assert.EqualError(t, err, "repository archive is not a local directory, so it cannot be watched")
+}